@@ -0,0 +1,67 @@
+package control
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ecwid/control/protocol/target"
+)
+
+var ErrNoPageTargets = errors.New("there are no page targets")
+
+// touchTarget moves the target to the head of activation order
+func (b BrowserContext) touchTarget(id target.TargetID) {
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	order := []target.TargetID{id}
+	for _, v := range b.state.activated {
+		if v != id {
+			order = append(order, v)
+		}
+	}
+	b.state.activated = order
+}
+
+// activationRank returns position of the target in activation order, targets never activated by this client go last
+func (b BrowserContext) activationRank(id target.TargetID) int {
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	for n, v := range b.state.activated {
+		if v == id {
+			return n
+		}
+	}
+	return len(b.state.activated)
+}
+
+// GetPageTargets returns page targets (tabs) ordered by activation, the most recently activated goes first.
+// Only activations made via this client (ActivateTarget, CreatePageTarget) are known,
+// the rest of the tabs keep the browser's order
+func (b BrowserContext) GetPageTargets() ([]*target.TargetInfo, error) {
+	targets, err := b.GetTargets()
+	if err != nil {
+		return nil, err
+	}
+	var pages []*target.TargetInfo
+	for _, t := range targets {
+		if t.Type == "page" {
+			pages = append(pages, t)
+		}
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		return b.activationRank(pages[i].TargetId) < b.activationRank(pages[j].TargetId)
+	})
+	return pages, nil
+}
+
+// ActiveTarget returns the page target which is foregrounded (see GetPageTargets)
+func (b BrowserContext) ActiveTarget() (*target.TargetInfo, error) {
+	pages, err := b.GetPageTargets()
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, ErrNoPageTargets
+	}
+	return pages[0], nil
+}
@@ -0,0 +1,174 @@
+// Package allocator provides browsers from different sources behind one interface,
+// so the same code runs against a local browser, a running one or a browser farm
+package allocator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ecwid/control/chrome"
+	"github.com/ecwid/control/transport"
+)
+
+// Allocation browser connection, Release frees the browser (kills the process, returns the pod)
+type Allocation struct {
+	Client  *transport.Client
+	release func() error
+	once    sync.Once
+	err     error
+}
+
+// Release closes the connection and frees the browser, it's safe to call it several times
+func (a *Allocation) Release() error {
+	a.once.Do(func() { a.err = a.release() })
+	return a.err
+}
+
+// Allocator provides browsers
+type Allocator interface {
+	Allocate(ctx context.Context) (*Allocation, error)
+}
+
+// Exec launches a local browser process
+type Exec struct {
+	Options chrome.LaunchOptions
+}
+
+func (e Exec) Allocate(ctx context.Context) (*Allocation, error) {
+	browser, err := chrome.LaunchWithOptions(ctx, e.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &Allocation{Client: browser.GetClient(), release: browser.Close}, nil
+}
+
+// Remote connects to a running browser. URL is either its DevTools websocket URL (ws://host:9222/devtools/browser/<id>)
+// or the HTTP address of the debugging port (http://host:9222), the websocket URL is looked up then
+type Remote struct {
+	URL    string
+	Client *http.Client // for the lookup, nil - http.DefaultClient
+	Dial   transport.DialOptions
+}
+
+func (r Remote) Allocate(ctx context.Context) (*Allocation, error) {
+	ws := r.URL
+	if strings.HasPrefix(ws, "http://") || strings.HasPrefix(ws, "https://") {
+		var version struct {
+			WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+		}
+		if err := doJSON(ctx, httpClient(r.Client), http.MethodGet, strings.TrimSuffix(ws, "/")+"/json/version", nil, nil, &version); err != nil {
+			return nil, err
+		}
+		if ws = version.WebSocketDebuggerURL; ws == "" {
+			return nil, fmt.Errorf("%s didn't report webSocketDebuggerUrl", r.URL)
+		}
+	}
+	client, err := transport.DialWithOptions(ctx, ws, r.Dial)
+	if err != nil {
+		return nil, err
+	}
+	return &Allocation{Client: client, release: client.Close}, nil
+}
+
+// Service requests browsers from an HTTP allocator service (e.g. one running browser pods in Kubernetes):
+//
+//	POST {Endpoint} with {"labels": {...}} -> {"id": "...", "webSocketDebuggerUrl": "ws://..."} once the browser is ready
+//	DELETE {Endpoint}/{id} when the browser is released
+type Service struct {
+	Endpoint string
+	Labels   map[string]string // passed to the service as is, e.g. browser version or region
+	Header   http.Header       // e.g. Authorization
+	Client   *http.Client      // nil - http.DefaultClient
+	Dial     transport.DialOptions
+}
+
+// ServiceError unexpected response of the allocator service
+type ServiceError struct {
+	Method string
+	URL    string
+	Status int
+	Body   string
+}
+
+func (e ServiceError) Error() string {
+	return fmt.Sprintf("allocator service %s %s: %d %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+func (s Service) Allocate(ctx context.Context) (*Allocation, error) {
+	client := httpClient(s.Client)
+	request := struct {
+		Labels map[string]string `json:"labels,omitempty"`
+	}{Labels: s.Labels}
+	var browser struct {
+		ID                   string `json:"id"`
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := doJSON(ctx, client, http.MethodPost, s.Endpoint, s.Header, request, &browser); err != nil {
+		return nil, err
+	}
+	free := func() error {
+		// the caller's context may be done already, the browser has to be returned anyway
+		return doJSON(context.Background(), client, http.MethodDelete, strings.TrimSuffix(s.Endpoint, "/")+"/"+url.PathEscape(browser.ID), s.Header, nil, nil)
+	}
+	conn, err := transport.DialWithOptions(ctx, browser.WebSocketDebuggerURL, s.Dial)
+	if err != nil {
+		_ = free()
+		return nil, err
+	}
+	return &Allocation{Client: conn, release: func() error {
+		_ = conn.Close()
+		return free()
+	}}, nil
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
+
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return ServiceError{Method: method, URL: url, Status: resp.StatusCode, Body: strings.TrimSpace(string(b))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
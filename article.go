@@ -0,0 +1,70 @@
+package control
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrNoArticle = errors.New("no readable content found")
+
+// readability-style scoring: paragraphs give points to their parent and grandparent,
+// class/id names and link density adjust the score, the best container with its related siblings is the article
+const functionArticle = `(()=>{const pos=/article|body|content|entry|main|page|post|text|blog|story/i,neg=/comment|meta|footer|footnote|sidebar|sponsor|share|social|related|promo|banner|menu|nav|combx|masthead|popup|widget|ad-|advert/i,
+noise="script,style,noscript,iframe,form,button,nav,aside,footer,header,svg,canvas,[hidden],[aria-hidden=true],[role=navigation],[role=complementary]",
+meta=s=>{const e=document.querySelector(s);return e?(e.getAttribute("content")||e.getAttribute("datetime")||e.textContent||"").trim():""},
+weight=e=>{let w=0;const s=(e.className&&e.className.baseVal===undefined?e.className:"")+" "+e.id;if(pos.test(s))w+=25;if(neg.test(s))w-=25;return w},
+text=e=>(e.textContent||"").replace(/\s+/g," ").trim(),
+links=e=>{const t=text(e).length;if(!t)return 0;let l=0;e.querySelectorAll("a").forEach(a=>l+=text(a).length);return l/t},
+scores=new Map(),add=(e,v)=>{if(!e||e===document.documentElement)return;if(!scores.has(e))scores.set(e,(/^(div|article|section|main)$/.test(e.localName)?5:/^(pre|td|blockquote)$/.test(e.localName)?3:0)+weight(e));scores.set(e,scores.get(e)+v)};
+document.querySelectorAll("p,pre,td,blockquote").forEach(p=>{if(p.closest(noise))return;const t=text(p);if(t.length<25)return;const v=1+t.split(/[,，、]/).length-1+Math.min(Math.floor(t.length/100),3);add(p.parentElement,v);if(p.parentElement)add(p.parentElement.parentElement,v/2)});
+let top=null,best=0;scores.forEach((v,e)=>{v*=1-links(e);scores.set(e,v);if(v>best){best=v;top=e}});if(!top)return null;
+const parts=[top];if(top.parentElement)for(const s of top.parentElement.children){if(s===top)continue;const v=scores.get(s)||0;if(v>Math.max(10,best*0.2)||s.localName==="p"&&text(s).length>80&&links(s)<0.25)parts.push(s)}
+const root=document.createElement("div");for(const p of parts.sort((a,b)=>a.compareDocumentPosition(b)&Node.DOCUMENT_POSITION_FOLLOWING?-1:1))root.appendChild(p.cloneNode(true));
+root.querySelectorAll(noise).forEach(e=>e.remove());root.querySelectorAll("*").forEach(e=>{const s=(e.className&&e.className.baseVal===undefined?e.className:"")+" "+e.id;if(neg.test(s)&&!pos.test(s)&&links(e)>0.3)e.remove()});
+const blocks=Array.from(root.querySelectorAll("h1,h2,h3,h4,h5,h6,p,li,pre,blockquote,figcaption")).filter(e=>!e.parentElement.closest("p,li,pre,blockquote")).map(text).filter(t=>t);
+const images=[];root.querySelectorAll("img").forEach(i=>{const src=i.currentSrc||i.src||i.getAttribute("data-src")||"";if(src&&!src.startsWith("data:")&&!images.some(x=>x.url===src))images.push({url:new URL(src,location.href).href,alt:i.alt||""})});
+const lead=meta('meta[property="og:image"]');if(lead&&!images.some(x=>x.url===lead))images.unshift({url:new URL(lead,location.href).href,alt:""});
+const h1=top.querySelector("h1")||document.querySelector("h1");
+return{url:location.href,title:meta('meta[property="og:title"]')||(h1?text(h1):"")||document.title,
+byline:meta('meta[name="author"]')||meta('[rel="author"]')||meta('[itemprop="author"]')||meta(".byline")||meta(".author"),
+siteName:meta('meta[property="og:site_name"]'),published:meta('meta[property="article:published_time"]')||meta("time[datetime]"),
+lang:document.documentElement.lang||"",excerpt:meta('meta[name="description"]')||meta('meta[property="og:description"]')||blocks.find(t=>t.length>80)||"",
+text:blocks.length?blocks.join("\n\n"):text(root),html:root.innerHTML,images}})()`
+
+// Article main content of the page as extracted by ExtractArticle
+type Article struct {
+	URL       string         `json:"url"`
+	Title     string         `json:"title"`
+	Byline    string         `json:"byline"`
+	SiteName  string         `json:"siteName"`
+	Published string         `json:"published"` // as written in the page, usually RFC 3339
+	Lang      string         `json:"lang"`
+	Excerpt   string         `json:"excerpt"`
+	Text      string         `json:"text"` // paragraphs separated by blank lines
+	HTML      string         `json:"html"` // cleaned markup of the content
+	Images    []ArticleImage `json:"images"`
+}
+
+// ArticleImage image of the article content, the lead (og:image) image goes first
+type ArticleImage struct {
+	URL string `json:"url"`
+	Alt string `json:"alt"`
+}
+
+// Words number of words of the article text
+func (a Article) Words() int {
+	return len(strings.Fields(a.Text))
+}
+
+// ExtractArticle finds the main content of the main frame (title, byline, text, images) with readability-style heuristics.
+// Navigation, comments, sidebars and other boilerplate are dropped
+func (s Session) ExtractArticle() (*Article, error) {
+	var a *Article
+	if err := s.Page().evaluateValue(functionArticle, false, &a); err != nil {
+		return nil, err
+	}
+	if a == nil || strings.TrimSpace(a.Text) == "" {
+		return nil, ErrNoArticle
+	}
+	return a, nil
+}
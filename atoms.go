@@ -22,5 +22,12 @@ const (
 	functionSelect               = `function(a){const b=Array.from(this.options);this.value=void 0;for(const c of b)if(c.selected=a.includes(c.value),c.selected&&!this.multiple)break}`
 	functionGetSelectedValues    = `function(){return Array.from(this.options).filter(a=>a.selected).map(a=>a.value)}`
 	functionGetSelectedInnerText = `function(){return Array.from(this.options).filter(a=>a.selected).map(a=>a.innerText)}`
+	functionSelector             = `function(){return(` + atomSelectorOf + `)(this)}`
 	functionDOMIdle              = `var d=function(e,t,n){var u,r=null;return function(){var i=this,o=arguments,s=n&&!r;return clearTimeout(r),r=setTimeout(function(){r=null,n||(u=e.apply(i,o))},t),s&&(u=e.apply(i,o)),u}};new Promise((e,t)=>{var n=d(function(){e()},%d);new MutationObserver(n).observe(document,{attributes:!0,childList:!0,subtree:!0}),n(),setTimeout(()=>t("timeout"),%d)});`
 )
+
+// atomSelectorOf best-guess unique CSS selector of an element: id, test attributes, name, then nth-of-type path
+const atomSelectorOf = `(e=>{const q=s=>{try{return document.querySelectorAll(s).length===1}catch(x){return!1}},c=CSS.escape;if(e.id&&q("#"+c(e.id)))return"#"+c(e.id);
+for(const a of["data-testid","data-test","data-qa","name","aria-label","placeholder"]){const v=e.getAttribute&&e.getAttribute(a);if(v){const s=e.localName+"["+a+'="'+v.replace(/"/g,'\\"')+'"]';if(q(s))return s}}
+const p=[];for(let n=e;n&&n.nodeType===1;n=n.parentElement){if(n.id&&q("#"+c(n.id))){p.unshift("#"+c(n.id));break}let s=n.localName;const l=n.parentElement?Array.from(n.parentElement.children).filter(x=>x.localName===n.localName):[];
+if(l.length>1)s+=":nth-of-type("+(l.indexOf(n)+1)+")";p.unshift(s);if(q(p.join(" > ")))break}return p.join(" > ")})`
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/ecwid/control"
+)
+
+const scriptImages = `(()=>{const d=window.devicePixelRatio||1,h=window.innerHeight;return Array.from(document.images).filter(i=>i.complete&&i.naturalWidth>0).map(i=>{
+const r=i.getBoundingClientRect(),e=performance.getEntriesByName(i.currentSrc)[0];
+return{src:i.currentSrc,naturalWidth:i.naturalWidth,naturalHeight:i.naturalHeight,displayWidth:r.width*d,displayHeight:r.height*d,
+loading:i.loading||"",belowFold:r.top+window.scrollY>h,bytes:e?(e.encodedBodySize||e.transferSize||0):0}})})()`
+
+// Issues reported by Images
+const (
+	ImageOversized    = "oversized"     // natural size is much larger than displayed size
+	ImageNotLazy      = "not-lazy"      // below the fold without loading=lazy
+	ImageLegacyFormat = "legacy-format" // JPEG, PNG or GIF where WebP/AVIF would be smaller
+)
+
+// Image displayed <img>, sizes are in device pixels
+type Image struct {
+	Src           string  `json:"src"`
+	NaturalWidth  float64 `json:"naturalWidth"`
+	NaturalHeight float64 `json:"naturalHeight"`
+	DisplayWidth  float64 `json:"displayWidth"`
+	DisplayHeight float64 `json:"displayHeight"`
+	Loading       string  `json:"loading"`
+	BelowFold     bool    `json:"belowFold"`
+	Bytes         float64 `json:"bytes"` // 0 if unknown (cross-origin without Timing-Allow-Origin)
+}
+
+// ImageFinding image with its issues
+type ImageFinding struct {
+	Image
+	Issues []string
+	// WastedPixels share of decoded pixels which are never displayed, 0..1
+	WastedPixels float64
+}
+
+// ImageOptions thresholds of the image audit
+type ImageOptions struct {
+	OversizeRatio float64 // natural/displayed area ratio to report, 1.5 by default (sub-pixel rounding and 1 DPR leeway)
+	MinBytes      float64 // images smaller than this are not reported as legacy format, 4KB by default
+}
+
+// Images audits images of the frame's document: oversized, not lazy-loaded below the fold and legacy formats
+func Images(f *control.Frame, options ImageOptions) ([]ImageFinding, error) {
+	if options.OversizeRatio == 0 {
+		options.OversizeRatio = 1.5
+	}
+	if options.MinBytes == 0 {
+		options.MinBytes = 4 * 1024
+	}
+	v, err := f.Evaluate(scriptImages, false, true)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var images []Image
+	if err = json.Unmarshal(b, &images); err != nil {
+		return nil, err
+	}
+	var findings []ImageFinding
+	for _, img := range images {
+		finding := ImageFinding{Image: img}
+		natural, displayed := img.NaturalWidth*img.NaturalHeight, img.DisplayWidth*img.DisplayHeight
+		if displayed > 0 && natural/displayed > options.OversizeRatio {
+			finding.Issues = append(finding.Issues, ImageOversized)
+			finding.WastedPixels = 1 - displayed/natural
+		}
+		if img.BelowFold && img.Loading != "lazy" {
+			finding.Issues = append(finding.Issues, ImageNotLazy)
+		}
+		if isLegacyFormat(img.Src) && (img.Bytes == 0 || img.Bytes >= options.MinBytes) {
+			finding.Issues = append(finding.Issues, ImageLegacyFormat)
+		}
+		if len(finding.Issues) > 0 {
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+func isLegacyFormat(src string) bool {
+	if strings.HasPrefix(src, "data:image/") {
+		mime := strings.TrimPrefix(src, "data:")
+		return strings.HasPrefix(mime, "image/jpeg") || strings.HasPrefix(mime, "image/png") || strings.HasPrefix(mime, "image/gif")
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// second-level public suffixes common enough to matter, a full public suffix list is out of scope
+var multiPartSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true,
+	"com.au": true, "net.au": true, "org.au": true,
+	"co.jp": true, "ne.jp": true, "or.jp": true,
+	"com.br": true, "com.cn": true, "com.mx": true, "com.tr": true, "com.ua": true,
+	"co.nz": true, "co.za": true, "co.in": true, "co.kr": true, "com.sg": true,
+	"github.io": true, "herokuapp.com": true, "appspot.com": true, "cloudfront.net": true,
+	"azurewebsites.net": true, "blogspot.com": true, "netlify.app": true, "vercel.app": true,
+}
+
+// Site approximates eTLD+1 (registrable domain) of the URL, e.g. "https://cdn.shop.example.co.uk/x" -> "example.co.uk".
+// IP addresses and single-label hosts are returned as is
+func Site(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	n := 2
+	if multiPartSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	if len(labels) < n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
@@ -0,0 +1,83 @@
+// Package audit turns data captured from sessions into actionable findings (third parties, images, SEO)
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+// ThirdParty requests of a site other than the first party
+type ThirdParty struct {
+	Site     string
+	Requests int
+	Bytes    float64 // transferred
+	Duration time.Duration
+	Failed   int
+}
+
+// ThirdParties groups captured requests by site (eTLD+1) excluding firstParty site and data/blob URLs, the heaviest go first.
+// firstParty may be a URL, a host or a site
+func ThirdParties(entries []control.RequestEntry, firstParty string) []*ThirdParty {
+	own := Site(firstParty)
+	if own == "" {
+		// a host or a site has no scheme, so it's parsed as a path
+		own = Site("https://" + firstParty)
+	}
+	sites := map[string]*ThirdParty{}
+	for _, e := range entries {
+		site := Site(e.URL)
+		if site == "" || site == own {
+			continue
+		}
+		t := sites[site]
+		if t == nil {
+			t = &ThirdParty{Site: site}
+			sites[site] = t
+		}
+		t.Requests++
+		t.Bytes += e.EncodedBytes
+		t.Duration += e.Duration
+		if e.Failed {
+			t.Failed++
+		}
+	}
+	list := make([]*ThirdParty, 0, len(sites))
+	for _, t := range sites {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Bytes > list[j].Bytes })
+	return list
+}
+
+// BudgetError third parties exceeded the budget
+type BudgetError struct {
+	LimitKB float64
+	Over    []*ThirdParty
+}
+
+func (e BudgetError) Error() string {
+	return fmt.Sprintf("%d third part(ies) over %.0fKB budget, the heaviest is %s with %.0fKB",
+		len(e.Over), e.LimitKB, e.Over[0].Site, e.Over[0].Bytes/1024)
+}
+
+// ThirdPartyBudget fails if any third party transferred more than limitKB kilobytes.
+// Per-site limits override the default one, e.g. {"googletagmanager.com": 150}
+func ThirdPartyBudget(parties []*ThirdParty, limitKB float64, perSite map[string]float64) error {
+	var over []*ThirdParty
+	for _, t := range parties {
+		limit := limitKB
+		if v, ok := perSite[t.Site]; ok {
+			limit = v
+		}
+		if t.Bytes > limit*1024 {
+			over = append(over, t)
+		}
+	}
+	if len(over) > 0 {
+		return BudgetError{LimitKB: limitKB, Over: over}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package control
+
+import (
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/fetch"
+)
+
+const (
+	AuthSourceServer = "Server"
+	AuthSourceProxy  = "Proxy"
+)
+
+// Credentials answer to an HTTP authentication challenge
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialsProvider returns credentials for the challenge (basic, digest or NTLM of a server or a proxy),
+// nil - the browser's default behaviour
+type CredentialsProvider func(challenge *fetch.AuthChallenge) *Credentials
+
+// Authenticate answers all server and proxy authentication challenges with the credentials
+func (s Session) Authenticate(username, password string) error {
+	c := &Credentials{Username: username, Password: password}
+	return s.SetCredentialsProvider(func(*fetch.AuthChallenge) *Credentials { return c })
+}
+
+// AuthenticateOrigin answers challenges of origins matching the pattern (e.g. "https://*.staging.example.com"
+// or "http://proxy:3128") with the credentials, other challenges are left to the browser. It replaces previous providers
+func (s Session) AuthenticateOrigin(pattern, username, password string) error {
+	c := &Credentials{Username: username, Password: password}
+	return s.SetCredentialsProvider(func(challenge *fetch.AuthChallenge) *Credentials {
+		if wildcard.Match(pattern, challenge.Origin) {
+			return c
+		}
+		return nil
+	})
+}
+
+// SetCredentialsProvider answers authentication challenges with credentials of the provider, nil - stop answering.
+// Rejected credentials are not sent again for the same request, the challenge is cancelled instead.
+// Requests are paused by Fetch domain while a provider is set
+func (s Session) SetCredentialsProvider(provider CredentialsProvider) error {
+	i := s.interceptor
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	prev := i.auth
+	i.auth = nil
+	if provider != nil {
+		i.auth = func(v *fetch.AuthRequired) *fetch.AuthChallengeResponse {
+			c := provider(v.AuthChallenge)
+			if c == nil {
+				return nil
+			}
+			return &fetch.AuthChallengeResponse{Response: "ProvideCredentials", Username: c.Username, Password: c.Password}
+		}
+	}
+	if err := s.updateFetch(); err != nil {
+		i.auth = prev
+		return err
+	}
+	return nil
+}
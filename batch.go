@@ -0,0 +1,82 @@
+package control
+
+import (
+	"context"
+
+	"github.com/ecwid/control/transport"
+)
+
+// Batch commands of the session sent in one go, see Session.Batch
+type Batch struct {
+	s        *Session
+	commands []*transport.Command
+}
+
+// Batch collects commands to pipeline them, e.g. to read attributes of many nodes in one round trip:
+//
+//	batch := session.Batch()
+//	for n, id := range nodes {
+//		batch.Add("DOM.getAttributes", dom.GetAttributesArgs{NodeId: id}, &values[n])
+//	}
+//	err := batch.Do(ctx)
+func (s Session) Batch() *Batch {
+	return &Batch{s: &s}
+}
+
+// Add queues the command, recv (optional) receives the result when Do returns. SessionID and Err of the command are set by Do
+func (b *Batch) Add(method string, send, recv interface{}) *transport.Command {
+	cmd := &transport.Command{Method: method, Args: send, Value: recv}
+	b.commands = append(b.commands, cmd)
+	return cmd
+}
+
+// Len number of queued commands
+func (b *Batch) Len() int {
+	return len(b.commands)
+}
+
+// Do sends queued commands in order without waiting for responses and then waits for all of them.
+// The batch counts as one action of the rate limit (see SetRateLimit). The first error is returned, the batch is empty afterwards
+func (b *Batch) Do(ctx context.Context) error {
+	commands := b.commands
+	b.commands = nil
+	if len(commands) == 0 {
+		return nil
+	}
+	select {
+	case <-b.s.context.Done():
+		return b.s.closedErr()
+	default:
+	}
+	b.s.throttle()
+	for _, cmd := range commands {
+		cmd.Method, cmd.Args = b.s.browser.shim(cmd.Method, cmd.Args)
+		defer b.s.activity.begin(cmd.Method)()
+	}
+	pending := commands
+	for len(pending) > 0 {
+		// commands are sent with the ID of the session on the current connection, see Session.CallContext
+		id, gen, err := b.s.id.current(ctx, b.s.browser.Client)
+		if err != nil {
+			return err
+		}
+		for _, cmd := range pending {
+			cmd.SessionID, cmd.Err = string(id), nil
+		}
+		_ = b.s.browser.Client.Pipeline(transport.WithGeneration(ctx, gen), pending...)
+		// commands which weren't sent because the client has reconnected meanwhile wait for the session to be attached again
+		var stale []*transport.Command
+		for _, cmd := range pending {
+			if cmd.Err == transport.ErrStaleGeneration {
+				stale = append(stale, cmd)
+			}
+		}
+		pending = stale
+	}
+	for _, cmd := range commands {
+		if cmd.Err != nil {
+			return cmd.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package control
+
+import (
+	"sync"
+
+	"github.com/ecwid/control/protocol/runtime"
+)
+
+// bindings counts references to bindings of the session added by AddBinding
+type bindings struct {
+	mx     sync.Mutex
+	counts map[string]int
+}
+
+// AddBinding exposes window[name](payload string) to the page, every call of it invokes function.
+// Bindings survive navigations. The same name may be added several times (e.g. by concurrent captures),
+// cancel unsubscribes the function and removes the binding when the last one is cancelled
+func (s Session) AddBinding(name string, function func(payload string)) (cancel func(), err error) {
+	unsubscribe := s.onBindingCalled(name, function)
+	b := s.bindings
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if b.counts[name] == 0 {
+		if err = runtime.AddBinding(s, runtime.AddBindingArgs{Name: name}); err != nil {
+			unsubscribe()
+			return nil, err
+		}
+	}
+	b.counts[name]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			b.mx.Lock()
+			defer b.mx.Unlock()
+			if b.counts[name]--; b.counts[name] == 0 {
+				delete(b.counts, name)
+				_ = runtime.RemoveBinding(s, runtime.RemoveBindingArgs{Name: name})
+			}
+		})
+	}, nil
+}
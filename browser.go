@@ -4,8 +4,9 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ecwid/control/protocol"
 	"github.com/ecwid/control/protocol/browser"
-	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/protocol/common"
 	"github.com/ecwid/control/protocol/page"
 	"github.com/ecwid/control/protocol/runtime"
 	"github.com/ecwid/control/protocol/target"
@@ -14,14 +15,30 @@ import (
 
 type BrowserContext struct {
 	Client *transport.Client
+	state  *browserState
+}
+
+// browserState is shared between all copies of BrowserContext
+type browserState struct {
+	mx         sync.Mutex
+	filterMx   sync.Mutex // serializes SetTargetFilter, never taken by the reading goroutine
+	filter     *TargetFilter
+	autoClose  func()
+	activated  []target.TargetID // most recently activated first
+	caps       *Capabilities
+	onError    func(error)
+	sessions   map[target.SessionID]*Session // live sessions
+	cpuSamples map[int]cpuSample
 }
 
 func New(client *transport.Client) BrowserContext {
-	return BrowserContext{Client: client}
+	b := BrowserContext{Client: client, state: &browserState{sessions: map[target.SessionID]*Session{}}}
+	client.OnReconnect(b.reattach)
+	return b
 }
 
 func (b BrowserContext) Call(method string, send, recv interface{}) error {
-	return b.Client.Call("", method, send, recv)
+	return b.call("", method, send, recv)
 }
 
 func (b BrowserContext) Crash() error {
@@ -32,47 +49,70 @@ func (b BrowserContext) Close() error {
 	return b.Client.Close()
 }
 
+// Shutdown gracefully detaches from the browser, see transport.Client.Shutdown
+func (b BrowserContext) Shutdown(ctx context.Context) error {
+	return b.Client.Shutdown(ctx)
+}
+
 func (b BrowserContext) SetDiscoverTargets(discover bool) error {
 	return target.SetDiscoverTargets(b, target.SetDiscoverTargetsArgs{Discover: discover})
 }
 
 func (b BrowserContext) runSession(targetID target.TargetID, sessionID target.SessionID) (session *Session, err error) {
-	session = &Session{
-		id:         sessionID,
-		tid:        targetID,
-		browser:    b,
-		eventPool:  make(chan transport.Event, 20000),
-		publisher:  transport.NewPublisher(),
-		executions: &sync.Map{},
-	}
-	session.context, session.cancelCtx = context.WithCancel(b.Client.Context())
-	session.Input = Input{s: session, mx: &sync.Mutex{}}
-	session.Network = Network{s: session}
-	session.Emulation = Emulation{s: session}
+	session = b.newSession(targetID, sessionID)
 
-	go session.handleEventPool()
-	session.detach = b.Client.Register(session)
-
-	if err = page.Enable(session); err != nil {
+	// Page and Runtime are used by the session itself, the rest of domains are enabled on demand
+	if err = session.holdDomain("Page"); err != nil {
 		return nil, err
 	}
-	if err = runtime.Enable(session); err != nil {
+	if err = session.holdDomain("Runtime"); err != nil {
 		return nil, err
 	}
-	if err = runtime.AddBinding(session, runtime.AddBindingArgs{Name: bindClick}); err != nil {
+	if err = setup(session); err != nil {
 		return nil, err
 	}
-	if err = page.SetLifecycleEventsEnabled(session, page.SetLifecycleEventsEnabledArgs{Enabled: true}); err != nil {
-		return nil, err
+	return
+}
+
+// setup session-scoped settings used by the session itself
+func setup(s protocol.Caller) error {
+	if err := runtime.AddBinding(s, runtime.AddBindingArgs{Name: bindClick}); err != nil {
+		return err
 	}
-	if err = target.SetDiscoverTargets(session, target.SetDiscoverTargetsArgs{Discover: true}); err != nil {
-		return nil, err
+	if err := page.SetLifecycleEventsEnabled(s, page.SetLifecycleEventsEnabledArgs{Enabled: true}); err != nil {
+		return err
 	}
-	// maxPostDataSize - The Longest post body size (in bytes) that would be included in requestWillBeSent notification
-	if err = network.Enable(session, network.EnableArgs{MaxPostDataSize: 20 * 1024}); err != nil {
-		return nil, err
+	return target.SetDiscoverTargets(s, target.SetDiscoverTargetsArgs{Discover: true})
+}
+
+// newSession registers the session to receive its events, nothing is sent to the browser
+func (b BrowserContext) newSession(targetID target.TargetID, id target.SessionID) *Session {
+	session := &Session{
+		id:          newSessionID(id, b.Client.Generation()),
+		tid:         targetID,
+		browser:     b,
+		eventPool:   make(chan transport.Event, 20000),
+		publisher:   transport.NewPublisher(),
+		executions:  &sync.Map{},
+		detached:    &sync.Map{},
+		domains:     &domains{counts: map[string]int{}, held: map[string]bool{}},
+		config:      &sessionConfig{},
+		activity:    newActivity(),
+		interceptor: &interceptor{routes: map[uint64]interceptRoute{}},
+		bindings:    &bindings{counts: map[string]int{}},
 	}
-	return
+	session.context, session.cancelCtx = context.WithCancel(b.Client.Context())
+	session.Input = Input{s: session, mx: &sync.Mutex{}, state: newInputState()}
+	session.Network = Network{s: session}
+	session.Emulation = Emulation{s: session}
+
+	b.state.mx.Lock()
+	b.state.sessions[id] = session
+	b.state.mx.Unlock()
+
+	go session.handleEventPool()
+	session.detach = b.Client.Register(session)
+	return session
 }
 
 func (b BrowserContext) AttachPageTarget(id target.TargetID) (*Session, error) {
@@ -86,21 +126,48 @@ func (b BrowserContext) AttachPageTarget(id target.TargetID) (*Session, error) {
 	return b.runSession(id, val.SessionId)
 }
 
+// TargetOptions optional parameters of a new tab
+type TargetOptions struct {
+	Background       bool // don't foreground the tab (headful only)
+	NewWindow        bool // open the tab in a new window (headful only)
+	Width, Height    int  // frame size in DIP (headless only)
+	BrowserContextID common.BrowserContextID
+}
+
 func (b BrowserContext) CreatePageTarget(url string) (*Session, error) {
+	return b.CreatePageTargetWithOptions(url, TargetOptions{})
+}
+
+// CreatePageTargetWithOptions opens a new tab, e.g. in background so it doesn't steal focus in headful mode
+func (b BrowserContext) CreatePageTargetWithOptions(url string, options TargetOptions) (*Session, error) {
 	if url == "" {
 		url = Blank // headless chrome crash when url is empty
 	}
-	r, err := target.CreateTarget(b, target.CreateTargetArgs{Url: url})
+	r, err := target.CreateTarget(b, target.CreateTargetArgs{
+		Url:              url,
+		Background:       options.Background,
+		NewWindow:        options.NewWindow,
+		Width:            options.Width,
+		Height:           options.Height,
+		BrowserContextId: options.BrowserContextID,
+	})
 	if err != nil {
 		return nil, err
 	}
+	if !options.Background {
+		b.touchTarget(r.TargetId) // new tab is foregrounded
+	}
 	return b.AttachPageTarget(r.TargetId)
 }
 
 func (b BrowserContext) ActivateTarget(id target.TargetID) error {
-	return target.ActivateTarget(b, target.ActivateTargetArgs{
+	err := target.ActivateTarget(b, target.ActivateTargetArgs{
 		TargetId: id,
 	})
+	if err == nil {
+		b.touchTarget(id)
+	}
+	return err
 }
 
 func (b BrowserContext) CloseTarget(id target.TargetID) (err error) {
@@ -112,10 +179,21 @@ func (b BrowserContext) CloseTarget(id target.TargetID) (err error) {
 	return err
 }
 
+// GetTargets returns all targets except ones ignored by TargetFilter
 func (b BrowserContext) GetTargets() ([]*target.TargetInfo, error) {
 	val, err := target.GetTargets(b, target.GetTargetsArgs{})
 	if err != nil {
 		return nil, err
 	}
-	return val.TargetInfos, nil
+	filter := b.targetFilter()
+	if filter == nil {
+		return val.TargetInfos, nil
+	}
+	targets := make([]*target.TargetInfo, 0, len(val.TargetInfos))
+	for _, t := range val.TargetInfos {
+		if !filter.Match(t) {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
 }
@@ -0,0 +1,193 @@
+// Package cache serves repeated requests of a session from a disk cache through Fetch interception,
+// so repeated scrapes of mostly static sites don't download unchanged resources again
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/protocol/network"
+)
+
+// Cache of GET responses keyed by URL and KeyHeaders
+type Cache struct {
+	Dir        string
+	TTL        time.Duration // entries older than TTL are fetched again, zero - never expire
+	KeyHeaders []string      // request headers which vary responses, e.g. "Accept-Language"
+	// Filter decides whether a resource may be cached, nil - everything except documents (to keep pages fresh)
+	Filter func(url string, typ network.ResourceType) bool
+
+	mx     sync.Mutex
+	hits   uint64
+	misses uint64
+}
+
+// Stats cache hits and misses since creation
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type entry struct {
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Stored time.Time   `json:"stored"`
+}
+
+// New opens (creates) a cache in dir
+func New(dir string, ttl time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir, TTL: ttl}, nil
+}
+
+// Attach serves requests of the session matching the URL pattern from the cache and stores missed responses
+func (c *Cache) Attach(session *control.Session, pattern string) (cancel func(), err error) {
+	cancelRequest, err := session.Intercept(pattern, control.StageRequest, c.request)
+	if err != nil {
+		return nil, err
+	}
+	cancelResponse, err := session.Intercept(pattern, control.StageResponse, c.response)
+	if err != nil {
+		cancelRequest()
+		return nil, err
+	}
+	return func() {
+		cancelRequest()
+		cancelResponse()
+	}, nil
+}
+
+// Stats of the cache
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+// Clear removes all entries
+func (c *Cache) Clear() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		_ = os.Remove(f)
+		_ = os.Remove(strings.TrimSuffix(f, ".json") + ".body")
+	}
+	return nil
+}
+
+func (c *Cache) cacheable(i *control.Interception) bool {
+	if i.Request.Method != http.MethodGet {
+		return false
+	}
+	if c.Filter != nil {
+		return c.Filter(i.Request.Url, i.ResourceType)
+	}
+	return i.ResourceType != "Document"
+}
+
+func (c *Cache) key(r *network.Request) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.Url))
+	for _, name := range c.KeyHeaders {
+		h.Write([]byte("\n" + strings.ToLower(name) + ": " + requestHeader(r, name)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) request(i *control.Interception) {
+	if !c.cacheable(i) {
+		return
+	}
+	e, body, ok := c.load(c.key(i.Request))
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return
+	}
+	atomic.AddUint64(&c.hits, 1)
+	i.Fulfill(e.Status, e.Header, body)
+}
+
+func (c *Cache) response(i *control.Interception) {
+	if !c.cacheable(i) || i.Status != http.StatusOK || !storable(i.Header) {
+		return
+	}
+	body, err := i.Body()
+	if err != nil {
+		return
+	}
+	header := i.Header.Clone()
+	// the body is stored decoded
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	c.store(c.key(i.Request), entry{URL: i.Request.Url, Status: i.Status, Header: header, Stored: time.Now()}, body)
+}
+
+func (c *Cache) load(key string) (*entry, []byte, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	meta, err := ioutil.ReadFile(filepath.Join(c.Dir, key+".json"))
+	if err != nil {
+		return nil, nil, false
+	}
+	e := &entry{}
+	if err = json.Unmarshal(meta, e); err != nil {
+		return nil, nil, false
+	}
+	if c.TTL > 0 && time.Since(e.Stored) > c.TTL {
+		return nil, nil, false
+	}
+	body, err := ioutil.ReadFile(filepath.Join(c.Dir, key+".body"))
+	if err != nil {
+		return nil, nil, false
+	}
+	return e, body, true
+}
+
+func (c *Cache) store(key string, e entry, body []byte) {
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	// the body goes first, so metadata never points to a missing body
+	if err = ioutil.WriteFile(filepath.Join(c.Dir, key+".body"), body, 0644); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(filepath.Join(c.Dir, key+".json"), meta, 0644)
+}
+
+// storable responses don't forbid storing and are not personalized by cookies
+func storable(h http.Header) bool {
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private") && h.Get("Set-Cookie") == ""
+}
+
+func requestHeader(r *network.Request, name string) string {
+	if r.Headers == nil {
+		return ""
+	}
+	m, _ := (*r.Headers).(map[string]interface{})
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			s, _ := v.(string)
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package control
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/ecwid/control/protocol/page"
+)
+
+const (
+	functionCanvasToDataURL = `function(){return this.toDataURL("image/png")}`
+	functionCanvasGeometry  = `function(){return {width:this.width,height:this.height,dpr:window.devicePixelRatio}}`
+)
+
+// CaptureCanvas returns PNG encoded pixels of the canvas element.
+// A canvas tainted by cross-origin content can't be read with toDataURL, and a WebGL canvas created without
+// preserveDrawingBuffer reads as fully transparent. In both cases the page screenshot is clipped to the canvas box
+// and scaled to the canvas resolution
+func (e Element) CaptureCanvas() ([]byte, error) {
+	if e.node.NodeName != "CANVAS" {
+		return nil, ErrNotCanvas
+	}
+	val, err := e.CallFunction(functionCanvasToDataURL, true, true, nil)
+	if err != nil {
+		var runtimeError RuntimeError
+		if !errors.As(err, &runtimeError) {
+			return nil, err
+		}
+		return e.captureCanvasBox()
+	}
+	dataURL, err := primitiveRemoteObject(*val).String()
+	if err != nil {
+		return nil, err
+	}
+	n := strings.IndexByte(dataURL, ',')
+	if n == -1 {
+		return nil, errors.New("unexpected canvas data url")
+	}
+	b, err := base64.StdEncoding.DecodeString(dataURL[n+1:])
+	if err != nil {
+		return nil, err
+	}
+	if transparentPNG(b) {
+		return e.captureCanvasBox()
+	}
+	return b, nil
+}
+
+// transparentPNG reports whether every pixel of the image is fully transparent
+func transparentPNG(b []byte) bool {
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		for i := 3; i < len(nrgba.Pix); i += 4 {
+			if nrgba.Pix[i] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e Element) captureCanvasBox() ([]byte, error) {
+	val, err := e.CallFunction(functionCanvasGeometry, true, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	var geometry struct {
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+		DPR    float64 `json:"dpr"`
+	}
+	if err = primitiveRemoteObject(*val).Decode(&geometry); err != nil {
+		return nil, err
+	}
+	rect, err := e.GetRectangle()
+	if err != nil {
+		return nil, err
+	}
+	scale := 1.0
+	if rect.Width > 0 && geometry.DPR > 0 {
+		// screenshot is taken in device pixels, bring it to the canvas' own resolution
+		scale = geometry.Width / (rect.Width * geometry.DPR)
+	}
+	return e.screenshot("png", 0, scale)
+}
+
+// Screenshot captures the element's box
+func (e Element) Screenshot(format string, quality int) ([]byte, error) {
+	return e.screenshot(format, quality, 1)
+}
+
+func (e Element) screenshot(format string, quality int, scale float64) ([]byte, error) {
+	if err := e.ScrollIntoView(); err != nil {
+		return nil, err
+	}
+	rect, err := e.GetRectangle()
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := e.frame.session.GetLayoutMetrics()
+	if err != nil {
+		return nil, err
+	}
+	// clip is in document coordinates while the rectangle is relative to the viewport
+	clip := &page.Viewport{
+		X:      rect.X + metrics.CssVisualViewport.PageX,
+		Y:      rect.Y + metrics.CssVisualViewport.PageY,
+		Width:  rect.Width,
+		Height: rect.Height,
+		Scale:  scale,
+	}
+	return e.frame.session.CaptureScreenshot(format, quality, clip, false, false)
+}
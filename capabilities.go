@@ -0,0 +1,78 @@
+package control
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ecwid/control/protocol/browser"
+)
+
+type HeadlessMode string
+
+const (
+	HeadlessNone HeadlessMode = ""
+	HeadlessOld  HeadlessMode = "old" // chrome-headless-shell or --headless=old
+	HeadlessNew  HeadlessMode = "new"
+)
+
+// Capabilities of the connected browser
+type Capabilities struct {
+	Product          string
+	Major            int // major version of the browser, 0 if unknown
+	ProtocolVersion  string
+	UserAgent        string
+	Headless         HeadlessMode
+	DownloadBehavior bool // Browser.setDownloadBehavior is available (Chrome 78+)
+}
+
+type NotSupportedError struct {
+	Feature string
+	Product string
+}
+
+func (e NotSupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported by %s", e.Feature, e.Product)
+}
+
+// Capabilities returns capabilities of the browser, detected once per client
+func (b BrowserContext) Capabilities() (*Capabilities, error) {
+	b.state.mx.Lock()
+	caps := b.state.caps
+	b.state.mx.Unlock()
+	if caps != nil {
+		return caps, nil
+	}
+	// don't use b.Call, it could be shimmed by capabilities
+	var val = &browser.GetVersionVal{}
+	if err := b.Client.Call("", "Browser.getVersion", nil, val); err != nil {
+		return nil, err
+	}
+	caps = detectCapabilities(val)
+	b.state.mx.Lock()
+	b.state.caps = caps
+	b.state.mx.Unlock()
+	return caps, nil
+}
+
+func detectCapabilities(v *browser.GetVersionVal) *Capabilities {
+	caps := &Capabilities{
+		Product:         v.Product,
+		ProtocolVersion: v.ProtocolVersion,
+		UserAgent:       v.UserAgent,
+	}
+	// Product is "HeadlessChrome/120.0.6099.109" for old headless and "Chrome/120.0.6099.109" for the rest
+	name, version := v.Product, ""
+	if n := strings.Index(v.Product, "/"); n != -1 {
+		name, version = v.Product[:n], v.Product[n+1:]
+	}
+	caps.Major, _ = strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	switch {
+	case name == "HeadlessChrome":
+		caps.Headless = HeadlessOld
+	case strings.Contains(v.UserAgent, "HeadlessChrome"):
+		caps.Headless = HeadlessNew
+	}
+	caps.DownloadBehavior = caps.Major == 0 || caps.Major >= 78
+	return caps
+}
@@ -0,0 +1,29 @@
+package chrome
+
+import "strings"
+
+// BlinkFeatures returns flags enabling and disabling web platform (Blink) runtime features,
+// e.g. BlinkFeatures([]string{"WebAssemblyJSPromiseIntegration"}, []string{"LazyFrameLoading"}).
+// Features are switched for the whole browser process, see https://source.chromium.org/chromium/chromium/src/+/main:third_party/blink/renderer/platform/runtime_enabled_features.json5
+func BlinkFeatures(enable, disable []string) []string {
+	var flags []string
+	if len(enable) > 0 {
+		flags = append(flags, "--enable-blink-features="+strings.Join(enable, ","))
+	}
+	if len(disable) > 0 {
+		flags = append(flags, "--disable-blink-features="+strings.Join(disable, ","))
+	}
+	return flags
+}
+
+// Features the same as BlinkFeatures for browser (Chromium) level features
+func Features(enable, disable []string) []string {
+	var flags []string
+	if len(enable) > 0 {
+		flags = append(flags, "--enable-features="+strings.Join(enable, ","))
+	}
+	if len(disable) > 0 {
+		flags = append(flags, "--disable-features="+strings.Join(disable, ","))
+	}
+	return flags
+}
@@ -20,13 +20,14 @@ type Browser struct {
 	cmd          *exec.Cmd
 	client       *transport.Client
 	UserDataDir  string
+	removeDir    bool // UserDataDir is a temporary profile created by Launch
 }
 
 func (c Browser) GetClient() *transport.Client {
 	return c.client
 }
 
-// Close close browser
+// Close close browser, kills the process tree if it's not closing gracefully and removes the temporary profile
 func (c Browser) Close() error {
 	// Close close browser and websocket connection
 	exited := make(chan int, 1)
@@ -35,51 +36,98 @@ func (c Browser) Close() error {
 		exited <- state.ExitCode()
 	}()
 	_ = c.client.Close()
+	var err error
 	select {
 	case <-exited:
-		return nil
 	case <-time.After(time.Second * 10):
-		if err := c.cmd.Process.Kill(); err != nil {
-			return err
+		if err = killProcessGroup(c.cmd); err == nil {
+			err = errors.New("browser is not closing gracefully, process was killed")
 		}
-		return errors.New("browser is not closing gracefully, process was killed")
+		<-exited
 	}
+	if c.removeDir {
+		// renderers may hold files for a moment after the browser exits
+		for attempt := 0; attempt < 5; attempt++ {
+			if rmErr := os.RemoveAll(c.UserDataDir); rmErr == nil {
+				break
+			}
+			time.Sleep(time.Millisecond * 200)
+		}
+	}
+	return err
+}
+
+// LaunchOptions of LaunchWithOptions
+type LaunchOptions struct {
+	Path         string        // browser binary, empty - the first one found of well-known names
+	Headless     bool          // --headless=new
+	UserDataDir  string        // persistent profile, empty - a fresh temporary one removed on Close
+	Proxy        string        // --proxy-server, e.g. "http://127.0.0.1:8080" or "socks5://host:1080"
+	MITMProxy    FlagSource    // intercepting proxy (*proxy.Proxy), its flags are added and take precedence over Proxy
+	WindowWidth  int           // --window-size, zero - browser default
+	WindowHeight int           // --window-size, zero - browser default
+	StartTimeout time.Duration // how long to wait for the DevTools endpoint, zero - 30 seconds
+	Env          []string      // extra environment variables of the process, e.g. "TZ=UTC"
+	Flags        []string      // any other command line flags
+}
+
+// FlagSource provides command line flags of the browser, e.g. *proxy.Proxy
+type FlagSource interface {
+	Flags() ([]string, error)
 }
 
 // Launch a new browser process
 func Launch(ctx context.Context, userFlags ...string) (*Browser, error) {
+	return LaunchWithOptions(ctx, LaunchOptions{Flags: userFlags})
+}
+
+// LaunchWithOptions starts a browser process, waits for its DevTools endpoint and connects to it.
+// The process is started in its own process group, so Close kills renderers and helpers too
+func LaunchWithOptions(ctx context.Context, options LaunchOptions) (*Browser, error) {
 	browser := &Browser{}
 	var (
-		path string
+		path = options.Path
 		err  error
 	)
-	bin := []string{
-		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
-		"/usr/bin/google-chrome",
-		"headless-shell",
-		"browser",
-		"chromium",
-		"chromium-browser",
-		"google-chrome",
-		"google-chrome-stable",
-		"google-chrome-beta",
-		"google-chrome-unstable",
-	}
-	for _, c := range bin {
-		if _, err = exec.LookPath(c); err == nil {
-			path = c
-			break
+	if path == "" {
+		if path, err = lookupBinary(); err != nil {
+			return nil, err
 		}
 	}
 
-	if browser.UserDataDir, err = os.MkdirTemp("", "chrome-control"); err != nil {
-		return nil, err
+	var proxyFlags []string
+	if options.MITMProxy != nil {
+		if proxyFlags, err = options.MITMProxy.Flags(); err != nil {
+			return nil, err
+		}
 	}
 
 	// https://github.com/GoogleChrome/chrome-launcher/blob/master/docs/chrome-flags-for-tools.md
 	flags := []string{
 		"--remote-debugging-port=0",
-		"--user-data-dir=" + browser.UserDataDir,
+	}
+	userFlags := options.Flags
+	if options.UserDataDir != "" {
+		userFlags = append([]string{"--user-data-dir=" + options.UserDataDir}, userFlags...)
+	}
+	// persistent profile can be passed with user flags, otherwise use a fresh temporary one
+	if browser.UserDataDir = userDataDir(userFlags); browser.UserDataDir == "" {
+		if browser.UserDataDir, err = os.MkdirTemp("", "chrome-control"); err != nil {
+			return nil, err
+		}
+		browser.removeDir = true
+		flags = append(flags, "--user-data-dir="+browser.UserDataDir)
+	}
+	if options.Headless {
+		flags = append(flags, "--headless=new")
+	}
+	if options.MITMProxy != nil {
+		flags = append(flags, proxyFlags...)
+	} else if options.Proxy != "" {
+		flags = append(flags, "--proxy-server="+options.Proxy)
+	}
+	if options.WindowWidth > 0 && options.WindowHeight > 0 {
+		flags = append(flags, fmt.Sprintf("--window-size=%d,%d", options.WindowWidth, options.WindowHeight))
 	}
 
 	if len(userFlags) > 0 {
@@ -90,20 +138,79 @@ func Launch(ctx context.Context, userFlags ...string) (*Browser, error) {
 	}
 
 	browser.cmd = exec.CommandContext(ctx, path, flags...)
+	if len(options.Env) > 0 {
+		browser.cmd.Env = append(os.Environ(), options.Env...)
+	}
+	setProcessGroup(browser.cmd)
+	cleanup := func() {
+		_ = killProcessGroup(browser.cmd)
+		_ = browser.cmd.Wait()
+		if browser.removeDir {
+			_ = os.RemoveAll(browser.UserDataDir)
+		}
+	}
 	stderr, err := browser.cmd.StderrPipe()
 	if err != nil {
 		return nil, err
 	}
 	defer stderr.Close()
 	if err = browser.cmd.Start(); err != nil {
+		if browser.removeDir {
+			_ = os.RemoveAll(browser.UserDataDir)
+		}
 		return nil, err
 	}
-	browser.webSocketURL, err = addrFromStderr(stderr)
+	timeout := options.StartTimeout
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+	type result struct {
+		url string
+		err error
+	}
+	started := make(chan result, 1)
+	go func() {
+		url, err := addrFromStderr(stderr)
+		started <- result{url: url, err: err}
+	}()
+	select {
+	case r := <-started:
+		browser.webSocketURL, err = r.url, r.err
+	case <-time.After(timeout):
+		err = fmt.Errorf("DevTools endpoint didn't come up within %s", timeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
 	if err != nil {
+		cleanup()
 		return nil, err
 	}
-	browser.client, err = transport.Dial(ctx, browser.webSocketURL)
-	return browser, err
+	if browser.client, err = transport.Dial(ctx, browser.webSocketURL); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return browser, nil
+}
+
+func lookupBinary() (string, error) {
+	bin := []string{
+		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		"/usr/bin/google-chrome",
+		"headless-shell",
+		"browser",
+		"chromium",
+		"chromium-browser",
+		"google-chrome",
+		"google-chrome-stable",
+		"google-chrome-beta",
+		"google-chrome-unstable",
+	}
+	for _, c := range bin {
+		if _, err := exec.LookPath(c); err == nil {
+			return c, nil
+		}
+	}
+	return "", errors.New("chrome binary not found, set LaunchOptions.Path")
 }
 
 func addrFromStderr(rc io.ReadCloser) (string, error) {
@@ -129,3 +236,13 @@ func addrFromStderr(rc io.ReadCloser) (string, error) {
 	}
 	return url, nil
 }
+
+func userDataDir(flags []string) string {
+	const prefix = "--user-data-dir="
+	for _, f := range flags {
+		if strings.HasPrefix(f, prefix) {
+			return strings.Trim(strings.TrimPrefix(f, prefix), `"`)
+		}
+	}
+	return ""
+}
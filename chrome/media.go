@@ -0,0 +1,28 @@
+package chrome
+
+import "path/filepath"
+
+// FakeMediaFlags returns flags making getUserMedia return fake camera/mic streams without permission prompts.
+// video (.y4m or .mjpeg) and audio (.wav) files are played in a loop instead of the built-in test pattern and beep,
+// leave them empty to use defaults. Note the files are set per browser process
+func FakeMediaFlags(video, audio string) ([]string, error) {
+	flags := []string{
+		"--use-fake-ui-for-media-stream",
+		"--use-fake-device-for-media-stream",
+	}
+	if video != "" {
+		abs, err := filepath.Abs(video)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "--use-file-for-fake-video-capture="+abs)
+	}
+	if audio != "" {
+		abs, err := filepath.Abs(audio)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "--use-file-for-fake-audio-capture="+abs)
+	}
+	return flags, nil
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package chrome
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the browser in a new process group, so its children can be killed together
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	// negative pid addresses the whole group
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
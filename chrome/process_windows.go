@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package chrome
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills the browser with its child processes
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package chrome
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CloneProfile copies template user-data-dir (cookies, extensions, history) to a new temporary directory,
+// so every run starts from the same "remembered" state without modifying the template:
+//
+//	dir, err := chrome.CloneProfile("/path/to/template")
+//	browser, err := chrome.Launch(ctx, "--user-data-dir="+dir)
+func CloneProfile(template string) (string, error) {
+	dir, err := os.MkdirTemp("", "chrome-control")
+	if err != nil {
+		return "", err
+	}
+	err = filepath.Walk(template, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(template, path)
+		if err != nil {
+			return err
+		}
+		// lock files of a running browser must not be copied
+		if strings.HasPrefix(info.Name(), "Singleton") {
+			return nil
+		}
+		dst := filepath.Join(dir, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm()|0700)
+		case info.Mode().IsRegular():
+			return copyFile(path, dst, info.Mode().Perm())
+		default: // skip sockets, symlinks etc.
+			return nil
+		}
+	})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
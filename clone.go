@@ -0,0 +1,24 @@
+package control
+
+import "time"
+
+// CloneTab opens a new tab in the same browser context (cookies, storage, permissions are shared)
+// and navigates it to the current URL of this session waiting for load event
+func (s Session) CloneTab(timeout time.Duration) (*Session, error) {
+	info, err := s.TargetInfo()
+	if err != nil {
+		return nil, err
+	}
+	clone, err := s.browser.CreatePageTargetWithOptions(Blank, TargetOptions{BrowserContextID: info.BrowserContextId})
+	if err != nil {
+		return nil, err
+	}
+	if info.Url == "" || info.Url == Blank {
+		return clone, nil
+	}
+	if err = clone.Page().Navigate(info.Url, LifecycleLoad, timeout); err != nil {
+		_ = clone.Close()
+		return nil, err
+	}
+	return clone, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Scenario batch of steps executed against a single tab, read from JSON or (for .yaml and .yml files) YAML, see parseYAMLScenario. Example:
+//
+//	{
+//	  "timeout": "30s",
+//	  "steps": [
+//	    {"action": "navigate", "value": "https://example.com"},
+//	    {"action": "type", "selector": "input[name=q]", "value": "shoes"},
+//	    {"action": "click", "selector": "button[type=submit]"},
+//	    {"action": "wait", "selector": ".results"},
+//	    {"action": "assert", "selector": "h1", "value": "Results"},
+//	    {"action": "screenshot", "value": "results.png"}
+//	  ]
+//	}
+type Scenario struct {
+	Timeout duration `json:"timeout"` // per step, 60s by default
+	Steps   []Step   `json:"steps"`
+}
+
+// Step action is one of navigate, click, type, wait, assert, eval, screenshot
+type Step struct {
+	Action   string `json:"action"`
+	Selector string `json:"selector,omitempty"`
+	Value    string `json:"value,omitempty"` // URL, text to type, expected substring, expression or file name
+}
+
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	*d = duration(v)
+	return err
+}
+
+func loadScenario(file string) (*Scenario, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+		s, err := parseYAMLScenario(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		return s, nil
+	}
+	s := &Scenario{}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return s, nil
+}
+
+// runScenario executes steps in a new tab stopping at the first failure
+func runScenario(sh *shell, s *Scenario) error {
+	sh.timeout = time.Duration(s.Timeout)
+	if err := sh.open(""); err != nil {
+		return err
+	}
+	defer func() { _ = sh.session.Close() }()
+	for n, step := range s.Steps {
+		start := time.Now()
+		err := sh.step(step)
+		status := "ok"
+		if err != nil {
+			status = "FAIL"
+		}
+		fmt.Fprintf(sh.out, "%s\t%d %s %s\t%s\n", status, n+1, step.Action, step.Selector, time.Since(start).Round(time.Millisecond))
+		if err != nil {
+			return fmt.Errorf("step %d (%s): %v", n+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+func (sh *shell) step(step Step) error {
+	switch step.Action {
+	case "navigate":
+		return sh.navigate(step.Value)
+	case "click":
+		return sh.click(step.Selector)
+	case "type":
+		e, err := sh.element(step.Selector)
+		if err != nil {
+			return err
+		}
+		return e.InsertText(step.Value)
+	case "wait":
+		return sh.waitFor(step.Selector)
+	case "assert":
+		return sh.assert(step.Selector, step.Value)
+	case "eval":
+		return sh.eval(step.Value)
+	case "screenshot":
+		return sh.screenshot(step.Value)
+	}
+	return fmt.Errorf("unknown action `%s`", step.Action)
+}
+
+func (sh *shell) waitFor(selector string) error {
+	page, err := sh.page()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(sh.wait())
+	for !page.IsExist(selector) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("`%s` didn't appear in %s", selector, sh.wait())
+		}
+		time.Sleep(time.Millisecond * 200)
+	}
+	return nil
+}
+
+// assert checks that the element exists and its text contains expected substring (if any)
+func (sh *shell) assert(selector, expected string) error {
+	e, err := sh.element(selector)
+	if err != nil {
+		return err
+	}
+	if expected == "" {
+		return nil
+	}
+	text, err := e.GetText()
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(text, expected) {
+		return fmt.Errorf("text of `%s` is %q, doesn't contain %q", selector, text, expected)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Command witness is an interactive shell to poke at pages and try selectors without writing a Go program
+//
+//	witness                      launch a local browser
+//	witness -url ws://host/...   connect to a running browser by its DevTools websocket URL
+//	witness -run scenario.json   execute JSON (or .yaml) scenario and exit, non-zero exit code on failure (see Scenario)
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/chrome"
+	"github.com/ecwid/control/transport"
+)
+
+func main() {
+	var (
+		url      = flag.String("url", "", "DevTools websocket URL of a running browser, a new browser is launched if empty")
+		headless = flag.Bool("headless", false, "launch the browser in headless mode")
+		run      = flag.String("run", "", "JSON or YAML scenario file to execute in batch mode")
+	)
+	flag.Parse()
+
+	var scenario *Scenario
+	if *run != "" {
+		var err error
+		if scenario, err = loadScenario(*run); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	client, closeBrowser, err := connect(*url, *headless || scenario != nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sh := &shell{browser: control.New(client), out: os.Stdout}
+	if scenario == nil {
+		repl(sh, os.Stdin)
+		closeBrowser()
+		return
+	}
+	err = runScenario(sh, scenario)
+	closeBrowser()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func connect(url string, headless bool) (*transport.Client, func(), error) {
+	if url != "" {
+		client, err := transport.Dial(context.Background(), url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func() { _ = client.Shutdown(context.Background()) }, nil
+	}
+	var flags []string
+	if headless {
+		flags = append(flags, "--headless=new")
+	}
+	browser, err := chrome.Launch(context.Background(), flags...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return browser.GetClient(), func() { _ = browser.Close() }, nil
+}
+
+func repl(sh *shell, in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	fmt.Fprint(sh.out, "> ")
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			return
+		}
+		if line != "" {
+			if err := sh.exec(line); err != nil {
+				fmt.Fprintln(sh.out, "error:", err)
+			}
+		}
+		fmt.Fprint(sh.out, "> ")
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+var errNoTab = errors.New("no tab, use `open` or `attach` first")
+
+type command struct {
+	usage string
+	run   func(sh *shell, arg string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"help":       {"help - list commands", (*shell).help},
+		"tabs":       {"tabs - list open tabs", (*shell).tabs},
+		"open":       {"open [url] - open a new tab", (*shell).open},
+		"attach":     {"attach <target id> - attach to an open tab", (*shell).attach},
+		"navigate":   {"navigate <url> - navigate current tab and wait for load", (*shell).navigate},
+		"reload":     {"reload - reload current tab", (*shell).reload},
+		"query":      {"query <selector> - list elements matching CSS selector", (*shell).query},
+		"text":       {"text <selector> - text of the first matching element", (*shell).text},
+		"click":      {"click <selector> - click the element", (*shell).click},
+		"type":       {"type <selector> <text> - insert text into the element", (*shell).typeText},
+		"eval":       {"eval <expression> - evaluate JavaScript in the main frame", (*shell).eval},
+		"screenshot": {"screenshot <file.png> - capture the viewport", (*shell).screenshot},
+		"pick":       {"pick - click an element in the browser to get its selector", (*shell).pick},
+		"quit":       {"quit - exit", nil},
+	}
+}
+
+type shell struct {
+	browser control.BrowserContext
+	session *control.Session
+	out     io.Writer
+	timeout time.Duration
+}
+
+func (sh *shell) exec(line string) error {
+	name, arg := line, ""
+	if n := strings.IndexAny(line, " \t"); n != -1 {
+		name, arg = line[:n], strings.TrimSpace(line[n+1:])
+	}
+	c, ok := commands[name]
+	if !ok || c.run == nil {
+		return fmt.Errorf("unknown command `%s`, try `help`", name)
+	}
+	return c.run(sh, arg)
+}
+
+func (sh *shell) page() (*control.Frame, error) {
+	if sh.session == nil {
+		return nil, errNoTab
+	}
+	return sh.session.Page(), nil
+}
+
+func (sh *shell) wait() time.Duration {
+	if sh.timeout == 0 {
+		return time.Second * 60
+	}
+	return sh.timeout
+}
+
+func (sh *shell) help(string) error {
+	var lines []string
+	for _, c := range commands {
+		lines = append(lines, c.usage)
+	}
+	sort.Strings(lines)
+	for _, l := range lines {
+		fmt.Fprintln(sh.out, " ", l)
+	}
+	return nil
+}
+
+func (sh *shell) tabs(string) error {
+	targets, err := sh.browser.GetPageTargets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		mark := " "
+		if sh.session != nil && t.TargetId == sh.session.GetTargetID() {
+			mark = "*"
+		}
+		fmt.Fprintf(sh.out, "%s %s %s %q\n", mark, t.TargetId, t.Url, t.Title)
+	}
+	return nil
+}
+
+func (sh *shell) open(url string) error {
+	session, err := sh.browser.CreatePageTarget("")
+	if err != nil {
+		return err
+	}
+	sh.session = session
+	if url != "" {
+		return sh.navigate(url)
+	}
+	return nil
+}
+
+func (sh *shell) attach(id string) error {
+	targets, err := sh.browser.GetPageTargets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if string(t.TargetId) == id || strings.HasPrefix(string(t.TargetId), id) && id != "" {
+			session, err := sh.browser.AttachPageTarget(t.TargetId)
+			if err != nil {
+				return err
+			}
+			sh.session = session
+			return nil
+		}
+	}
+	return fmt.Errorf("no tab %s", id)
+}
+
+func (sh *shell) navigate(url string) error {
+	page, err := sh.page()
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "about:") {
+		url = "https://" + url
+	}
+	err = page.Navigate(url, control.LifecycleLoad, sh.wait())
+	if errors.Is(err, control.ErrAlreadyNavigated) {
+		return nil
+	}
+	return err
+}
+
+func (sh *shell) reload(string) error {
+	page, err := sh.page()
+	if err != nil {
+		return err
+	}
+	return page.Reload(false, "", control.LifecycleLoad, sh.wait())
+}
+
+func (sh *shell) query(selector string) error {
+	page, err := sh.page()
+	if err != nil {
+		return err
+	}
+	elements, err := page.QuerySelectorAll(selector)
+	if err != nil {
+		return err
+	}
+	for n, e := range elements {
+		fmt.Fprintf(sh.out, "%d: %s\n", n, e.Description())
+	}
+	fmt.Fprintf(sh.out, "%d element(s)\n", len(elements))
+	return nil
+}
+
+func (sh *shell) element(selector string) (*control.Element, error) {
+	page, err := sh.page()
+	if err != nil {
+		return nil, err
+	}
+	return page.QuerySelector(selector)
+}
+
+func (sh *shell) text(selector string) error {
+	e, err := sh.element(selector)
+	if err != nil {
+		return err
+	}
+	text, err := e.GetText()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(sh.out, text)
+	return nil
+}
+
+func (sh *shell) click(selector string) error {
+	e, err := sh.element(selector)
+	if err != nil {
+		return err
+	}
+	return e.Click()
+}
+
+func (sh *shell) typeText(arg string) error {
+	selector, text, err := splitQuoted(arg)
+	if err != nil {
+		return err
+	}
+	e, err := sh.element(selector)
+	if err != nil {
+		return err
+	}
+	return e.InsertText(text)
+}
+
+func (sh *shell) eval(expression string) error {
+	page, err := sh.page()
+	if err != nil {
+		return err
+	}
+	v, err := page.Evaluate(expression, true, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "%v\n", v)
+	return nil
+}
+
+func (sh *shell) screenshot(file string) error {
+	if file == "" {
+		file = "screenshot.png"
+	}
+	if sh.session == nil {
+		return errNoTab
+	}
+	b, err := sh.session.CaptureScreenshot("png", 0, nil, false, false)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(file, b, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "saved %s (%d bytes)\n", file, len(b))
+	return nil
+}
+
+func (sh *shell) pick(string) error {
+	if sh.session == nil {
+		return errNoTab
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	fmt.Fprintln(sh.out, "click an element in the browser...")
+	e, selector, err := sh.session.PickElement(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "%s\n%s\n", e.Description(), selector)
+	return nil
+}
+
+// splitQuoted splits `selector text` where selector may be quoted if it contains spaces
+func splitQuoted(arg string) (string, string, error) {
+	if strings.HasPrefix(arg, `"`) || strings.HasPrefix(arg, "`") {
+		quoted := quotedPrefix(arg)
+		unquoted, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", "", err
+		}
+		return unquoted, strings.TrimSpace(arg[len(quoted):]), nil
+	}
+	n := strings.IndexAny(arg, " \t")
+	if n == -1 {
+		return arg, "", nil
+	}
+	return arg[:n], strings.TrimSpace(arg[n+1:]), nil
+}
+
+// quotedPrefix quoted token at the start of arg up to the closing quote (the whole arg if it isn't closed),
+// backslash escapes are skipped in double quotes
+func quotedPrefix(arg string) string {
+	quote := arg[0]
+	for n := 1; n < len(arg); n++ {
+		switch {
+		case arg[n] == '\\' && quote == '"':
+			n++
+		case arg[n] == quote:
+			return arg[:n+1]
+		}
+	}
+	return arg
+}
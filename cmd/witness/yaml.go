@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseYAMLScenario reads the YAML form of Scenario. Only the subset needed by scenarios is supported:
+// top-level timeout and steps keys, a block sequence of step mappings, plain, single- and double-quoted scalars
+// and comments. Example:
+//
+//	timeout: 30s
+//	steps:
+//	  - action: navigate
+//	    value: https://example.com
+//	  - action: type
+//	    selector: "input[name=q]"
+//	    value: shoes
+func parseYAMLScenario(b []byte) (*Scenario, error) {
+	var (
+		s       = &Scenario{}
+		step    *Step
+		inSteps bool
+		indent  = -1 // indent of keys of the current step
+	)
+	for n, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(stripComment(line), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || line == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", n+1)
+		}
+		depth := len(line) - len(trimmed)
+		item := trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+		if depth == 0 && !(inSteps && item) {
+			key, value, err := splitKey(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			inSteps, step = false, nil
+			switch key {
+			case "timeout":
+				v, err := time.ParseDuration(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", n+1, err)
+				}
+				s.Timeout = duration(v)
+			case "steps":
+				if value != "" {
+					return nil, fmt.Errorf("line %d: steps must be a block sequence", n+1)
+				}
+				inSteps = true
+			default:
+				return nil, fmt.Errorf("line %d: unknown key `%s`", n+1, key)
+			}
+			continue
+		}
+		if !inSteps {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n+1)
+		}
+		if item {
+			s.Steps = append(s.Steps, Step{})
+			step = &s.Steps[len(s.Steps)-1]
+			rest := strings.TrimLeft(strings.TrimPrefix(trimmed, "-"), " ")
+			indent = len(line) - len(rest)
+			if rest == "" {
+				indent = -1 // keys follow on the next lines
+				continue
+			}
+			trimmed, depth = rest, indent
+		}
+		if step == nil {
+			return nil, fmt.Errorf("line %d: step must start with `- `", n+1)
+		}
+		if indent == -1 {
+			indent = depth
+		}
+		if depth != indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n+1)
+		}
+		key, value, err := splitKey(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		switch key {
+		case "action":
+			step.Action = value
+		case "selector":
+			step.Selector = value
+		case "value":
+			step.Value = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown step key `%s`", n+1, key)
+		}
+	}
+	return s, nil
+}
+
+// splitKey splits `key: value` and unquotes the value
+func splitKey(line string) (key, value string, err error) {
+	n := strings.Index(line, ":")
+	if n == -1 || (n+1 < len(line) && line[n+1] != ' ') {
+		return "", "", fmt.Errorf("expected `key: value`, got `%s`", line)
+	}
+	key, value = line[:n], strings.TrimSpace(line[n+1:])
+	switch {
+	case value == "":
+	case value[0] == '"':
+		if value, err = strconv.Unquote(value); err != nil {
+			return "", "", fmt.Errorf("%s: invalid double-quoted value", key)
+		}
+	case value[0] == '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", "", fmt.Errorf("%s: invalid single-quoted value", key)
+		}
+		value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	case value[0] == '|' || value[0] == '>' || value[0] == '[' || value[0] == '{' || value[0] == '&' || value[0] == '*':
+		return "", "", fmt.Errorf("%s: block scalars, flow collections, anchors and aliases are not supported, quote the value", key)
+	}
+	return key, value, nil
+}
+
+// stripComment removes `# comment` which is not inside quotes
+func stripComment(line string) string {
+	var quote byte
+	for n := 0; n < len(line); n++ {
+		c := line[n]
+		switch {
+		case quote == '"' && c == '\\', quote == '\'' && c == '\'' && n+1 < len(line) && line[n+1] == '\'':
+			n++ // an escape in double quotes, '' in single quotes
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (n == 0 || line[n-1] == ' '):
+			quote = c
+		case c == '#' && (n == 0 || line[n-1] == ' ' || line[n-1] == '\t'):
+			return line[:n]
+		}
+	}
+	return line
+}
@@ -0,0 +1,263 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/log"
+	"github.com/ecwid/control/protocol/runtime"
+	"github.com/ecwid/control/transport"
+)
+
+// ConsoleMessage console API call (console.log, console.error...) or a browser log entry (network, violation, intervention...)
+type ConsoleMessage struct {
+	Source string        // "console-api" for console calls, Log domain source otherwise
+	Level  string        // log, debug, info, warning, error
+	Text   string        // arguments joined by space
+	Args   []interface{} // primitive values as is, objects as their descriptions
+	URL    string
+	Line   int // zero based
+	Column int // zero based
+	Time   time.Time
+}
+
+// JSException exception which was not caught by the page
+type JSException struct {
+	Message string
+	URL     string
+	Line    int // zero based
+	Column  int // zero based
+	Stack   string
+	Time    time.Time
+}
+
+func (e JSException) Error() string {
+	return fmt.Sprintf("%s (%s:%d:%d)", e.Message, e.URL, e.Line+1, e.Column+1)
+}
+
+// JSErrorsError page produced exceptions or console errors
+type JSErrorsError struct {
+	Exceptions []JSException
+	Errors     []ConsoleMessage
+}
+
+func (e JSErrorsError) Error() string {
+	var lines []string
+	for _, x := range e.Exceptions {
+		lines = append(lines, "exception: "+x.Error())
+	}
+	for _, m := range e.Errors {
+		lines = append(lines, fmt.Sprintf("console.%s: %s", m.Level, m.Text))
+	}
+	return fmt.Sprintf("%d JavaScript error(s):\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// OnConsole calls handler on every console message and browser log entry
+func (s Session) OnConsole(handler func(ConsoleMessage)) (cancel func(), err error) {
+	release, err := s.EnableDomain("Log")
+	if err != nil {
+		return nil, err
+	}
+	unsubscribe := s.Subscribe("*", func(e transport.Event) error {
+		m, ok, err := consoleMessage(e)
+		if ok {
+			handler(m)
+		}
+		return err
+	})
+	return func() {
+		unsubscribe()
+		release()
+	}, nil
+}
+
+// OnException calls handler on every uncaught exception and unhandled promise rejection
+func (s Session) OnException(handler func(JSException)) (cancel func()) {
+	return s.Subscribe("Runtime.exceptionThrown", func(e transport.Event) error {
+		var v = runtime.ExceptionThrown{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		handler(jsException(v))
+		return nil
+	})
+}
+
+// ConsoleLog buffers console messages and exceptions, see CaptureConsole
+type ConsoleLog struct {
+	mx         sync.Mutex
+	messages   []ConsoleMessage
+	exceptions []JSException
+	cancel     func()
+}
+
+// CaptureConsole buffers console messages and exceptions of the page until Stop is called
+func (s Session) CaptureConsole() (*ConsoleLog, error) {
+	l := &ConsoleLog{}
+	cancelConsole, err := s.OnConsole(func(m ConsoleMessage) {
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		l.messages = append(l.messages, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cancelException := s.OnException(func(e JSException) {
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		l.exceptions = append(l.exceptions, e)
+	})
+	l.cancel = func() {
+		cancelConsole()
+		cancelException()
+	}
+	return l, nil
+}
+
+// Stop capturing, messages captured so far are kept
+func (l *ConsoleLog) Stop() {
+	l.cancel()
+}
+
+// Messages captured so far
+func (l *ConsoleLog) Messages() []ConsoleMessage {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return append([]ConsoleMessage(nil), l.messages...)
+}
+
+// Exceptions captured so far
+func (l *ConsoleLog) Exceptions() []JSException {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return append([]JSException(nil), l.exceptions...)
+}
+
+// Reset forgets captured messages and exceptions
+func (l *ConsoleLog) Reset() {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.messages, l.exceptions = nil, nil
+}
+
+// AssertNoErrors returns JSErrorsError if there were exceptions or error-level messages
+func (l *ConsoleLog) AssertNoErrors() error {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	e := JSErrorsError{Exceptions: append([]JSException(nil), l.exceptions...)}
+	for _, m := range l.messages {
+		if m.Level == "error" {
+			e.Errors = append(e.Errors, m)
+		}
+	}
+	if len(e.Exceptions) == 0 && len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func consoleMessage(e transport.Event) (ConsoleMessage, bool, error) {
+	switch e.Method {
+	case "Runtime.consoleAPICalled":
+		var v = runtime.ConsoleAPICalled{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return ConsoleMessage{}, false, err
+		}
+		m := ConsoleMessage{Source: "console-api", Level: consoleLevel(v.Type), Time: epochMillis(float64(v.Timestamp))}
+		var texts []string
+		for _, arg := range v.Args {
+			value := remoteValue(arg)
+			m.Args = append(m.Args, value)
+			texts = append(texts, fmt.Sprint(value))
+		}
+		m.Text = strings.Join(texts, " ")
+		if v.StackTrace != nil && len(v.StackTrace.CallFrames) > 0 {
+			top := v.StackTrace.CallFrames[0]
+			m.URL, m.Line, m.Column = top.Url, top.LineNumber, top.ColumnNumber
+		}
+		return m, true, nil
+	case "Log.entryAdded":
+		var v = log.EntryAdded{}
+		if err := json.Unmarshal(e.Params, &v); err != nil || v.Entry == nil {
+			return ConsoleMessage{}, false, err
+		}
+		m := ConsoleMessage{
+			Source: v.Entry.Source,
+			Level:  v.Entry.Level,
+			Text:   v.Entry.Text,
+			URL:    v.Entry.Url,
+			Line:   v.Entry.LineNumber,
+			Time:   epochMillis(float64(v.Entry.Timestamp)),
+		}
+		for _, arg := range v.Entry.Args {
+			m.Args = append(m.Args, remoteValue(arg))
+		}
+		return m, true, nil
+	}
+	return ConsoleMessage{}, false, nil
+}
+
+// consoleLevel maps console API type to log level
+func consoleLevel(typ string) string {
+	switch typ {
+	case "error", "assert":
+		return "error"
+	case "warning":
+		return "warning"
+	case "debug":
+		return "debug"
+	case "info":
+		return "info"
+	}
+	return "log"
+}
+
+func remoteValue(o *runtime.RemoteObject) interface{} {
+	switch {
+	case o == nil:
+		return nil
+	case o.Type == "undefined":
+		return "undefined"
+	case o.UnserializableValue != "":
+		return string(o.UnserializableValue)
+	case o.Value != nil || o.Subtype == "null":
+		return o.Value
+	}
+	return o.Description
+}
+
+func jsException(v runtime.ExceptionThrown) JSException {
+	x := JSException{Time: epochMillis(float64(v.Timestamp))}
+	d := v.ExceptionDetails
+	if d == nil {
+		return x
+	}
+	x.Message, x.URL, x.Line, x.Column = d.Text, d.Url, d.LineNumber, d.ColumnNumber
+	if d.Exception != nil && d.Exception.Description == "" && d.Exception.Value != nil {
+		x.Message = fmt.Sprintf("%s %v", d.Text, d.Exception.Value) // e.g. throw "text"
+	}
+	if d.Exception != nil && d.Exception.Description != "" {
+		// description of an Error contains the message and the stack
+		desc := d.Exception.Description
+		if n := strings.Index(desc, "\n"); n != -1 {
+			x.Message, x.Stack = desc[:n], desc[n+1:]
+		} else {
+			x.Message = strings.TrimSpace(d.Text + " " + desc)
+		}
+	}
+	if x.Stack == "" && d.StackTrace != nil {
+		var frames []string
+		for _, f := range d.StackTrace.CallFrames {
+			frames = append(frames, fmt.Sprintf("    at %s (%s:%d:%d)", f.FunctionName, f.Url, f.LineNumber+1, f.ColumnNumber+1))
+		}
+		x.Stack = strings.Join(frames, "\n")
+	}
+	return x
+}
+
+func epochMillis(ms float64) time.Time {
+	return time.Unix(0, int64(ms*float64(time.Millisecond)))
+}
@@ -0,0 +1,147 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+// ErrDisallowed the URL is disallowed by robots.txt
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+const functionLinks = `Array.from(document.querySelectorAll("a[href]")).filter(a=>!/\bnofollow\b/i.test(a.rel)).map(a=>a.href)`
+
+// Crawler visits pages breadth-first starting from seeds and following links in scope.
+// Progress is kept in Store, so running the crawler again with the same FileStore resumes it
+type Crawler struct {
+	Session    *control.Session
+	Store      Store                                    // nil - MemoryStore
+	Politeness *Politeness                              // nil - robots.txt is not consulted and there is no delay
+	MaxDepth   int                                      // links deeper than this are not followed, zero - unlimited
+	MaxPages   int                                      // zero - unlimited
+	Retries    int                                      // attempts after the first failed one
+	Timeout    time.Duration                            // navigation timeout, zero - 30 seconds
+	Scope      func(u *url.URL) bool                    // nil - hosts of the seeds
+	Visit      func(page *control.Frame, e Entry) error // extraction hook, an error fails the visit
+}
+
+// Run crawls until the frontier is exhausted, MaxPages are visited or ctx is done
+func (c *Crawler) Run(ctx context.Context, seeds ...string) error {
+	if c.Store == nil {
+		c.Store = NewMemoryStore()
+	}
+	scope := c.Scope
+	if scope == nil {
+		hosts := map[string]bool{}
+		for _, seed := range seeds {
+			if u, err := url.Parse(seed); err == nil {
+				hosts[strings.ToLower(u.Hostname())] = true
+			}
+		}
+		scope = func(u *url.URL) bool { return hosts[strings.ToLower(u.Hostname())] }
+	}
+	for _, seed := range seeds {
+		if u := normalize(seed); u != "" {
+			if _, err := c.Store.Add(Entry{URL: u}); err != nil {
+				return err
+			}
+		}
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+	for visited := 0; c.MaxPages == 0 || visited < c.MaxPages; visited++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e, err := c.Store.Next()
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return nil
+		}
+		links, visitErr := c.visit(ctx, *e, timeout)
+		if err = ctx.Err(); err != nil {
+			return err // not the page's fault, it stays pending
+		}
+		retries := c.Retries
+		if visitErr == ErrDisallowed {
+			retries = 0
+		}
+		if err = c.Store.Done(e.URL, visitErr, retries); err != nil {
+			return err
+		}
+		if c.MaxDepth > 0 && e.Depth >= c.MaxDepth {
+			continue
+		}
+		for _, link := range links {
+			u, err := url.Parse(link)
+			if err != nil || !scope(u) {
+				continue
+			}
+			if _, err = c.Store.Add(Entry{URL: link, Depth: e.Depth + 1, Referrer: e.URL}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Crawler) visit(ctx context.Context, e Entry, timeout time.Duration) ([]string, error) {
+	if c.Politeness != nil {
+		allowed, err := c.Politeness.Allowed(ctx, e.URL)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrDisallowed
+		}
+		if err = c.Politeness.Wait(ctx, e.URL); err != nil {
+			return nil, err
+		}
+	}
+	page := c.Session.Page()
+	if err := page.Navigate(e.URL, control.LifecycleLoad, timeout); err != nil {
+		return nil, err
+	}
+	if c.Visit != nil {
+		if err := c.Visit(page, e); err != nil {
+			return nil, err
+		}
+	}
+	value, err := page.Evaluate(functionLinks, false, true)
+	if err != nil {
+		return nil, err
+	}
+	list, _ := value.([]interface{})
+	var links []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			if s = normalize(s); s != "" {
+				links = append(links, s)
+			}
+		}
+	}
+	return links, nil
+}
+
+// normalize drops fragments and non-HTTP links
+func normalize(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Host = strings.ToLower(u.Host)
+	return u.String()
+}
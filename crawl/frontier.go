@@ -0,0 +1,255 @@
+package crawl
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry URL known to the crawler
+type Entry struct {
+	URL      string    `json:"url"`
+	Depth    int       `json:"depth"`
+	Referrer string    `json:"referrer,omitempty"`
+	Added    time.Time `json:"added"`
+	Visited  bool      `json:"visited,omitempty"`
+	Attempts int       `json:"attempts,omitempty"`
+	Error    string    `json:"error,omitempty"` // of the last attempt
+}
+
+// Store keeps the frontier: pending URLs in order of discovery and results of visited ones.
+// Entries taken by Next but not marked Done are pending again after reopening the store, so a crashed crawl resumes
+type Store interface {
+	// Add appends the URL unless it's already known
+	Add(e Entry) (added bool, err error)
+	// Next takes the oldest pending entry, nil if there are none
+	Next() (*Entry, error)
+	// Done records the result of a visit, failed entries are retried while attempts < retries
+	Done(url string, visitErr error, retries int) error
+	// Stats counts known, visited and failed entries
+	Stats() (Stats, error)
+	Close() error
+}
+
+// Stats of the frontier
+type Stats struct {
+	Known   int
+	Pending int
+	Visited int
+	Failed  int
+}
+
+// MemoryStore keeps the frontier in memory
+type MemoryStore struct {
+	mx      sync.Mutex
+	entries map[string]*Entry
+	queue   []string
+	failed  map[string]bool // retries are exhausted
+}
+
+// NewMemoryStore creates empty in-memory frontier
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*Entry{}, failed: map[string]bool{}}
+}
+
+func (m *MemoryStore) Add(e Entry) (bool, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	return m.add(e), nil
+}
+
+func (m *MemoryStore) add(e Entry) bool {
+	if _, ok := m.entries[e.URL]; ok {
+		return false
+	}
+	if e.Added.IsZero() {
+		e.Added = time.Now()
+	}
+	m.entries[e.URL] = &e
+	m.queue = append(m.queue, e.URL)
+	return true
+}
+
+func (m *MemoryStore) Next() (*Entry, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if len(m.queue) == 0 {
+		return nil, nil
+	}
+	url := m.queue[0]
+	m.queue = m.queue[1:]
+	e := *m.entries[url]
+	return &e, nil
+}
+
+func (m *MemoryStore) Done(url string, visitErr error, retries int) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.done(url, visitErr, retries)
+	return nil
+}
+
+func (m *MemoryStore) done(url string, visitErr error, retries int) {
+	e, ok := m.entries[url]
+	if !ok {
+		return
+	}
+	e.Attempts++
+	if visitErr == nil {
+		e.Visited, e.Error = true, ""
+		return
+	}
+	e.Error = visitErr.Error()
+	if e.Attempts <= retries {
+		m.queue = append(m.queue, url)
+	} else {
+		m.failed[url] = true
+	}
+}
+
+func (m *MemoryStore) Stats() (Stats, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	s := Stats{Known: len(m.entries), Pending: len(m.queue)}
+	for _, e := range m.entries {
+		if e.Visited {
+			s.Visited++
+		}
+	}
+	s.Failed = len(m.failed)
+	return s, nil
+}
+
+// Entries snapshot of all known entries in arbitrary order
+func (m *MemoryStore) Entries() []Entry {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	list := make([]Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		list = append(list, *e)
+	}
+	return list
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// FileStore is MemoryStore persisted to an append-only journal (JSON lines), reopening the file resumes the crawl
+type FileStore struct {
+	*MemoryStore
+	file *os.File
+	w    *bufio.Writer
+}
+
+type journalRecord struct {
+	Op      string `json:"op"` // add, done
+	Entry   *Entry `json:"entry,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Retries int    `json:"retries,omitempty"`
+}
+
+// OpenFileStore opens or creates the journal at path and replays it
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{MemoryStore: NewMemoryStore(), file: f, w: bufio.NewWriter(f)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r journalRecord
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue // the last line may be torn by a crash
+		}
+		switch r.Op {
+		case "add":
+			if r.Entry != nil {
+				s.add(*r.Entry)
+			}
+		case "done":
+			var visitErr error
+			if r.Error != "" {
+				visitErr = visitError(r.Error)
+			}
+			s.done(r.URL, visitErr, r.Retries)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	// replay doesn't know which entries were taken by Next, everything not finished is pending again
+	var queue []string
+	for _, url := range s.queue {
+		if !s.entries[url].Visited && !s.failed[url] {
+			queue = append(queue, url)
+		}
+	}
+	s.queue = dedupe(queue)
+	return s, nil
+}
+
+type visitError string
+
+func (e visitError) Error() string {
+	return string(e)
+}
+
+func dedupe(list []string) []string {
+	seen := map[string]bool{}
+	out := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (s *FileStore) Add(e Entry) (bool, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if !s.add(e) {
+		return false, nil
+	}
+	return true, s.write(journalRecord{Op: "add", Entry: s.entries[e.URL]})
+}
+
+func (s *FileStore) Done(url string, visitErr error, retries int) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.done(url, visitErr, retries)
+	r := journalRecord{Op: "done", URL: url, Retries: retries}
+	if visitErr != nil {
+		r.Error = visitErr.Error()
+	}
+	return s.write(r)
+}
+
+// write appends the record and flushes it, so a crash loses at most the record being written
+func (s *FileStore) write(r journalRecord) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err = s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+func (s *FileStore) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if err := s.w.Flush(); err != nil {
+		_ = s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,127 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Politeness enforces robots.txt and a minimal delay between requests to the same host.
+// It's opt-in: crawlers consult Allowed before visiting a URL and Wait right before fetching it
+type Politeness struct {
+	UserAgent string        // product token is matched against robots.txt groups
+	Delay     time.Duration // minimal delay per host, Crawl-delay of robots.txt is used if it's longer
+	MaxDelay  time.Duration // caps Crawl-delay of robots.txt, zero - no cap
+	Client    *http.Client  // used to download robots.txt, nil - http.DefaultClient
+	// IgnoreRobots skips robots.txt, only Delay is applied
+	IgnoreRobots bool
+
+	mx    sync.Mutex
+	hosts map[string]*host
+}
+
+type host struct {
+	mx     sync.Mutex // serializes Wait of the host
+	ready  chan struct{}
+	robots *Robots
+	err    error
+	next   time.Time
+}
+
+// NewPoliteness creates politeness for the user agent with the default delay
+func NewPoliteness(userAgent string, delay time.Duration) *Politeness {
+	return &Politeness{UserAgent: userAgent, Delay: delay}
+}
+
+func (p *Politeness) host(ctx context.Context, rawURL string) (*host, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+	p.mx.Lock()
+	if p.hosts == nil {
+		p.hosts = map[string]*host{}
+	}
+	h, ok := p.hosts[key]
+	if !ok {
+		h = &host{ready: make(chan struct{})}
+		p.hosts[key] = h
+	}
+	p.mx.Unlock()
+	if !ok {
+		if p.IgnoreRobots {
+			h.robots = allowAll
+		} else {
+			h.robots, h.err = FetchRobots(ctx, p.Client, p.UserAgent, rawURL)
+		}
+		if h.err != nil {
+			// don't cache transient failures
+			p.mx.Lock()
+			delete(p.hosts, key)
+			p.mx.Unlock()
+		}
+		close(h.ready)
+	}
+	select {
+	case <-h.ready:
+		return h, h.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Robots of the URL's host, downloaded once per host
+func (p *Politeness) Robots(ctx context.Context, rawURL string) (*Robots, error) {
+	h, err := p.host(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return h.robots, nil
+}
+
+// Allowed reports whether robots.txt allows the URL
+func (p *Politeness) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	h, err := p.host(ctx, rawURL)
+	if err != nil {
+		return false, err
+	}
+	return h.robots.Allowed(p.UserAgent, rawURL), nil
+}
+
+// Wait blocks until the URL's host may be requested again and books the next slot
+func (p *Politeness) Wait(ctx context.Context, rawURL string) error {
+	h, err := p.host(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	delay := p.Delay
+	if d := h.robots.CrawlDelay(p.UserAgent); d > delay {
+		delay = d
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+	h.mx.Lock()
+	now := time.Now()
+	wait := h.next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	h.next = now.Add(wait + delay)
+	h.mx.Unlock()
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,215 @@
+// Package crawl contains building blocks for crawlers: robots.txt compliance, politeness delays,
+// a persistent frontier and the crawler itself
+package crawl
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robots.txt bigger than this is truncated (as Google does)
+const robotsMaxSize = 500 * 1024
+
+// Robots parsed robots.txt
+type Robots struct {
+	Sitemaps []string
+	groups   []robotsGroup
+}
+
+type robotsGroup struct {
+	agents []string // lower case product tokens
+	rules  []robotsRule
+	delay  time.Duration
+}
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+// allowAll is used when robots.txt is missing, disallowAll when it's unavailable because of server errors
+var (
+	allowAll    = &Robots{}
+	disallowAll = &Robots{groups: []robotsGroup{{agents: []string{"*"}, rules: []robotsRule{{allow: false, path: "/"}}}}}
+)
+
+// ParseRobots parses robots.txt, unknown and malformed lines are ignored
+func ParseRobots(r io.Reader) (*Robots, error) {
+	var (
+		robots  = &Robots{}
+		current *robotsGroup
+		rules   = false // the current group already has rules, the next user-agent starts a new group
+		scanner = bufio.NewScanner(io.LimitReader(r, robotsMaxSize))
+	)
+	scanner.Buffer(make([]byte, 64*1024), robotsMaxSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if n := strings.IndexByte(line, '#'); n != -1 {
+			line = line[:n]
+		}
+		n := strings.IndexByte(line, ':')
+		if n == -1 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(line[:n])), strings.TrimSpace(line[n+1:])
+		switch key {
+		case "user-agent":
+			if current == nil || rules {
+				robots.groups = append(robots.groups, robotsGroup{})
+				current = &robots.groups[len(robots.groups)-1]
+				rules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			rules = true
+			if value == "" {
+				continue // empty disallow allows everything
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", path: value})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			rules = true
+			if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 {
+				current.delay = time.Duration(f * float64(time.Second))
+			}
+		case "sitemap":
+			robots.Sitemaps = append(robots.Sitemaps, value)
+		}
+	}
+	return robots, scanner.Err()
+}
+
+// FetchRobots downloads robots.txt of the URL's origin. Missing robots.txt (4xx) allows everything,
+// server errors (5xx) disallow everything as recommended by RFC 9309
+func FetchRobots(ctx context.Context, client *http.Client, userAgent, rawURL string) (*Robots, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.Scheme+"://"+u.Host+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode >= 500:
+		return disallowAll, nil
+	case res.StatusCode >= 400:
+		return allowAll, nil
+	case res.StatusCode >= 300:
+		// redirects are followed by the client, more than it allows is treated as missing
+		return allowAll, nil
+	}
+	return ParseRobots(res.Body)
+}
+
+// group returns the most specific group for the user agent ("*" if nothing else matches)
+func (r *Robots) group(userAgent string) *robotsGroup {
+	token := strings.ToLower(userAgent)
+	if n := strings.IndexAny(token, "/ "); n != -1 {
+		token = token[:n] // product token of "Mybot/1.0 (+https://...)"
+	}
+	var (
+		best    *robotsGroup
+		bestLen = -1
+	)
+	for n := range r.groups {
+		g := &r.groups[n]
+		for _, agent := range g.agents {
+			switch {
+			case agent == "*" && bestLen < 0:
+				best, bestLen = g, 0
+			case agent != "*" && token != "" && strings.HasPrefix(token, agent) && len(agent) > bestLen:
+				best, bestLen = g, len(agent)
+			}
+		}
+	}
+	return best
+}
+
+// Allowed reports whether the user agent may fetch the URL. The longest matching rule wins, Allow wins ties
+func (r *Robots) Allowed(userAgent, rawURL string) bool {
+	g := r.group(userAgent)
+	if g == nil {
+		return true
+	}
+	path := "/"
+	if u, err := url.Parse(rawURL); err == nil {
+		if path = u.EscapedPath(); path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+	}
+	var (
+		allowed = true
+		longest = -1
+	)
+	for _, rule := range g.rules {
+		if !robotsMatch(rule.path, path) {
+			continue
+		}
+		if l := len(rule.path); l > longest || l == longest && rule.allow {
+			allowed, longest = rule.allow, l
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay requested by the site for the user agent, zero if not set
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	if g := r.group(userAgent); g != nil {
+		return g.delay
+	}
+	return 0
+}
+
+// robotsMatch matches path prefix pattern where '*' is any sequence and '$' anchors the end
+func robotsMatch(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "$") {
+		return robotsMatchFull(pattern[:len(pattern)-1], path, true)
+	}
+	return robotsMatchFull(pattern, path, false)
+}
+
+func robotsMatchFull(pattern, path string, anchored bool) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for n, part := range parts[1:] {
+		last := n == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		i := strings.Index(path[pos:], part)
+		if i == -1 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	return !anchored || pos == len(path)
+}
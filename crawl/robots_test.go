@@ -0,0 +1,122 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const robotsTxt = `# comment
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 1.5
+
+User-agent: mybot
+User-agent: otherbot
+Disallow: /
+Allow: /$
+Allow: /open/
+
+User-agent: mybot-images
+Disallow:
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestRobotsAllowed(t *testing.T) {
+	robots, err := ParseRobots(strings.NewReader(robotsTxt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		agent, url string
+		want       bool
+	}{
+		{"Googlebot", "https://example.com/", true},
+		{"Googlebot", "https://example.com/private/", false},
+		{"Googlebot", "https://example.com/private/x.html", false},
+		{"Googlebot", "https://example.com/private/public.html", true},
+		{"Googlebot", "https://example.com/docs/a.pdf", false},
+		{"Googlebot", "https://example.com/docs/a.pdf?x=1", true},
+		{"Googlebot", "https://example.com/search?q=1", false},
+		{"Googlebot", "https://example.com/search", true},
+		{"MyBot/1.0 (+https://example.com/bot)", "https://example.com/", true},
+		{"MyBot/1.0", "https://example.com/page", false},
+		{"mybot", "https://example.com/open/page", true},
+		{"otherbot", "https://example.com/page", false},
+		{"mybot-images", "https://example.com/page", true}, // the longest agent wins, empty disallow allows everything
+		{"", "https://example.com/private/", false},
+	} {
+		if got := robots.Allowed(c.agent, c.url); got != c.want {
+			t.Errorf("%s %s: got %v, want %v", c.agent, c.url, got, c.want)
+		}
+	}
+	if d := robots.CrawlDelay("Googlebot"); d != 1500*time.Millisecond {
+		t.Errorf("crawl delay %v", d)
+	}
+	if d := robots.CrawlDelay("mybot"); d != 0 {
+		t.Errorf("crawl delay of mybot %v", d)
+	}
+	if len(robots.Sitemaps) != 1 || robots.Sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("sitemaps %q", robots.Sitemaps)
+	}
+}
+
+func TestRobotsMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish/", "/fish", false},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/a*b*c$", "/abxc", true},
+		{"/a*b*c$", "/abcx", false},
+		{"/$", "/", true},
+		{"/$", "/a", false},
+	} {
+		if got := robotsMatch(c.pattern, c.path); got != c.want {
+			t.Errorf("%s %s: got %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
+
+func TestFetchRobots(t *testing.T) {
+	for _, c := range []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, true},
+		{http.StatusServiceUnavailable, false},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/robots.txt" {
+				http.NotFound(w, r)
+				return
+			}
+			w.WriteHeader(c.status)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		}))
+		robots, err := FetchRobots(context.Background(), srv.Client(), "mybot", srv.URL+"/page")
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := robots.Allowed("mybot", srv.URL+"/page"); got != c.want {
+			t.Errorf("status %d: got %v, want %v", c.status, got, c.want)
+		}
+	}
+}
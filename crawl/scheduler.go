@@ -0,0 +1,298 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+// Job page to visit by Scheduler
+type Job struct {
+	URL     string
+	Extract func(page *control.Frame, job Job) error // nil - Scheduler.Extract
+	Attempt int                                      // zero based, set by Scheduler
+	Data    interface{}                              // user data passed through to Result
+}
+
+// Result of a job, reported once per job after the last attempt
+type Result struct {
+	Job      Job
+	Err      error
+	Duration time.Duration // of the last attempt
+}
+
+// HostLimits limits requests to a single host
+type HostLimits struct {
+	Concurrency int     // pages of the host open at the same time, zero - 1
+	QPS         float64 // navigations per second, zero - unlimited
+}
+
+// Scheduler visits jobs of many hosts with a shared pool of tabs respecting per-host limits.
+// A job waiting for its host doesn't occupy a tab, so slow hosts don't starve the rest
+type Scheduler struct {
+	Browser    control.BrowserContext
+	Sessions   int                   // size of the tab pool, zero - 4
+	PerHost    HostLimits            // default limits of a host
+	Hosts      map[string]HostLimits // limits by hostname overriding PerHost
+	Retries    int                   // attempts after the first one for navigation errors and timeouts
+	Backoff    time.Duration         // delay before the first retry doubled on each next one, zero - 1 second
+	Timeout    time.Duration         // navigation timeout, zero - 30 seconds
+	Politeness *Politeness           // nil - robots.txt is not consulted
+	Extract    func(page *control.Frame, job Job) error
+	OnResult   func(Result) // called from workers concurrently
+
+	mx       sync.Mutex
+	pending  []Job
+	hosts    map[string]*hostState
+	inflight int // jobs dispatched or waiting for a retry
+	closed   bool
+	wake     chan struct{}
+}
+
+type hostState struct {
+	active int
+	next   time.Time
+}
+
+// Run visits jobs until the channel is closed and all of them are done, or until ctx is done
+func (s *Scheduler) Run(ctx context.Context, jobs <-chan Job) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	size := s.Sessions
+	if size == 0 {
+		size = 4
+	}
+	s.mx.Lock()
+	s.pending, s.hosts, s.inflight, s.closed = nil, map[string]*hostState{}, 0, false
+	s.wake = make(chan struct{}, 1)
+	s.mx.Unlock()
+
+	sessions := make([]*control.Session, 0, size)
+	defer func() {
+		for _, session := range sessions {
+			_ = session.Close()
+		}
+	}()
+	for n := 0; n < size; n++ {
+		session, err := s.Browser.CreatePageTarget("")
+		if err != nil {
+			return err
+		}
+		sessions = append(sessions, session)
+	}
+
+	go func() {
+		for {
+			select {
+			case job, ok := <-jobs:
+				s.mx.Lock()
+				if ok {
+					s.pending = append(s.pending, job)
+				} else {
+					s.closed = true
+				}
+				s.mx.Unlock()
+				s.signal()
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	work := make(chan Job)
+	var wg sync.WaitGroup
+	for _, session := range sessions {
+		wg.Add(1)
+		go func(session *control.Session) {
+			defer wg.Done()
+			for job := range work {
+				s.run(ctx, session, job)
+			}
+		}(session)
+	}
+	defer wg.Wait()
+	defer close(work)
+
+	for {
+		job, wait, done := s.dispatch()
+		if done {
+			return nil
+		}
+		if job != nil {
+			select {
+			case work <- *job:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+		if err := s.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep until something changes or the QPS limit of a host expires, zero wait - no host is limited by QPS
+func (s *Scheduler) sleep(ctx context.Context, wait time.Duration) error {
+	var expired <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-s.wake:
+	case <-expired:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) limits(host string) HostLimits {
+	l, ok := s.Hosts[host]
+	if !ok {
+		l = s.PerHost
+	}
+	if l.Concurrency <= 0 {
+		l.Concurrency = 1
+	}
+	return l
+}
+
+// dispatch takes the first pending job whose host has a free slot, otherwise tells how long to wait for the QPS limit
+func (s *Scheduler) dispatch() (job *Job, wait time.Duration, done bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.closed && len(s.pending) == 0 && s.inflight == 0 {
+		return nil, 0, true
+	}
+	now := time.Now()
+	for n, j := range s.pending {
+		host := hostOf(j.URL)
+		h := s.hosts[host]
+		if h == nil {
+			h = &hostState{}
+			s.hosts[host] = h
+		}
+		limits := s.limits(host)
+		if h.active >= limits.Concurrency {
+			continue
+		}
+		if h.next.After(now) {
+			if d := h.next.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		h.active++
+		if limits.QPS > 0 {
+			h.next = now.Add(time.Duration(float64(time.Second) / limits.QPS))
+		}
+		s.pending = append(s.pending[:n], s.pending[n+1:]...)
+		s.inflight++
+		return &j, 0, false
+	}
+	return nil, wait, false
+}
+
+func (s *Scheduler) run(ctx context.Context, session *control.Session, job Job) {
+	start := time.Now()
+	err := s.visit(ctx, session, job)
+	duration := time.Since(start)
+	host := hostOf(job.URL)
+
+	s.mx.Lock()
+	s.hosts[host].active--
+	s.mx.Unlock()
+	s.signal()
+
+	if err != nil && job.Attempt < s.Retries && retryable(err) && ctx.Err() == nil {
+		backoff := s.Backoff
+		if backoff == 0 {
+			backoff = time.Second
+		}
+		backoff <<= uint(job.Attempt)
+		job.Attempt++
+		time.AfterFunc(backoff, func() {
+			s.mx.Lock()
+			s.pending = append(s.pending, job)
+			s.inflight--
+			s.mx.Unlock()
+			s.signal()
+		})
+		return
+	}
+	if s.OnResult != nil {
+		s.OnResult(Result{Job: job, Err: err, Duration: duration})
+	}
+	s.mx.Lock()
+	s.inflight--
+	s.mx.Unlock()
+	s.signal()
+}
+
+func (s *Scheduler) visit(ctx context.Context, session *control.Session, job Job) error {
+	if s.Politeness != nil {
+		allowed, err := s.Politeness.Allowed(ctx, job.URL)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrDisallowed
+		}
+		if err = s.Politeness.Wait(ctx, job.URL); err != nil {
+			return err
+		}
+	}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+	page := session.Page()
+	if err := page.Navigate(job.URL, control.LifecycleLoad, timeout); err != nil && err != control.ErrAlreadyNavigated {
+		return err
+	}
+	extract := job.Extract
+	if extract == nil {
+		extract = s.Extract
+	}
+	if extract == nil {
+		return nil
+	}
+	return extract(page, job)
+}
+
+// retryable navigation errors and timeouts, extraction errors are not retried
+func retryable(err error) bool {
+	var (
+		navigation control.NavigationError
+		timeout    control.FutureTimeoutError
+	)
+	if errors.As(err, &timeout) {
+		return true
+	}
+	return errors.As(err, &navigation) && !errors.Is(err, control.ErrBlockedByClient) && !errors.Is(err, control.ErrAborted)
+}
+
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return strings.ToLower(u.Hostname())
+	}
+	return ""
+}
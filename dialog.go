@@ -0,0 +1,58 @@
+package control
+
+import (
+	"encoding/json"
+
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+const (
+	DialogAlert        page.DialogType = "alert"
+	DialogConfirm      page.DialogType = "confirm"
+	DialogPrompt       page.DialogType = "prompt"
+	DialogBeforeUnload page.DialogType = "beforeunload"
+)
+
+// Dialog JavaScript dialog opened by the page
+type Dialog struct {
+	Type          page.DialogType
+	Message       string
+	URL           string
+	DefaultPrompt string
+}
+
+// DialogPolicy decides how to close a dialog, promptText is used by prompt dialogs only
+type DialogPolicy func(Dialog) (accept bool, promptText string)
+
+// DialogAccept accepts every dialog, prompts get their default value
+func DialogAccept(d Dialog) (bool, string) {
+	return true, d.DefaultPrompt
+}
+
+// DialogDismiss dismisses every dialog (cancel for confirm and prompt, stay on the page for beforeunload)
+func DialogDismiss(Dialog) (bool, string) {
+	return false, ""
+}
+
+// SetDialogPolicy closes JavaScript dialogs (alert, confirm, prompt, beforeunload) according to the policy,
+// so an unexpected dialog doesn't block navigation and input. nil policy leaves dialogs open
+func (s Session) SetDialogPolicy(policy DialogPolicy) {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.dialogCancel != nil {
+		s.config.dialogCancel()
+		s.config.dialogCancel = nil
+	}
+	if policy == nil {
+		return
+	}
+	s.config.dialogCancel = s.Subscribe("Page.javascriptDialogOpening", func(e transport.Event) error {
+		var v = page.JavascriptDialogOpening{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		accept, promptText := policy(Dialog{Type: v.Type, Message: v.Message, URL: v.Url, DefaultPrompt: v.DefaultPrompt})
+		return s.HandleJavaScriptDialog(accept, promptText)
+	})
+}
@@ -0,0 +1,209 @@
+package control
+
+import (
+	"strings"
+)
+
+// DiffOp kind of DiffLine
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffInsert
+	DiffDelete
+)
+
+// DiffLine line of Diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// Diff line by line difference, see Snapshot.Diff
+type Diff []DiffLine
+
+// Changed true if any line is inserted or deleted
+func (d Diff) Changed() bool {
+	for _, l := range d {
+		if l.Op != DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// Inserted lines
+func (d Diff) Inserted() []string {
+	return d.lines(DiffInsert)
+}
+
+// Deleted lines
+func (d Diff) Deleted() []string {
+	return d.lines(DiffDelete)
+}
+
+func (d Diff) lines(op DiffOp) []string {
+	var list []string
+	for _, l := range d {
+		if l.Op == op {
+			list = append(list, l.Text)
+		}
+	}
+	return list
+}
+
+// String changed lines prefixed with "+" and "-" and up to 2 lines of context prefixed with " ",
+// skipped equal lines are marked with "..."
+func (d Diff) String() string {
+	const context = 2
+	var (
+		b    strings.Builder
+		keep = make([]bool, len(d))
+	)
+	for n, l := range d {
+		if l.Op == DiffEqual {
+			continue
+		}
+		for k := n - context; k <= n+context; k++ {
+			if k >= 0 && k < len(d) {
+				keep[k] = true
+			}
+		}
+	}
+	skipped := false
+	for n, l := range d {
+		if !keep[n] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			b.WriteString("...\n")
+			skipped = false
+		}
+		switch l.Op {
+		case DiffInsert:
+			b.WriteString("+ ")
+		case DiffDelete:
+			b.WriteString("- ")
+		default:
+			b.WriteString("  ")
+		}
+		b.WriteString(l.Text)
+		b.WriteByte('\n')
+	}
+	if skipped && b.Len() > 0 {
+		b.WriteString("...\n")
+	}
+	return b.String()
+}
+
+// diffLines Myers' shortest edit script of a into b in linear space: common prefix and suffix are trimmed,
+// the rest is split at the middle snake and both halves are diffed recursively
+func diffLines(a, b []string) Diff {
+	return appendDiff(nil, a, b)
+}
+
+func appendDiff(diff Diff, a, b []string) Diff {
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: a[p]})
+		p++
+	}
+	a, b = a[p:], b[p:]
+	s := 0
+	for s < len(a) && s < len(b) && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	suffix := a[len(a)-s:]
+	a, b = a[:len(a)-s], b[:len(b)-s]
+	x, y, ok := middleSnake(a, b)
+	if ok && (x > 0 || y > 0) && (x < len(a) || y < len(b)) {
+		diff = appendDiff(diff, a[:x], b[:y])
+		diff = appendDiff(diff, a[x:], b[y:])
+	} else {
+		for _, l := range a {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: l})
+		}
+		for _, l := range b {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: l})
+		}
+	}
+	for _, l := range suffix {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: l})
+	}
+	return diff
+}
+
+// middleSnake runs forward and reverse searches until their paths overlap and returns the point to split the edit script at,
+// false if a or b is empty. a and b must differ in the first and the last lines
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	var (
+		maxD   = (n + m + 1) / 2
+		offset = maxD + 1
+		size   = 2*maxD + 3
+		vf     = make([]int, size) // furthest x on diagonal k of forward paths
+		vr     = make([]int, size) // furthest x on diagonal k of reverse paths, counted from the ends
+		delta  = n - m
+		odd    = delta%2 != 0
+	)
+	for i := range vf {
+		vf[i], vr[i] = -1, -1
+	}
+	vf[offset+1], vr[offset+1] = 0, 0
+	// diagonals which ran off the edit graph are skipped
+	var fStart, fEnd, rStart, rEnd int
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			vf[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if i := offset + delta - k; i >= 0 && i < size && vr[i] != -1 && x >= n-vr[i] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + rStart; k <= d-rEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vr[offset+k-1] < vr[offset+k+1]) {
+				x = vr[offset+k+1]
+			} else {
+				x = vr[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x, y = x+1, y+1
+			}
+			vr[offset+k] = x
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < size && vf[i] != -1 && vf[i] >= n-x {
+					fx := vf[i]
+					return fx, fx - (delta - k), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,90 @@
+package control
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func chars(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "")
+}
+
+func TestDiffLines(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want string // ops of the diff: = equal, + insert, - delete
+	}{
+		{"", "", ""},
+		{"abc", "abc", "==="},
+		{"", "abc", "+++"},
+		{"abc", "", "---"},
+		{"abc", "abxc", "==+="},
+		{"abxc", "abc", "==-="},
+		{"abc", "xyz", "---+++"},
+		{"abcabba", "cbabac", "-+=-==-=+"},
+	} {
+		var ops strings.Builder
+		for _, l := range diffLines(chars(c.a), chars(c.b)) {
+			ops.WriteByte("=+-"[l.Op])
+		}
+		if ops.String() != c.want {
+			t.Errorf("%q -> %q: got %s, want %s", c.a, c.b, ops.String(), c.want)
+		}
+	}
+}
+
+// lcs length of the longest common subsequence by dynamic programming
+func lcs(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLinesRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := func() []string {
+		list := make([]string, rnd.Intn(30))
+		for n := range list {
+			list[n] = string(rune('a' + rnd.Intn(4)))
+		}
+		return list
+	}
+	for n := 0; n < 20000; n++ {
+		a, b := random(), random()
+		var from, to []string
+		equal := 0
+		for _, l := range diffLines(a, b) {
+			switch l.Op {
+			case DiffEqual:
+				from, to = append(from, l.Text), append(to, l.Text)
+				equal++
+			case DiffDelete:
+				from = append(from, l.Text)
+			case DiffInsert:
+				to = append(to, l.Text)
+			}
+		}
+		if !equalLines(from, a) || !equalLines(to, b) {
+			t.Fatalf("%q -> %q: the diff doesn't reproduce the input", a, b)
+		}
+		if want := lcs(a, b); equal != want {
+			t.Fatalf("%q -> %q: %d equal lines, the shortest edit script has %d", a, b, equal, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package control
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ecwid/control/protocol"
+	"github.com/ecwid/control/protocol/css"
+	"github.com/ecwid/control/protocol/dom"
+	"github.com/ecwid/control/protocol/domstorage"
+	"github.com/ecwid/control/protocol/log"
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/protocol/overlay"
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/protocol/performance"
+	"github.com/ecwid/control/protocol/runtime"
+	"github.com/ecwid/control/protocol/serviceworker"
+)
+
+type domainSwitch struct {
+	enable  func(protocol.Caller) error
+	disable func(protocol.Caller) error
+}
+
+var domainSwitches = map[string]domainSwitch{
+	"Page":    {enable: page.Enable, disable: page.Disable},
+	"Runtime": {enable: runtime.Enable, disable: runtime.Disable},
+	"Network": {
+		enable: func(c protocol.Caller) error {
+			// maxPostDataSize - The Longest post body size (in bytes) that would be included in requestWillBeSent notification
+			return network.Enable(c, network.EnableArgs{MaxPostDataSize: 20 * 1024})
+		},
+		disable: network.Disable,
+	},
+	"DOM": {
+		enable:  func(c protocol.Caller) error { return dom.Enable(c, dom.EnableArgs{}) },
+		disable: dom.Disable,
+	},
+	"CSS":        {enable: css.Enable, disable: css.Disable},
+	"DOMStorage": {enable: domstorage.Enable, disable: domstorage.Disable},
+	"Log":        {enable: log.Enable, disable: log.Disable},
+	"Overlay":    {enable: overlay.Enable, disable: overlay.Disable},
+	"Performance": {
+		enable:  func(c protocol.Caller) error { return performance.Enable(c, performance.EnableArgs{}) },
+		disable: performance.Disable,
+	},
+	"ServiceWorker": {enable: serviceworker.Enable, disable: serviceworker.Disable},
+}
+
+// domains counts references to enabled CDP domains of the session
+type domains struct {
+	mx      sync.Mutex // serializes enabling and disabling, held while waiting for the response
+	stateMx sync.Mutex // guards counts and held, never held while calling the browser
+	counts  map[string]int
+	held    map[string]bool
+}
+
+func (d *domains) count(name string) int {
+	d.stateMx.Lock()
+	defer d.stateMx.Unlock()
+	return d.counts[name]
+}
+
+// enabled names of domains with references
+func (d *domains) enabled() []string {
+	d.stateMx.Lock()
+	defer d.stateMx.Unlock()
+	var names []string
+	for name, count := range d.counts {
+		if count > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EnableDomain enables CDP domain (Network, DOM, Log, Performance...) if it isn't enabled yet.
+// The domain is disabled when the last reference is released, so the session receives events it really needs only
+func (s Session) EnableDomain(name string) (release func(), err error) {
+	sw, ok := domainSwitches[name]
+	if !ok {
+		return nil, fmt.Errorf("domain `%s` is not supported", name)
+	}
+	s.domains.mx.Lock()
+	defer s.domains.mx.Unlock()
+	if s.domains.count(name) == 0 {
+		if err = sw.enable(s); err != nil {
+			return nil, err
+		}
+	}
+	s.domains.stateMx.Lock()
+	s.domains.counts[name]++
+	s.domains.stateMx.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.domains.mx.Lock()
+			defer s.domains.mx.Unlock()
+			s.domains.stateMx.Lock()
+			s.domains.counts[name]--
+			last := s.domains.counts[name] == 0
+			s.domains.stateMx.Unlock()
+			if last {
+				_ = sw.disable(s)
+			}
+		})
+	}, nil
+}
+
+// holdDomain enables domain until the session is closed
+func (s Session) holdDomain(name string) error {
+	s.domains.stateMx.Lock()
+	held := s.domains.held[name]
+	s.domains.stateMx.Unlock()
+	if held {
+		return nil
+	}
+	if _, err := s.EnableDomain(name); err != nil {
+		return err
+	}
+	s.domains.stateMx.Lock()
+	s.domains.held[name] = true
+	s.domains.stateMx.Unlock()
+	return nil
+}
@@ -0,0 +1,229 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/browser"
+	"github.com/ecwid/control/transport"
+)
+
+var (
+	ErrDownloadsDisabled = errors.New("downloads are not enabled, call SetDownloadDir first")
+	ErrDownloadCanceled  = errors.New("download was canceled")
+)
+
+const (
+	DownloadInProgress = "inProgress"
+	DownloadCompleted  = "completed"
+	DownloadCanceled   = "canceled"
+)
+
+// Download file downloaded by the page
+type Download struct {
+	GUID              string
+	URL               string
+	SuggestedFilename string
+	Path              string // where the file is saved, known when the download is completed
+	TotalBytes        float64
+	ReceivedBytes     float64
+	State             string // DownloadInProgress, DownloadCompleted or DownloadCanceled
+	Started           time.Time
+}
+
+type downloads struct {
+	mx       sync.Mutex
+	dir      string
+	items    map[string]*Download
+	order    []string
+	consumed map[string]bool // returned by WaitForDownload already
+	changed  chan struct{}   // closed and replaced on every change
+	progress func(Download)
+	cancel   func()
+}
+
+// SetDownloadDir saves downloads of the session's browser context into dir and tracks them.
+// Files are saved under their suggested names (with the download GUID if the name is taken).
+// An empty dir restores the default behavior
+func (s Session) SetDownloadDir(dir string) error {
+	contextID, err := s.browserContextID()
+	if err != nil {
+		return err
+	}
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.downloads != nil {
+		s.config.downloads.cancel()
+		s.config.downloads = nil
+	}
+	if dir == "" {
+		return browser.SetDownloadBehavior(s, browser.SetDownloadBehaviorArgs{Behavior: "default", BrowserContextId: contextID})
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	d := &downloads{
+		dir:      dir,
+		items:    map[string]*Download{},
+		consumed: map[string]bool{},
+		changed:  make(chan struct{}),
+	}
+	d.cancel = s.Subscribe("*", d.update)
+	err = browser.SetDownloadBehavior(s, browser.SetDownloadBehaviorArgs{
+		Behavior:         "allowAndName", // files are named by GUID, so they can be found reliably
+		BrowserContextId: contextID,
+		DownloadPath:     dir,
+		EventsEnabled:    true,
+	})
+	if err != nil {
+		d.cancel()
+		return err
+	}
+	s.config.downloads = d
+	return nil
+}
+
+func (s Session) downloads() *downloads {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	return s.config.downloads
+}
+
+// OnDownloadProgress calls handler on every downloadWillBegin and downloadProgress event
+func (s Session) OnDownloadProgress(handler func(Download)) error {
+	d := s.downloads()
+	if d == nil {
+		return ErrDownloadsDisabled
+	}
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.progress = handler
+	return nil
+}
+
+// Downloads started since SetDownloadDir in order of start
+func (s Session) Downloads() []Download {
+	d := s.downloads()
+	if d == nil {
+		return nil
+	}
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	list := make([]Download, len(d.order))
+	for n, guid := range d.order {
+		list[n] = *d.items[guid]
+	}
+	return list
+}
+
+// WaitForDownload waits for a completed download whose suggested filename matches the pattern
+// ('*' -> zero or more, '?' -> exactly one, empty - any) and returns the path of the file.
+// A download completed before the call counts too, but every download is returned once
+func (s Session) WaitForDownload(pattern string, timeout time.Duration) (string, error) {
+	d := s.downloads()
+	if d == nil {
+		return "", ErrDownloadsDisabled
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		d.mx.Lock()
+		for _, guid := range d.order {
+			item := d.items[guid]
+			if d.consumed[guid] || !wildcard.Match(pattern, item.SuggestedFilename) {
+				continue
+			}
+			switch item.State {
+			case DownloadCompleted:
+				d.consumed[guid] = true
+				d.mx.Unlock()
+				return item.Path, nil
+			case DownloadCanceled:
+				d.consumed[guid] = true
+				d.mx.Unlock()
+				return "", ErrDownloadCanceled
+			}
+		}
+		changed := d.changed
+		d.mx.Unlock()
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return "", FutureTimeoutError{timeout: timeout}
+		case <-s.context.Done():
+			return "", ErrTargetDestroyed
+		}
+	}
+}
+
+func (d *downloads) update(e transport.Event) error {
+	switch e.Method {
+	case "Browser.downloadWillBegin":
+		var v = browser.DownloadWillBegin{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		d.mx.Lock()
+		item := &Download{GUID: v.Guid, URL: v.Url, SuggestedFilename: v.SuggestedFilename, State: DownloadInProgress, Started: time.Now()}
+		if _, ok := d.items[v.Guid]; !ok {
+			d.order = append(d.order, v.Guid)
+		}
+		d.items[v.Guid] = item
+		d.notify(*item)
+
+	case "Browser.downloadProgress":
+		var v = browser.DownloadProgress{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		d.mx.Lock()
+		item, ok := d.items[v.Guid]
+		if !ok {
+			d.mx.Unlock()
+			return nil
+		}
+		item.TotalBytes, item.ReceivedBytes = v.TotalBytes, v.ReceivedBytes
+		if v.State == DownloadCompleted {
+			item.Path = d.rename(item)
+		}
+		item.State = v.State
+		d.notify(*item)
+	}
+	return nil
+}
+
+// notify wakes up waiters and calls progress handler, d.mx must be held and is released
+func (d *downloads) notify(item Download) {
+	close(d.changed)
+	d.changed = make(chan struct{})
+	progress := d.progress
+	d.mx.Unlock()
+	if progress != nil {
+		progress(item)
+	}
+}
+
+// rename gives the file (saved as GUID) its suggested name if it's free
+func (d *downloads) rename(item *Download) string {
+	saved := filepath.Join(d.dir, item.GUID)
+	name := filepath.Base(item.SuggestedFilename)
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		return saved
+	}
+	target := filepath.Join(d.dir, name)
+	if _, err := os.Stat(target); err == nil {
+		return saved
+	}
+	if err := os.Rename(saved, target); err != nil {
+		return saved
+	}
+	return target
+}
@@ -88,6 +88,11 @@ func (e Element) Clear() error {
 }
 
 func (e Element) InsertText(text string) error {
+	e.frame.session.throttle()
+	return e.insertText(text)
+}
+
+func (e Element) insertText(text string) error {
 	var err error
 	if err = e.ScrollIntoView(); err != nil {
 		return err
@@ -115,6 +120,7 @@ func (e Element) InsertText(text string) error {
 // Type ...
 func (e *Element) Type(text string, delay time.Duration) error {
 	var err error
+	e.frame.session.throttle()
 	if err = e.ScrollIntoView(); err != nil {
 		return err
 	}
@@ -130,11 +136,11 @@ func (e *Element) Type(text string, delay time.Duration) error {
 				return err
 			}
 		} else {
-			if err = e.InsertText(string(c)); err != nil {
+			if err = e.insertText(string(c)); err != nil {
 				return err
 			}
 		}
-		time.Sleep(delay)
+		time.Sleep(e.frame.Session().Input.keyDelay(delay))
 	}
 	if text == "" {
 		return e.dispatchEvents(
@@ -198,6 +204,7 @@ func (e Element) Click() error {
 }
 
 func (e Element) ClickWith(button input.MouseButton, delayToRelease time.Duration) error {
+	e.frame.session.throttle()
 	if err := e.ScrollIntoView(); err != nil {
 		return err
 	}
@@ -205,7 +212,6 @@ func (e Element) ClickWith(button input.MouseButton, delayToRelease time.Duratio
 		return err
 	}
 	var clickValue = make(chan string, 1)
-	defer close(clickValue)
 	cancel := e.frame.session.onBindingCalled(bindClick, func(p string) {
 		select {
 		case clickValue <- p:
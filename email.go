@@ -0,0 +1,24 @@
+package control
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecwid/control/mail"
+)
+
+// FollowEmailLink waits for a message to the address newer than since, navigates the main frame to its first link
+// matching the wildcard pattern (e.g. "*/confirm?token=*") and returns the link. The timeout covers both
+func (s Session) FollowEmailLink(box mail.Mailbox, address, pattern string, since time.Time, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(s.context, timeout)
+	defer cancel()
+	m, err := mail.Wait(ctx, box, address, since)
+	if err != nil {
+		return "", err
+	}
+	link, err := m.Link(pattern)
+	if err != nil {
+		return "", err
+	}
+	return link, s.Page().NavigateContext(ctx, link, LifecycleLoad)
+}
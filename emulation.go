@@ -1,6 +1,8 @@
 package control
 
 import (
+	"fmt"
+
 	"github.com/ecwid/control/mobile"
 	"github.com/ecwid/control/protocol/common"
 	"github.com/ecwid/control/protocol/emulation"
@@ -57,5 +59,77 @@ func (e Emulation) Emulate(device *mobile.Device) error {
 	if err := e.SetDeviceMetricsOverride(device.Metrics); err != nil {
 		return err
 	}
+	if err := e.setTouch(device.Metrics.Mobile); err != nil {
+		return err
+	}
 	return e.SetUserAgentOverride(device.UserAgent, "", "", nil)
 }
+
+// EmulateDevice emulate device of mobile.Devices catalog by name, e.g. "iPhone X"
+func (e Emulation) EmulateDevice(name string) error {
+	device, ok := mobile.Devices[name]
+	if !ok {
+		return fmt.Errorf("unknown device `%s`", name)
+	}
+	return e.Emulate(device)
+}
+
+// SetViewport sets the size of the viewport in CSS pixels and device scale factor (zero - the default one).
+// Mobile emulates meta viewport, overlay scrollbars and touch events
+func (e Emulation) SetViewport(width, height int, scale float64, mobile bool) error {
+	err := e.SetDeviceMetricsOverride(emulation.SetDeviceMetricsOverrideArgs{
+		Width:             width,
+		Height:            height,
+		DeviceScaleFactor: scale,
+		Mobile:            mobile,
+	})
+	if err != nil {
+		return err
+	}
+	return e.setTouch(mobile)
+}
+
+func (e Emulation) setTouch(enabled bool) error {
+	args := emulation.SetTouchEmulationEnabledArgs{Enabled: enabled}
+	if enabled {
+		args.MaxTouchPoints = 5
+	}
+	return emulation.SetTouchEmulationEnabled(e.s, args)
+}
+
+// SetUserAgent overrides User-Agent header and navigator.userAgent
+func (e Emulation) SetUserAgent(userAgent string) error {
+	return e.SetUserAgentOverride(userAgent, "", "", nil)
+}
+
+// zero coordinates are valid, so they can't be omitted as the generated args do
+type setGeolocationOverrideArgs struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+// SetGeolocation overrides the position reported by navigator.geolocation, accuracy in meters.
+// The page also needs PermissionGeolocation, see GrantPermissions
+func (e Emulation) SetGeolocation(latitude, longitude, accuracy float64) error {
+	return e.s.Call("Emulation.setGeolocationOverride", setGeolocationOverrideArgs{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Accuracy:  accuracy,
+	}, nil)
+}
+
+// ClearGeolocation removes the override, the real position is reported again
+func (e Emulation) ClearGeolocation() error {
+	return emulation.ClearGeolocationOverride(e.s)
+}
+
+// SetTimezone overrides the timezone by ICU id (e.g. "Europe/Berlin"), empty id restores the default one
+func (e Emulation) SetTimezone(id string) error {
+	return emulation.SetTimezoneOverride(e.s, emulation.SetTimezoneOverrideArgs{TimezoneId: id})
+}
+
+// SetLocale overrides ICU locale (e.g. "de-DE") affecting Intl and number/date formatting, empty locale restores the default one
+func (e Emulation) SetLocale(locale string) error {
+	return emulation.SetLocaleOverride(e.s, emulation.SetLocaleOverrideArgs{Locale: locale})
+}
@@ -17,6 +17,11 @@ var (
 	ErrDetachedFromTarget        = errors.New("detached from target")
 	ErrClickTimeout              = errors.New("no click registered")
 	ErrExecutionContextDestroyed = errors.New("execution context was destroyed")
+	ErrNotCanvas                 = errors.New("element is not a canvas")
+	ErrNotFileInput              = errors.New("element is not an input[type=file]")
+	ErrSingleFileInput           = errors.New("input accepts a single file, it has no multiple attribute")
+	ErrInterval                  = errors.New("interval must be positive")
+	ErrNotLocalProcess           = errors.New("the process doesn't belong to a browser launched by this process")
 )
 
 type ErrTargetCrashed target.TargetCrashed
@@ -66,3 +71,51 @@ type ClickTargetOverlappedError struct {
 func (e ClickTargetOverlappedError) Error() string {
 	return fmt.Sprintf("click at target is overlapped by `%s`", e.outerHTML)
 }
+
+// SessionError error occurred in the session's goroutine (e.g. in a subscriber)
+type SessionError struct {
+	SessionID string
+	Err       error
+}
+
+func (e SessionError) Error() string {
+	return fmt.Sprintf("session %s: %v", e.SessionID, e.Err)
+}
+
+func (e SessionError) Unwrap() error {
+	return e.Err
+}
+
+type InternalPanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e InternalPanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// SecretError wraps an error which happened while handling a secret value, the value is masked in the message
+type SecretError struct {
+	Err     error
+	message string
+}
+
+func (e SecretError) Error() string {
+	return e.message
+}
+
+func (e SecretError) Unwrap() error {
+	return e.Err
+}
+
+// FrameDetachedError the frame was removed from the page (or moved to another process),
+// commands have to be sent to the main frame or to the frame which replaced it
+type FrameDetachedError struct {
+	FrameID common.FrameId
+	Reason  string // remove or swap
+}
+
+func (e FrameDetachedError) Error() string {
+	return fmt.Sprintf("frame %s is detached (%s)", e.FrameID, e.Reason)
+}
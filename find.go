@@ -0,0 +1,74 @@
+package control
+
+import (
+	"context"
+	"time"
+)
+
+// FindPollingInterval how often Find queries the selector
+var FindPollingInterval = time.Millisecond * 100
+
+// Find waits until an element matching the selector appears in the main frame (and becomes visible if visible is set).
+// All methods of the package return errors instead of panicking, so Find is the non-panicking way to wait for an element
+func (s Session) Find(selector string, visible bool, timeout time.Duration) (*Element, error) {
+	return s.Page().Find(selector, visible, timeout)
+}
+
+// Find waits until an element matching the selector appears in the frame (and becomes visible if visible is set).
+// On timeout the last error is returned: NoSuchElementError, ErrNodeIsNotVisible or the error of the query
+func (f Frame) Find(selector string, visible bool, timeout time.Duration) (*Element, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	return f.waitElement(context.Background(), deadline.C, selector, visible)
+}
+
+// FindContext is Find which waits until ctx is done, then ctx.Err() is returned
+func (s Session) FindContext(ctx context.Context, selector string, visible bool) (*Element, error) {
+	return s.Page().FindContext(ctx, selector, visible)
+}
+
+// FindContext is Find which waits until ctx is done, then ctx.Err() is returned
+func (f Frame) FindContext(ctx context.Context, selector string, visible bool) (*Element, error) {
+	return f.waitElement(ctx, nil, selector, visible)
+}
+
+func (f Frame) waitElement(ctx context.Context, deadline <-chan time.Time, selector string, visible bool) (*Element, error) {
+	ticker := time.NewTicker(FindPollingInterval)
+	defer ticker.Stop()
+	for {
+		element, err := f.find(ctx, selector, visible)
+		if err == nil {
+			return element, nil
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-f.session.context.Done():
+			return nil, f.session.context.Err()
+		}
+	}
+}
+
+func (f Frame) find(ctx context.Context, selector string, visible bool) (*Element, error) {
+	element, err := f.QuerySelectorContext(ctx, selector)
+	if err != nil || !visible {
+		return element, err
+	}
+	if _, err = element.GetContentQuad(false); err != nil {
+		if err == ErrNodeIsOutOfViewport { // zero size
+			err = ErrNodeIsNotVisible
+		}
+		return nil, err
+	}
+	value, err := element.GetComputedStyle("visibility", nil)
+	if err != nil {
+		return nil, err
+	}
+	if value == "hidden" || value == "collapse" {
+		return nil, ErrNodeIsNotVisible
+	}
+	return element, nil
+}
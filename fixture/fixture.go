@@ -0,0 +1,245 @@
+// Package fixture composes setup of scenarios: hooks run around each scenario and named fixtures
+// (a logged-in session, seeded data) are set up on demand, so suites share setup logic instead of copying it
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ecwid/control"
+)
+
+var ErrUnknownFixture = errors.New("unknown fixture")
+
+// Hook runs before or after each scenario
+type Hook func(*Scope) error
+
+// Fixture named value provided to scenarios. The value is set up on the first Scope.Get of a scenario
+// and torn down after the scenario in reverse order of setup. A shared fixture is set up once per suite
+// (with the scope of the first scenario using it) and torn down by Suite.Close
+type Fixture struct {
+	Name     string
+	Setup    func(*Scope) (interface{}, error)
+	Teardown func(*Scope, interface{}) error // optional
+	Shared   bool
+}
+
+// Suite hooks and fixtures of scenarios
+type Suite struct {
+	NewSession func() (*control.Session, error) // session of each scenario, nil - scenarios have no session
+
+	mx       sync.Mutex
+	before   []Hook
+	after    []Hook
+	fixtures map[string]Fixture
+	shared   map[string]*sharedValue
+	order    []string // shared values in order of setup
+}
+
+type sharedValue struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// BeforeEach adds a hook run before each scenario in order of registration
+func (s *Suite) BeforeEach(hook Hook) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.before = append(s.before, hook)
+}
+
+// AfterEach adds a hook run after each scenario (even a failed one) in reverse order of registration
+func (s *Suite) AfterEach(hook Hook) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.after = append(s.after, hook)
+}
+
+// Use registers fixtures, a fixture with the same name is replaced
+func (s *Suite) Use(fixtures ...Fixture) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.fixtures == nil {
+		s.fixtures = map[string]Fixture{}
+	}
+	for _, f := range fixtures {
+		s.fixtures[f.Name] = f
+	}
+}
+
+// Run runs the scenario with a new scope: BeforeEach hooks, the scenario, AfterEach hooks, teardown of fixtures
+// and the session. Hooks after a failed step are skipped except AfterEach and teardown.
+// The first error is returned, errors of teardown are returned only if everything else succeeded
+func (s *Suite) Run(name string, scenario func(*Scope) error) error {
+	scope := &Scope{Name: name, suite: s, values: map[string]interface{}{}}
+	if s.NewSession != nil {
+		session, err := s.NewSession()
+		if err != nil {
+			return fmt.Errorf("%s: new session: %w", name, err)
+		}
+		scope.Session = session
+		scope.Cleanup(session.Close)
+	}
+	s.mx.Lock()
+	before := append([]Hook{}, s.before...)
+	after := append([]Hook{}, s.after...)
+	s.mx.Unlock()
+
+	var err error
+	for _, hook := range before {
+		if err = hook(scope); err != nil {
+			err = fmt.Errorf("%s: before each: %w", name, err)
+			break
+		}
+	}
+	if err == nil {
+		if err = scenario(scope); err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for n := len(after) - 1; n >= 0; n-- {
+		if e := after[n](scope); e != nil && err == nil {
+			err = fmt.Errorf("%s: after each: %w", name, e)
+		}
+	}
+	if e := scope.teardown(); e != nil && err == nil {
+		err = fmt.Errorf("%s: teardown: %w", name, e)
+	}
+	return err
+}
+
+// Close tears down shared fixtures in reverse order of setup
+func (s *Suite) Close() error {
+	s.mx.Lock()
+	order, shared, fixtures := s.order, s.shared, s.fixtures
+	s.order, s.shared = nil, nil
+	s.mx.Unlock()
+	var err error
+	for n := len(order) - 1; n >= 0; n-- {
+		f, v := fixtures[order[n]], shared[order[n]]
+		if f.Teardown == nil || v.err != nil {
+			continue
+		}
+		if e := f.Teardown(&Scope{Name: f.Name, suite: s}, v.value); e != nil && err == nil {
+			err = fmt.Errorf("%s: %w", f.Name, e)
+		}
+	}
+	return err
+}
+
+func (s *Suite) fixture(name string) (Fixture, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	f, ok := s.fixtures[name]
+	return f, ok
+}
+
+func (s *Suite) sharedValue(f Fixture, scope *Scope) (interface{}, error) {
+	s.mx.Lock()
+	if s.shared == nil {
+		s.shared = map[string]*sharedValue{}
+	}
+	v, ok := s.shared[f.Name]
+	if !ok {
+		v = &sharedValue{}
+		s.shared[f.Name] = v
+	}
+	s.mx.Unlock()
+	v.once.Do(func() {
+		v.value, v.err = f.Setup(scope)
+		if v.err == nil {
+			s.mx.Lock()
+			s.order = append(s.order, f.Name)
+			s.mx.Unlock()
+		}
+	})
+	return v.value, v.err
+}
+
+// Scope state of a running scenario
+type Scope struct {
+	Name    string
+	Session *control.Session // nil if the suite has no NewSession
+
+	suite    *Suite
+	values   map[string]interface{}
+	cleanups []func() error
+}
+
+// Get value of the fixture, it's set up on the first call within the scenario
+func (s *Scope) Get(name string) (interface{}, error) {
+	if v, ok := s.values[name]; ok {
+		return v, nil
+	}
+	f, ok := s.suite.fixture(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFixture, name)
+	}
+	if f.Shared {
+		v, err := s.suite.sharedValue(f, s)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+		s.values[name] = v
+		return v, nil
+	}
+	v, err := f.Setup(s)
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", name, err)
+	}
+	s.values[name] = v
+	if f.Teardown != nil {
+		s.Cleanup(func() error { return f.Teardown(s, v) })
+	}
+	return v, nil
+}
+
+// Cleanup adds a function called when the scenario ends, cleanups are called in reverse order
+func (s *Scope) Cleanup(fn func() error) {
+	s.cleanups = append(s.cleanups, fn)
+}
+
+func (s *Scope) teardown() error {
+	var err error
+	for n := len(s.cleanups) - 1; n >= 0; n-- {
+		if e := s.cleanups[n](); e != nil && err == nil {
+			err = e
+		}
+	}
+	s.cleanups = nil
+	return err
+}
+
+// Login fixture logs in once with the login function and restores the resulting storage state
+// (cookies, localStorage, sessionStorage) into the session of each next scenario. The value is *control.StorageState.
+// The state is kept by the fixture, so the login happens once for all suites using it
+func Login(name string, login func(*control.Session) error) Fixture {
+	var (
+		mx    sync.Mutex
+		state *control.StorageState
+	)
+	return Fixture{
+		Name: name,
+		Setup: func(s *Scope) (interface{}, error) {
+			if s.Session == nil {
+				return nil, errors.New("the suite has no session")
+			}
+			mx.Lock()
+			defer mx.Unlock()
+			if state != nil {
+				return state, s.Session.RestoreStorageState(state)
+			}
+			if err := login(s.Session); err != nil {
+				return nil, err
+			}
+			st, err := s.Session.StorageState()
+			if err != nil {
+				return nil, err
+			}
+			state = st
+			return state, nil
+		},
+	}
+}
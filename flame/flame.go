@@ -0,0 +1,224 @@
+// Package flame aggregates CPU samples of a recorded Chrome trace (ProfileChunk events)
+// into self/total times per function and folded stacks for flamegraph tools
+package flame
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CallFrame JavaScript function location
+type CallFrame struct {
+	FunctionName string `json:"functionName"`
+	URL          string `json:"url"`
+	LineNumber   int    `json:"lineNumber"`
+	ColumnNumber int    `json:"columnNumber"`
+}
+
+func (c CallFrame) String() string {
+	name := c.FunctionName
+	if name == "" {
+		name = "(anonymous)"
+	}
+	if c.URL == "" {
+		return name
+	}
+	return fmt.Sprintf("%s %s:%d", name, c.URL, c.LineNumber+1)
+}
+
+// Function aggregated time of a function over all samples
+type Function struct {
+	CallFrame
+	Self  time.Duration // time the function was on top of the stack
+	Total time.Duration // time the function was anywhere on the stack
+}
+
+// Profile aggregated main thread samples
+type Profile struct {
+	Functions []*Function // sorted by self time, longest first
+	Duration  time.Duration
+	stacks    map[string]time.Duration
+}
+
+type traceEvent struct {
+	Name string          `json:"name"`
+	Ph   string          `json:"ph"`
+	Pid  int             `json:"pid"`
+	Tid  int             `json:"tid"`
+	ID   interface{}     `json:"id"` // string or number
+	Args json.RawMessage `json:"args"`
+}
+
+type profileChunk struct {
+	Data struct {
+		CPUProfile struct {
+			Nodes []struct {
+				ID        int       `json:"id"`
+				Parent    int       `json:"parent"`
+				CallFrame CallFrame `json:"callFrame"`
+			} `json:"nodes"`
+			Samples []int `json:"samples"`
+		} `json:"cpuProfile"`
+		TimeDeltas []int64 `json:"timeDeltas"` // microseconds
+	} `json:"data"`
+}
+
+type node struct {
+	frame  CallFrame
+	parent int
+}
+
+type profile struct {
+	nodes   map[int]node
+	samples []int
+	deltas  []int64
+}
+
+type thread struct{ pid, tid int }
+
+// Parse reads trace JSON (an array of events or an object with traceEvents) and aggregates samples of renderer main threads.
+// If the trace has no thread names all profiles are taken
+func Parse(r io.Reader) (*Profile, error) {
+	var (
+		events   []traceEvent
+		b, err   = ioutil.ReadAll(r)
+		object   struct{ TraceEvents []traceEvent }
+		profiles = map[string]*profile{}
+		owners   = map[string]thread{}
+		main     = map[thread]bool{}
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &events); err != nil {
+		if err = json.Unmarshal(b, &object); err != nil {
+			return nil, err
+		}
+		events = object.TraceEvents
+	}
+	for _, e := range events {
+		key := fmt.Sprintf("%d/%v", e.Pid, e.ID)
+		switch e.Name {
+		case "thread_name":
+			var args struct{ Name string }
+			if json.Unmarshal(e.Args, &args) == nil && args.Name == "CrRendererMain" {
+				main[thread{e.Pid, e.Tid}] = true
+			}
+		case "Profile":
+			owners[key] = thread{e.Pid, e.Tid}
+		case "ProfileChunk":
+			var chunk profileChunk
+			if err = json.Unmarshal(e.Args, &chunk); err != nil {
+				return nil, err
+			}
+			p, ok := profiles[key]
+			if !ok {
+				p = &profile{nodes: map[int]node{}}
+				profiles[key] = p
+			}
+			for _, n := range chunk.Data.CPUProfile.Nodes {
+				p.nodes[n.ID] = node{frame: n.CallFrame, parent: n.Parent}
+			}
+			p.samples = append(p.samples, chunk.Data.CPUProfile.Samples...)
+			p.deltas = append(p.deltas, chunk.Data.TimeDeltas...)
+		}
+	}
+	result := &Profile{stacks: map[string]time.Duration{}}
+	functions := map[CallFrame]*Function{}
+	for key, p := range profiles {
+		if owner, ok := owners[key]; ok && len(main) > 0 && !main[owner] {
+			continue
+		}
+		result.add(p, functions)
+	}
+	for _, f := range functions {
+		result.Functions = append(result.Functions, f)
+	}
+	sort.Slice(result.Functions, func(i, j int) bool {
+		if result.Functions[i].Self == result.Functions[j].Self {
+			return result.Functions[i].Total > result.Functions[j].Total
+		}
+		return result.Functions[i].Self > result.Functions[j].Self
+	})
+	return result, nil
+}
+
+func (p *Profile) add(pr *profile, functions map[CallFrame]*Function) {
+	for n, id := range pr.samples {
+		// the interval until the next sample is spent in this sample's stack
+		if n+1 >= len(pr.deltas) {
+			break
+		}
+		d := time.Duration(pr.deltas[n+1]) * time.Microsecond
+		if d <= 0 {
+			continue
+		}
+		p.Duration += d
+		var (
+			stack []string
+			seen  = map[CallFrame]bool{}
+		)
+		for nodeID := id; nodeID != 0; nodeID = pr.nodes[nodeID].parent {
+			nd, ok := pr.nodes[nodeID]
+			if !ok {
+				break
+			}
+			f := functions[nd.frame]
+			if f == nil {
+				f = &Function{CallFrame: nd.frame}
+				functions[nd.frame] = f
+			}
+			if nodeID == id {
+				f.Self += d
+			}
+			if !seen[nd.frame] { // recursion is counted once
+				f.Total += d
+				seen[nd.frame] = true
+			}
+			stack = append(stack, strings.ReplaceAll(nd.frame.String(), ";", ","))
+		}
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+		p.stacks[strings.Join(stack, ";")] += d
+	}
+}
+
+// ByURL self time per script URL, empty URL collects native and idle time
+func (p *Profile) ByURL() map[string]time.Duration {
+	m := map[string]time.Duration{}
+	for _, f := range p.Functions {
+		m[f.URL] += f.Self
+	}
+	return m
+}
+
+// Find returns the function with the given name (the first by self time if there are several)
+func (p *Profile) Find(functionName string) *Function {
+	for _, f := range p.Functions {
+		if f.FunctionName == functionName {
+			return f
+		}
+	}
+	return nil
+}
+
+// WriteFolded writes stacks in folded format ("root;parent;child <microseconds>") accepted by flamegraph.pl and speedscope
+func (p *Profile) WriteFolded(w io.Writer) error {
+	keys := make([]string, 0, len(p.stacks))
+	for k := range p.stacks {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s %d\n", k, p.stacks[k].Microseconds()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,12 +1,14 @@
 package control
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/ecwid/control/protocol"
 	"github.com/ecwid/control/protocol/common"
 	"github.com/ecwid/control/protocol/page"
 	"github.com/ecwid/control/protocol/runtime"
@@ -62,25 +64,55 @@ func (f Frame) GetLifecycleEvent(event LifecycleEventType) Future {
 	})
 }
 
+// NavigateOptions optional parameters of Page.navigate
+type NavigateOptions struct {
+	Referrer       string
+	ReferrerPolicy page.ReferrerPolicy // e.g. "strictOriginWhenCrossOrigin", "unsafeUrl"
+	TransitionType page.TransitionType // e.g. "link", "typed", "form_submit", default "typed"
+}
+
 func (f Frame) Navigate(url string, waitEvent LifecycleEventType, timeout time.Duration) error {
+	return f.NavigateWithOptions(url, NavigateOptions{}, waitEvent, timeout)
+}
+
+// NavigateWithOptions navigates with referrer and transition type, e.g. to exercise referrer-dependent flows
+func (f Frame) NavigateWithOptions(url string, options NavigateOptions, waitEvent LifecycleEventType, timeout time.Duration) error {
+	return f.navigate(f, url, options, waitEvent, func(future Future) error {
+		_, err := future.Get(timeout)
+		return err
+	})
+}
+
+// NavigateContext navigates and waits for the lifecycle event until ctx is done
+func (f Frame) NavigateContext(ctx context.Context, url string, waitEvent LifecycleEventType) error {
+	return f.navigate(f.session.WithContext(ctx), url, NavigateOptions{}, waitEvent, func(future Future) error {
+		_, err := future.GetContext(ctx)
+		return err
+	})
+}
+
+func (f Frame) navigate(caller protocol.Caller, url string, options NavigateOptions, waitEvent LifecycleEventType, wait func(Future) error) error {
+	f.session.throttle()
 	future := f.GetLifecycleEvent(waitEvent)
 	defer future.Cancel()
-	nav, err := page.Navigate(f, page.NavigateArgs{
-		Url:     url,
-		FrameId: f.id,
+	defer f.watchNavigation(url)()
+	nav, err := page.Navigate(caller, page.NavigateArgs{
+		Url:            url,
+		FrameId:        f.id,
+		Referrer:       options.Referrer,
+		ReferrerPolicy: options.ReferrerPolicy,
+		TransitionType: options.TransitionType,
 	})
 	if err != nil {
 		return err
 	}
 	if nav.ErrorText != "" {
-		return errors.New(nav.ErrorText)
+		return NavigationError{URL: url, ErrorText: nav.ErrorText}
 	}
 	if nav.LoaderId == "" {
 		return ErrAlreadyNavigated
 	}
-	_, err = future.Get(timeout)
-	return err
-
+	return wait(future)
 }
 
 // Reload refresh current page
@@ -98,6 +130,17 @@ func (f Frame) Reload(ignoreCache bool, scriptToEvaluateOnLoad string, eventType
 	return err
 }
 
+// ReloadContext reloads the page and waits for the lifecycle event until ctx is done
+func (f Frame) ReloadContext(ctx context.Context, ignoreCache bool, eventType LifecycleEventType) error {
+	future := f.GetLifecycleEvent(eventType)
+	defer future.Cancel()
+	if err := page.Reload(f.session.WithContext(ctx), page.ReloadArgs{IgnoreCache: ignoreCache}); err != nil {
+		return err
+	}
+	_, err := future.GetContext(ctx)
+	return err
+}
+
 func safeSelector(v string) string {
 	v = strings.TrimSpace(v)
 	v = strings.ReplaceAll(v, `"`, `\"`)
@@ -115,8 +158,13 @@ func (f Frame) IsExist(selector string) bool {
 }
 
 func (f Frame) QuerySelector(selector string) (*Element, error) {
+	return f.QuerySelectorContext(context.Background(), selector)
+}
+
+// QuerySelectorContext is QuerySelector which gives up when ctx is done
+func (f Frame) QuerySelectorContext(ctx context.Context, selector string) (*Element, error) {
 	selector = safeSelector(selector)
-	var object, err = f.evaluate(`document.querySelector("`+selector+`")`, true, false)
+	var object, err = f.evaluateContext(ctx, `document.querySelector("`+selector+`")`, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +183,11 @@ func (f Frame) QuerySelectorAll(selector string) ([]*Element, error) {
 	if array == nil || array.Description == "NodeList(0)" {
 		return nil, nil
 	}
+	return f.constructElements(array)
+}
+
+// constructElements elements of NodeList or array
+func (f Frame) constructElements(array *runtime.RemoteObject) ([]*Element, error) {
 	list := make([]*Element, 0)
 	descriptor, err := f.getProperties(array.ObjectId, true, false)
 	if err != nil {
@@ -161,7 +214,12 @@ func (r RuntimeError) Error() string {
 }
 
 func (f Frame) Evaluate(expression string, await, returnByValue bool) (interface{}, error) {
-	val, err := f.evaluate(expression, await, returnByValue)
+	return f.EvaluateContext(context.Background(), expression, await, returnByValue)
+}
+
+// EvaluateContext is Evaluate which gives up when ctx is done, e.g. while awaiting a promise
+func (f Frame) EvaluateContext(ctx context.Context, expression string, await, returnByValue bool) (interface{}, error) {
+	val, err := f.evaluateContext(ctx, expression, await, returnByValue)
 	if err != nil {
 		return "", err
 	}
@@ -169,17 +227,33 @@ func (f Frame) Evaluate(expression string, await, returnByValue bool) (interface
 }
 
 func (f Frame) evaluate(expression string, await, returnByValue bool) (*runtime.RemoteObject, error) {
-	var uid, ok = f.session.executions.Load(f.id)
-	if !ok {
-		return nil, ErrExecutionContextDestroyed
+	return f.evaluateContext(context.Background(), expression, await, returnByValue)
+}
+
+func (f Frame) evaluateContext(ctx context.Context, expression string, await, returnByValue bool) (*runtime.RemoteObject, error) {
+	caller := f.session.WithContext(ctx)
+	uid, err := f.executionContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	val, err := runtime.Evaluate(f, runtime.EvaluateArgs{
+	args := runtime.EvaluateArgs{
 		Expression:            expression,
 		IncludeCommandLineAPI: true,
-		UniqueContextId:       uid.(string),
+		UniqueContextId:       uid,
 		AwaitPromise:          await,
 		ReturnByValue:         returnByValue,
-	})
+	}
+	val, err := runtime.Evaluate(caller, args)
+	if isContextNotFound(err) {
+		// the frame has navigated between the lookup and the call, try its new context once
+		if v, ok := f.session.executions.Load(f.id); ok && v == uid {
+			f.session.executions.Delete(f.id)
+		}
+		if args.UniqueContextId, err = f.executionContext(ctx); err != nil {
+			return nil, err
+		}
+		val, err = runtime.Evaluate(caller, args)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -189,6 +263,32 @@ func (f Frame) evaluate(expression string, await, returnByValue bool) (*runtime.
 	return val.Result, nil
 }
 
+// executionContext returns the frame's execution context waiting a little if it's being recreated after navigation
+func (f Frame) executionContext(ctx context.Context) (string, error) {
+	deadline := time.Now().Add(executionContextWait)
+	for {
+		if uid, ok := f.session.executions.Load(f.id); ok {
+			return uid.(string), nil
+		}
+		if reason, ok := f.session.detached.Load(f.id); ok {
+			return "", FrameDetachedError{FrameID: f.id, Reason: reason.(string)}
+		}
+		if f.session.IsClosed() || time.Now().After(deadline) {
+			return "", ErrExecutionContextDestroyed
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Millisecond * 20):
+		}
+	}
+}
+
+func isContextNotFound(err error) bool {
+	var e *transport.Error
+	return errors.As(err, &e) && strings.Contains(e.Message, "Cannot find context")
+}
+
 // GetNavigationEntry get current tab info
 func (f Frame) GetNavigationEntry() (*page.NavigationEntry, error) {
 	val, err := page.GetNavigationHistory(f)
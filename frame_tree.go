@@ -0,0 +1,103 @@
+package control
+
+import (
+	"github.com/ecwid/control/protocol/dom"
+	"github.com/ecwid/control/protocol/page"
+)
+
+// FrameTree frame of the page with its nested frames
+type FrameTree struct {
+	*Frame
+	URL      string
+	Name     string
+	Children []*FrameTree
+}
+
+// FrameTree https://chromedevtools.github.io/devtools-protocol/tot/Page/#method-getFrameTree
+func (s Session) FrameTree() (*FrameTree, error) {
+	val, err := page.GetFrameTree(s)
+	if err != nil {
+		return nil, err
+	}
+	return s.frameTree(val.FrameTree), nil
+}
+
+func (s Session) frameTree(t *page.FrameTree) *FrameTree {
+	node := &FrameTree{
+		Frame: &Frame{id: t.Frame.Id, session: &s},
+		URL:   t.Frame.Url,
+		Name:  t.Frame.Name,
+	}
+	for _, child := range t.ChildFrames {
+		node.Children = append(node.Children, s.frameTree(child))
+	}
+	return node
+}
+
+// Frames all frames of the page in depth-first order, the main frame goes first
+func (s Session) Frames() ([]*Frame, error) {
+	tree, err := s.FrameTree()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		frames []*Frame
+		walk   func(*FrameTree)
+	)
+	walk = func(t *FrameTree) {
+		frames = append(frames, t.Frame)
+		for _, child := range t.Children {
+			walk(child)
+		}
+	}
+	walk(tree)
+	return frames, nil
+}
+
+// Content HTML of the frame's document
+func (f Frame) Content() (string, error) {
+	var content string
+	err := f.evaluateValue(`document.documentElement?document.documentElement.outerHTML:""`, false, &content)
+	return content, err
+}
+
+// InnerText rendered text of the frame's document body
+func (f Frame) InnerText() (string, error) {
+	var text string
+	err := f.evaluateValue(`document.body?document.body.innerText:""`, false, &text)
+	return text, err
+}
+
+// Screenshot captures the visible viewport for the main frame or the box of the iframe element otherwise
+func (f Frame) Screenshot(format string, quality int) ([]byte, error) {
+	if f.id == f.session.Page().id {
+		return f.session.CaptureScreenshot(format, quality, nil, false, false)
+	}
+	owner, err := dom.GetFrameOwner(f.session, dom.GetFrameOwnerArgs{FrameId: f.id})
+	if err != nil {
+		return nil, err
+	}
+	if err = dom.ScrollIntoViewIfNeeded(f.session, dom.ScrollIntoViewIfNeededArgs{BackendNodeId: owner.BackendNodeId}); err != nil {
+		return nil, err
+	}
+	val, err := dom.GetContentQuads(f.session, dom.GetContentQuadsArgs{BackendNodeId: owner.BackendNodeId})
+	if err != nil {
+		return nil, err
+	}
+	quads := convertQuads(val.Quads)
+	if len(quads) == 0 {
+		return nil, ErrNodeIsNotVisible
+	}
+	metrics, err := f.session.GetLayoutMetrics()
+	if err != nil {
+		return nil, err
+	}
+	q := quads[0]
+	return f.session.CaptureScreenshot(format, quality, &page.Viewport{
+		X:      q[0].X + metrics.CssVisualViewport.PageX,
+		Y:      q[0].Y + metrics.CssVisualViewport.PageY,
+		Width:  q[1].X - q[0].X,
+		Height: q[3].Y - q[0].Y,
+		Scale:  1,
+	}, false, false)
+}
@@ -0,0 +1,353 @@
+package control
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/redact"
+	"github.com/ecwid/control/transport"
+)
+
+// HAR 1.2 archive, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"` // milliseconds
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings in milliseconds, -1 if the phase doesn't apply
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HAROptions of RecordHAR
+type HAROptions struct {
+	Bodies      bool           // fetch response bodies when they are loaded
+	MaxBodySize int            // larger bodies are not stored, zero - no limit
+	Redaction   *redact.Policy // nil - the policy of the browser, see SetRedactionPolicy
+}
+
+type harBody struct {
+	text   string
+	base64 bool
+	size   int
+}
+
+// HARRecorder records requests of the page, see RecordHAR
+type HARRecorder struct {
+	options HAROptions
+	log     *RequestLog
+	mx      sync.Mutex
+	bodies  map[network.RequestId]harBody
+	cancel  func()
+}
+
+// RecordHAR records requests of the page until Stop is called, HAR may be taken at any moment.
+// URLs, headers and bodies are masked by the redaction policy
+func (s Session) RecordHAR(options HAROptions) (*HARRecorder, error) {
+	if options.Redaction == nil {
+		options.Redaction = s.browser.RedactionPolicy()
+	}
+	r := &HARRecorder{options: options, bodies: map[network.RequestId]harBody{}, cancel: func() {}}
+	if options.Bodies {
+		// bodies are evicted from the browser's buffer soon, so they are taken right away
+		r.cancel = s.Subscribe("Network.loadingFinished", func(e transport.Event) error {
+			var v = network.LoadingFinished{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				return err
+			}
+			r.fetchBody(s, v.RequestId)
+			return nil
+		})
+	}
+	log, err := s.CaptureRequests()
+	if err != nil {
+		r.cancel()
+		return nil, err
+	}
+	r.log = log
+	return r, nil
+}
+
+func (r *HARRecorder) fetchBody(s Session, id network.RequestId) {
+	val, err := network.GetResponseBody(s, network.GetResponseBodyArgs{RequestId: id})
+	if err != nil {
+		return // no body (e.g. redirect, 204, preflight)
+	}
+	body := harBody{text: val.Body, base64: val.Base64Encoded, size: len(val.Body)}
+	if body.base64 {
+		body.size = base64.StdEncoding.DecodedLen(len(val.Body))
+	}
+	if r.options.MaxBodySize > 0 && body.size > r.options.MaxBodySize {
+		body.text = ""
+	}
+	r.mx.Lock()
+	r.bodies[id] = body
+	r.mx.Unlock()
+}
+
+// Stop recording, requests recorded so far are kept
+func (r *HARRecorder) Stop() {
+	r.log.Stop()
+	r.cancel()
+}
+
+// HAR of requests recorded so far
+func (r *HARRecorder) HAR() *HAR {
+	entries := r.log.Entries()
+	last := map[network.RequestId]int{} // redirects share the ID, the body belongs to the last one
+	for n, e := range entries {
+		last[e.ID] = n
+	}
+	har := &HAR{Log: HARLog{Version: "1.2", Creator: HARCreator{Name: "control"}, Entries: make([]HAREntry, 0, len(entries))}}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for n, e := range entries {
+		var next *RequestEntry
+		if last[e.ID] != n {
+			for k := n + 1; k < len(entries); k++ {
+				if entries[k].ID == e.ID {
+					next = &entries[k]
+					break
+				}
+			}
+		}
+		entry := r.entry(e, next)
+		if next == nil {
+			if body, ok := r.bodies[e.ID]; ok {
+				entry.Response.Content.Size = body.size
+				entry.Response.Content.Text = body.text
+				if body.base64 {
+					entry.Response.Content.Encoding = "base64"
+				} else {
+					entry.Response.Content.Text = r.redactBody(e.MimeType, body.text)
+				}
+			}
+		}
+		har.Log.Entries = append(har.Log.Entries, entry)
+	}
+	return har
+}
+
+func (r *HARRecorder) entry(e RequestEntry, redirect *RequestEntry) HAREntry {
+	p := r.options.Redaction
+	version := httpVersion(e.Protocol)
+	entry := HAREntry{
+		StartedDateTime: e.Started,
+		Request: HARRequest{
+			Method:      e.Method,
+			URL:         p.URL(e.URL),
+			HTTPVersion: version,
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(p, e.RequestHeaders),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(e.PostData),
+		},
+		Response: HARResponse{
+			Status:      e.Status,
+			StatusText:  e.StatusText,
+			HTTPVersion: version,
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(p, e.ResponseHeaders),
+			Content:     HARContent{MimeType: e.MimeType},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		ServerIPAddress: e.RemoteAddress,
+		ResourceType:    string(e.Type),
+		Error:           e.ErrorText,
+	}
+	if u, err := url.Parse(p.URL(e.URL)); err == nil {
+		for name, values := range u.Query() {
+			for _, v := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, HARNameValue{Name: name, Value: v})
+			}
+		}
+		sort.Slice(entry.Request.QueryString, func(a, b int) bool {
+			return entry.Request.QueryString[a].Name < entry.Request.QueryString[b].Name
+		})
+	}
+	if e.PostData != "" {
+		mimeType, _ := e.RequestHeaders["Content-Type"].(string)
+		if mimeType == "" {
+			mimeType, _ = e.RequestHeaders["content-type"].(string)
+		}
+		entry.Request.PostData = &HARPostData{MimeType: mimeType, Text: r.redactBody(mimeType, e.PostData)}
+	}
+	if redirect != nil {
+		entry.Response.RedirectURL = p.URL(redirect.URL)
+	}
+	if e.Finished && !e.FromCache {
+		entry.Response.BodySize = int(e.EncodedBytes)
+	}
+	entry.Timings, entry.Time = harTimings(e)
+	return entry
+}
+
+func (r *HARRecorder) redactBody(mimeType, text string) string {
+	if strings.Contains(mimeType, "json") {
+		return string(r.options.Redaction.JSON([]byte(text)))
+	}
+	return r.options.Redaction.String(text)
+}
+
+// Write writes HAR of requests recorded so far
+func (r *HARRecorder) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.HAR())
+}
+
+// WriteFile saves HAR of requests recorded so far into the file
+func (r *HARRecorder) WriteFile(name string) error {
+	b, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0644)
+}
+
+func harHeaders(p *redact.Policy, h map[string]interface{}) []HARNameValue {
+	list := []HARNameValue{}
+	for name, value := range h {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		// the browser joins repeated headers with a new line
+		for _, v := range strings.Split(str, "\n") {
+			list = append(list, HARNameValue{Name: name, Value: p.Header(name, v)})
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list
+}
+
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2", "spdy":
+		return "HTTP/2"
+	case "h3", "quic":
+		return "HTTP/3"
+	case "":
+		return ""
+	}
+	return strings.ToUpper(protocol)
+}
+
+func harTimings(e RequestEntry) (HARTimings, float64) {
+	total := float64(e.Duration) / float64(time.Millisecond)
+	t := e.Timing
+	if t == nil {
+		return HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: total}, total
+	}
+	phase := func(start, end float64) float64 {
+		if start < 0 || end < start {
+			return -1
+		}
+		return end - start
+	}
+	timings := HARTimings{
+		DNS:     phase(t.DnsStart, t.DnsEnd),
+		Connect: phase(t.ConnectStart, t.ConnectEnd),
+		SSL:     phase(t.SslStart, t.SslEnd),
+		Send:    t.SendEnd - t.SendStart,
+		Wait:    t.ReceiveHeadersEnd - t.SendEnd,
+		Receive: total - t.ReceiveHeadersEnd,
+	}
+	switch {
+	case t.DnsStart >= 0:
+		timings.Blocked = t.DnsStart
+	case t.ConnectStart >= 0:
+		timings.Blocked = t.ConnectStart
+	default:
+		timings.Blocked = t.SendStart
+	}
+	if timings.Receive < 0 {
+		timings.Receive = 0
+	}
+	var sum float64
+	for _, v := range []float64{timings.Blocked, timings.DNS, timings.Connect, timings.Send, timings.Wait, timings.Receive} {
+		if v > 0 {
+			sum += v
+		}
+	}
+	return timings, sum
+}
@@ -0,0 +1,114 @@
+package control
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/input"
+)
+
+// inputState is shared between all copies of Input
+type inputState struct {
+	mx       sync.Mutex
+	humanize bool
+	x, y     float64 // the last known mouse position
+	pressed  input.MouseButton
+	keys     map[string]bool // held by Keyboard
+	rnd      *rand.Rand
+}
+
+func newInputState() *inputState {
+	return &inputState{keys: map[string]bool{}, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (s *inputState) isHumanized() bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.humanize
+}
+
+func (s *inputState) setPosition(x, y float64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.x, s.y = x, y
+}
+
+// modifiers bit field of modifier keys held by Keyboard
+func (s *inputState) modifiers() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	var m int
+	for key := range s.keys {
+		m |= modifierKeys[key]
+	}
+	return m
+}
+
+func (s *inputState) position() (float64, float64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.x, s.y
+}
+
+// float64 returns a random value in [from, to)
+func (s *inputState) float64(from, to float64) float64 {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return from + s.rnd.Float64()*(to-from)
+}
+
+// SetHumanize turns on the mode where mouse moves follow curved paths with variable speed
+// and typing has realistic inter-key timing variance
+func (i Input) SetHumanize(enabled bool) {
+	i.state.mx.Lock()
+	defer i.state.mx.Unlock()
+	i.state.humanize = enabled
+}
+
+// humanMouseMove moves the mouse along a cubic Bezier curve, slow at the ends and fast in the middle
+func (i Input) humanMouseMove(button input.MouseButton, x, y float64) error {
+	x0, y0 := i.state.position()
+	distance := math.Hypot(x-x0, y-y0)
+	if distance < 1 {
+		return i.mouseMoved(button, x, y)
+	}
+	// control points are shifted aside of the straight line
+	spread := distance * 0.3
+	cx1, cy1 := x0+(x-x0)*0.3+i.state.float64(-spread, spread), y0+(y-y0)*0.3+i.state.float64(-spread, spread)
+	cx2, cy2 := x0+(x-x0)*0.7+i.state.float64(-spread, spread), y0+(y-y0)*0.7+i.state.float64(-spread, spread)
+	steps := int(math.Max(5, math.Min(50, distance/15)))
+	duration := time.Duration(i.state.float64(150, 300)+distance*0.5) * time.Millisecond
+	for n := 1; n <= steps; n++ {
+		t := float64(n) / float64(steps)
+		t = t * t * (3 - 2*t) // ease-in-out
+		px := bezier(t, x0, cx1, cx2, x)
+		py := bezier(t, y0, cy1, cy2, y)
+		if err := i.mouseMoved(button, px, py); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(float64(duration) / float64(steps) * i.state.float64(0.5, 1.5)))
+	}
+	return i.mouseMoved(button, x, y)
+}
+
+func bezier(t, p0, p1, p2, p3 float64) float64 {
+	u := 1 - t
+	return u*u*u*p0 + 3*u*u*t*p1 + 3*u*t*t*p2 + t*t*t*p3
+}
+
+// keyDelay returns the delay between keystrokes, varied in humanized mode
+func (i Input) keyDelay(delay time.Duration) time.Duration {
+	if !i.state.isHumanized() {
+		return delay
+	}
+	if delay <= 0 {
+		delay = time.Millisecond * 80
+	}
+	d := time.Duration(float64(delay) * i.state.float64(0.4, 1.6))
+	if i.state.float64(0, 1) < 0.05 { // occasional thinking pause
+		d += time.Duration(i.state.float64(200, 600)) * time.Millisecond
+	}
+	return d
+}
@@ -133,8 +133,9 @@ var keyDefinitions = map[rune]KeyDefinition{
 }
 
 type Input struct {
-	mx *sync.Mutex
-	s  *Session
+	mx    *sync.Mutex
+	s     *Session
+	state *inputState
 }
 
 func (i Input) Click(button input.MouseButton, x, y float64, delay time.Duration) (err error) {
@@ -154,32 +155,45 @@ func (i Input) Click(button input.MouseButton, x, y float64, delay time.Duration
 }
 
 func (i Input) MouseMove(button input.MouseButton, x, y float64) error {
+	if i.state.isHumanized() {
+		return i.humanMouseMove(button, x, y)
+	}
+	return i.mouseMoved(button, x, y)
+}
+
+func (i Input) mouseMoved(button input.MouseButton, x, y float64) error {
+	i.state.setPosition(x, y)
 	return input.DispatchMouseEvent(i.s, input.DispatchMouseEventArgs{
 		X:          x,
 		Y:          y,
 		Type:       "mouseMoved",
 		Button:     button,
 		ClickCount: 1,
+		Modifiers:  i.state.modifiers(),
 	})
 }
 
 func (i Input) MousePress(button input.MouseButton, x, y float64) error {
+	i.state.setPosition(x, y)
 	return input.DispatchMouseEvent(i.s, input.DispatchMouseEventArgs{
 		X:          x,
 		Y:          y,
 		Type:       "mousePressed",
 		Button:     button,
 		ClickCount: 1,
+		Modifiers:  i.state.modifiers(),
 	})
 }
 
 func (i Input) MouseRelease(button input.MouseButton, x, y float64) error {
+	i.state.setPosition(x, y)
 	return input.DispatchMouseEvent(i.s, input.DispatchMouseEventArgs{
 		X:          x,
 		Y:          y,
 		Type:       "mouseReleased",
 		Button:     button,
 		ClickCount: 1,
+		Modifiers:  i.state.modifiers(),
 	})
 }
 
@@ -255,3 +269,35 @@ func (i Input) Press(key KeyDefinition) error {
 		Text: key.Text,
 	})
 }
+
+// Modifiers bit field of DispatchKeyEventArgs and DispatchMouseEventArgs
+const (
+	ModifierAlt   = 1
+	ModifierCtrl  = 2
+	ModifierMeta  = 4 // Command on Mac
+	ModifierShift = 8
+)
+
+// PressWithModifiers presses the key while holding modifiers (key chord).
+// commands are editing commands for the chord (e.g. "selectAll", "copy"),
+// they are required to get the native behaviour since the OS doesn't handle synthetic keys
+func (i Input) PressWithModifiers(key KeyDefinition, modifiers int, commands ...string) error {
+	err := input.DispatchKeyEvent(i.s, input.DispatchKeyEventArgs{
+		Type:                  "rawKeyDown",
+		Modifiers:             modifiers,
+		Key:                   key.Key,
+		Code:                  key.Code,
+		WindowsVirtualKeyCode: key.KeyCode,
+		Commands:              commands,
+	})
+	if err != nil {
+		return err
+	}
+	return input.DispatchKeyEvent(i.s, input.DispatchKeyEventArgs{
+		Type:                  dispatchKeyEventKeyUp,
+		Modifiers:             modifiers,
+		Key:                   key.Key,
+		Code:                  key.Code,
+		WindowsVirtualKeyCode: key.KeyCode,
+	})
+}
@@ -0,0 +1,360 @@
+package control
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/ecwid/control/internal/conditional"
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/fetch"
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/transport"
+)
+
+const (
+	StageRequest  fetch.RequestStage = "Request"  // before the request is sent
+	StageResponse fetch.RequestStage = "Response" // after the response headers are received
+)
+
+// InterceptHandler handles paused request, see Interception
+type InterceptHandler func(*Interception)
+
+// Interception request (or response at StageResponse) paused by Fetch domain.
+// URL, Method, PostData and Header may be modified by handlers. If no handler calls Abort, Fulfill or Continue
+// the request continues with the modifications. Handlers are called in order of registration
+type Interception struct {
+	ID           fetch.RequestId
+	Stage        fetch.RequestStage
+	ResourceType network.ResourceType
+	Request      *network.Request // original request
+	URL          string
+	Method       string
+	PostData     string
+	Header       http.Header // request headers at StageRequest, response headers at StageResponse
+	Status       int         // response status code, StageResponse only
+
+	session  *Session
+	original http.Header
+	status   int
+	aborted  bool
+	done     bool
+	fulfill  *fetch.FulfillRequestArgs
+}
+
+// Continue lets the request go (with modifications made so far) skipping the rest of handlers
+func (i *Interception) Continue() {
+	i.done = true
+}
+
+// Abort fails the request with network error
+func (i *Interception) Abort() {
+	i.aborted = true
+}
+
+// Fulfill provides response to the request. A 200 response is turned into 304 Not Modified
+// if the request's If-None-Match or If-Modified-Since matches ETag or Last-Modified of the header
+func (i *Interception) Fulfill(status int, header http.Header, body []byte) {
+	if status == http.StatusOK && header != nil && conditional.NotModified(i.Request.Method, i.requestHeader(), header) {
+		status, header, body = http.StatusNotModified, conditional.Header(header), nil
+	}
+	i.fulfill = &fetch.FulfillRequestArgs{
+		RequestId:       i.ID,
+		ResponseCode:    status,
+		ResponseHeaders: headerEntries(header),
+		Body:            body,
+	}
+	i.done = true
+}
+
+// Body reads the whole response body (StageResponse only)
+func (i *Interception) Body() ([]byte, error) {
+	if i.Stage != StageResponse {
+		return nil, nil
+	}
+	val, err := fetch.GetResponseBody(i.session, fetch.GetResponseBodyArgs{RequestId: i.ID})
+	if err != nil {
+		return nil, err
+	}
+	if val.Base64Encoded {
+		return base64.StdEncoding.DecodeString(val.Body)
+	}
+	return []byte(val.Body), nil
+}
+
+// BodyStream is Body which reads the response body as a stream (StageResponse only).
+// The body is consumed, so the request has to be fulfilled or aborted afterwards
+func (i *Interception) BodyStream() (*Stream, error) {
+	if i.Stage != StageResponse {
+		return nil, nil
+	}
+	val, err := fetch.TakeResponseBodyAsStream(i.session, fetch.TakeResponseBodyAsStreamArgs{RequestId: i.ID})
+	if err != nil {
+		return nil, err
+	}
+	return NewStream(i.session, val.Stream), nil
+}
+
+// requestHeader headers of the request as modified by handlers so far
+func (i *Interception) requestHeader() http.Header {
+	if i.Stage == StageRequest {
+		return i.Header
+	}
+	h := http.Header{}
+	for name, value := range headersMap(i.Request.Headers) {
+		if str, ok := value.(string); ok {
+			h.Set(name, str)
+		}
+	}
+	return h
+}
+
+func (i *Interception) decided() bool {
+	return i.aborted || i.done
+}
+
+func (i *Interception) resolve() error {
+	switch {
+	case i.aborted:
+		return fetch.FailRequest(i.session, fetch.FailRequestArgs{RequestId: i.ID, ErrorReason: "BlockedByClient"})
+	case i.fulfill != nil:
+		return fetch.FulfillRequest(i.session, *i.fulfill)
+	case i.Stage == StageResponse:
+		if i.Status == i.status && reflect.DeepEqual(i.Header, i.original) {
+			return fetch.ContinueRequest(i.session, fetch.ContinueRequestArgs{RequestId: i.ID})
+		}
+		return fetch.ContinueResponse(i.session, fetch.ContinueResponseArgs{
+			RequestId:       i.ID,
+			ResponseCode:    i.Status,
+			ResponseHeaders: headerEntries(i.Header),
+		})
+	}
+	args := fetch.ContinueRequestArgs{RequestId: i.ID}
+	if i.URL != i.Request.Url {
+		args.Url = i.URL
+	}
+	if i.Method != i.Request.Method {
+		args.Method = i.Method
+	}
+	if i.PostData != i.Request.PostData {
+		args.PostData = []byte(i.PostData)
+	}
+	if !reflect.DeepEqual(i.Header, i.original) {
+		args.Headers = headerEntries(i.Header)
+	}
+	return fetch.ContinueRequest(i.session, args)
+}
+
+type interceptRoute struct {
+	pattern string
+	stage   fetch.RequestStage
+	handler InterceptHandler
+}
+
+// interceptor owns Fetch domain of the session, patterns of all routes are merged into a single Fetch.enable
+type interceptor struct {
+	mx       sync.Mutex
+	guid     uint64
+	routes   map[uint64]interceptRoute
+	auth     func(*fetch.AuthRequired) *fetch.AuthChallengeResponse // nil - auth challenges are not handled
+	answered map[fetch.RequestId]bool                               // requests whose challenge is answered by auth
+	cancel   func()
+
+	fetchMx sync.Mutex        // guards fetch, never held while calling the browser
+	fetch   *fetch.EnableArgs // Fetch.enable sent last, nil - Fetch is disabled
+}
+
+// fetchState Fetch.enable to restore on a new session, nil if Fetch is disabled
+func (i *interceptor) fetchState() *fetch.EnableArgs {
+	i.fetchMx.Lock()
+	defer i.fetchMx.Unlock()
+	return i.fetch
+}
+
+func (i *interceptor) setFetchState(args *fetch.EnableArgs) {
+	i.fetchMx.Lock()
+	defer i.fetchMx.Unlock()
+	i.fetch = args
+}
+
+const maxAnsweredChallenges = 1024
+
+// Intercept pauses requests matching URL pattern at the given stage and passes them to the handler.
+// Wildcards ('*' -> zero or more, '?' -> exactly one) are allowed, an empty pattern is equivalent to "*"
+func (s Session) Intercept(pattern string, stage fetch.RequestStage, handler InterceptHandler) (cancel func(), err error) {
+	i := s.interceptor
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.guid++
+	uid := i.guid
+	i.routes[uid] = interceptRoute{pattern: pattern, stage: stage, handler: handler}
+	if err = s.updateFetch(); err != nil {
+		delete(i.routes, uid)
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			i.mx.Lock()
+			defer i.mx.Unlock()
+			delete(i.routes, uid)
+			if err := s.updateFetch(); err != nil {
+				s.browser.internalError(err)
+			}
+		})
+	}, nil
+}
+
+// updateFetch enables Fetch with the union of routes' patterns or disables it if nothing is intercepted, i.mx must be held
+func (s Session) updateFetch() error {
+	i := s.interceptor
+	if len(i.routes) == 0 && i.auth == nil {
+		if i.cancel != nil {
+			i.cancel()
+			i.cancel = nil
+			i.setFetchState(nil)
+			return fetch.Disable(s)
+		}
+		return nil
+	}
+	if i.cancel == nil {
+		i.cancel = s.Subscribe("*", s.handleFetch)
+	}
+	args := i.enableArgs()
+	i.setFetchState(&args)
+	return fetch.Enable(s, args)
+}
+
+// enableArgs Fetch.enable with the union of routes' patterns, i.mx must be held
+func (i *interceptor) enableArgs() fetch.EnableArgs {
+	var (
+		patterns []*fetch.RequestPattern
+		seen     = map[fetch.RequestPattern]bool{}
+	)
+	for _, r := range i.routes {
+		p := fetch.RequestPattern{UrlPattern: r.pattern, RequestStage: r.stage}
+		if p.UrlPattern == "" {
+			p.UrlPattern = "*"
+		}
+		if !seen[p] {
+			seen[p] = true
+			patterns = append(patterns, &p)
+		}
+	}
+	if all := (fetch.RequestPattern{UrlPattern: "*", RequestStage: StageRequest}); i.auth != nil && !seen[all] {
+		// challenges are reported for paused requests only, requests without handlers are continued as is
+		patterns = append(patterns, &all)
+	}
+	return fetch.EnableArgs{Patterns: patterns, HandleAuthRequests: i.auth != nil}
+}
+
+func (s Session) handleFetch(e transport.Event) error {
+	switch e.Method {
+	case "Fetch.requestPaused":
+		var v = fetch.RequestPaused{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		go s.paused(v) // handlers may call the browser, do not block the queue
+	case "Fetch.authRequired":
+		var v = fetch.AuthRequired{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		s.interceptor.mx.Lock()
+		auth := s.interceptor.auth
+		retry := s.interceptor.answered[v.RequestId]
+		s.interceptor.mx.Unlock()
+		response := &fetch.AuthChallengeResponse{Response: "Default"}
+		if retry {
+			// the credentials are rejected, do not loop
+			response.Response = "CancelAuth"
+		} else if auth != nil {
+			if r := auth(&v); r != nil {
+				response = r
+				s.interceptor.answer(v.RequestId)
+			}
+		}
+		return fetch.ContinueWithAuth(s, fetch.ContinueWithAuthArgs{RequestId: v.RequestId, AuthChallengeResponse: response})
+	}
+	return nil
+}
+
+func (i *interceptor) answer(id fetch.RequestId) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	if i.answered == nil || len(i.answered) >= maxAnsweredChallenges {
+		i.answered = map[fetch.RequestId]bool{}
+	}
+	i.answered[id] = true
+}
+
+func (s Session) paused(v fetch.RequestPaused) {
+	i := &Interception{
+		ID:           v.RequestId,
+		Stage:        StageRequest,
+		ResourceType: v.ResourceType,
+		Request:      v.Request,
+		URL:          v.Request.Url,
+		Method:       v.Request.Method,
+		PostData:     v.Request.PostData,
+		session:      &s,
+	}
+	if v.ResponseStatusCode != 0 || v.ResponseErrorReason != "" {
+		i.Stage = StageResponse
+		i.Status, i.status = v.ResponseStatusCode, v.ResponseStatusCode
+		i.Header = http.Header{}
+		for _, h := range v.ResponseHeaders {
+			i.Header.Add(h.Name, h.Value)
+		}
+	} else {
+		i.Header = http.Header{}
+		for name, value := range headersMap(v.Request.Headers) {
+			if str, ok := value.(string); ok {
+				i.Header.Set(name, str)
+			}
+		}
+	}
+	i.original = i.Header.Clone()
+	for _, handler := range s.interceptHandlers(v.Request.Url, i.Stage) {
+		handler(i)
+		if i.decided() {
+			break
+		}
+	}
+	if err := i.resolve(); err != nil && !s.IsClosed() {
+		s.browser.internalError(err)
+	}
+}
+
+func (s Session) interceptHandlers(url string, stage fetch.RequestStage) []InterceptHandler {
+	i := s.interceptor
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	var uids []uint64
+	for uid, r := range i.routes {
+		if r.stage == stage && wildcard.Match(r.pattern, url) {
+			uids = append(uids, uid)
+		}
+	}
+	sort.Slice(uids, func(a, b int) bool { return uids[a] < uids[b] })
+	list := make([]InterceptHandler, len(uids))
+	for n, uid := range uids {
+		list[n] = i.routes[uid].handler
+	}
+	return list
+}
+
+func headerEntries(h http.Header) []*fetch.HeaderEntry {
+	var list []*fetch.HeaderEntry
+	for name, values := range h {
+		for _, v := range values {
+			list = append(list, &fetch.HeaderEntry{Name: name, Value: v})
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list
+}
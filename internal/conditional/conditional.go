@@ -0,0 +1,55 @@
+// Package conditional evaluates conditional request headers against stubbed responses (RFC 9110, section 13)
+package conditional
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NotModified reports whether a 200 response to GET/HEAD with the given headers has to be replaced by 304.
+// If-None-Match takes precedence over If-Modified-Since
+func NotModified(method string, request, response http.Header) bool {
+	if method != "" && method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	if inm := request.Get("If-None-Match"); inm != "" {
+		etag := response.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weak(candidate) == weak(etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, lm := request.Get("If-Modified-Since"), response.Get("Last-Modified")
+	if ims == "" || lm == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lm)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// Header of 304 response derived from the full response header
+func Header(response http.Header) http.Header {
+	h := response.Clone()
+	for _, name := range []string{"Content-Length", "Content-Type", "Content-Encoding", "Content-Range", "Transfer-Encoding"} {
+		h.Del(name)
+	}
+	return h
+}
+
+// weak comparison ignores W/ prefix
+func weak(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
@@ -0,0 +1,38 @@
+package conditional
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNotModified(t *testing.T) {
+	response := http.Header{"Etag": {`"v1"`}, "Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"}}
+	for _, c := range []struct {
+		method  string
+		request http.Header
+		want    bool
+	}{
+		{"GET", http.Header{}, false},
+		{"GET", http.Header{"If-None-Match": {`"v1"`}}, true},
+		{"HEAD", http.Header{"If-None-Match": {`"v0", W/"v1"`}}, true},
+		{"GET", http.Header{"If-None-Match": {"*"}}, true},
+		{"GET", http.Header{"If-None-Match": {`"v2"`}}, false},
+		{"POST", http.Header{"If-None-Match": {`"v1"`}}, false},
+		{"GET", http.Header{"If-Modified-Since": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, true},
+		{"GET", http.Header{"If-Modified-Since": {"Tue, 20 Oct 2015 07:28:00 GMT"}}, false},
+		{"GET", http.Header{"If-Modified-Since": {"not a date"}}, false},
+		// If-None-Match takes precedence
+		{"GET", http.Header{"If-None-Match": {`"v2"`}, "If-Modified-Since": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, false},
+	} {
+		if got := NotModified(c.method, c.request, response); got != c.want {
+			t.Errorf("%s %v: got %v, want %v", c.method, c.request, got, c.want)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := Header(http.Header{"Etag": {`"v1"`}, "Content-Type": {"text/html"}, "Content-Length": {"10"}})
+	if h.Get("ETag") != `"v1"` || h.Get("Content-Type") != "" || h.Get("Content-Length") != "" {
+		t.Errorf("got %v", h)
+	}
+}
@@ -0,0 +1,30 @@
+package wildcard
+
+// Match reports whether s matches the wildcard pattern ('*' -> zero or more, '?' -> exactly one).
+// The same syntax is used by Fetch.RequestPattern.urlPattern
+func Match(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	var p, n, star, mark = 0, 0, -1, 0
+	for n < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[n]):
+			p++
+			n++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, n
+			p++
+		case star != -1:
+			p = star + 1
+			mark++
+			n = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
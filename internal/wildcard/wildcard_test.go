@@ -0,0 +1,31 @@
+package wildcard
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "anything", true},
+		{"*", "", true},
+		{"*", "https://example.com/", true},
+		{"https://example.com/", "https://example.com/", true},
+		{"https://example.com/", "https://example.com/a", false},
+		{"https://example.com/*", "https://example.com/a/b", true},
+		{"*://example.com/*", "http://example.com/", true},
+		{"*.png", "https://example.com/a.png", true},
+		{"*.png", "https://example.com/a.png?x", false},
+		{"*.png*", "https://example.com/a.png?x", true},
+		{"devtools://*", "chrome-extension://x", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{"**a", "ba", true},
+	} {
+		if got := Match(c.pattern, c.s); got != c.want {
+			t.Errorf("%q %q: got %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package control
+
+import "log"
+
+// OnInternalError sets handler for library-internal failures which happen in goroutines users don't own
+// (event handling, subscribers' errors and panics). By default such errors are logged
+func (b BrowserContext) OnInternalError(handler func(error)) {
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	b.state.onError = handler
+}
+
+func (b BrowserContext) internalError(err error) {
+	b.state.mx.Lock()
+	handler := b.state.onError
+	b.state.mx.Unlock()
+	if handler == nil {
+		log.Printf("control: %s", b.RedactionPolicy().String(err.Error()))
+		return
+	}
+	handler(err)
+}
@@ -0,0 +1,70 @@
+package control
+
+import (
+	"github.com/ecwid/control/protocol/common"
+	"github.com/ecwid/control/protocol/target"
+)
+
+// IsolatedContextOptions of CreateIsolatedContext
+type IsolatedContextOptions struct {
+	ProxyServer     string // e.g. "http://127.0.0.1:8080", empty - the browser's proxy
+	ProxyBypassList string
+}
+
+// IsolatedContext incognito-like browser context: cookies, cache and storage are not shared with other contexts
+type IsolatedContext struct {
+	ID      common.BrowserContextID
+	browser BrowserContext
+}
+
+// CreateIsolatedContext creates a new browser context, so parallel workers of one browser don't share auth state
+func (b BrowserContext) CreateIsolatedContext(options IsolatedContextOptions) (*IsolatedContext, error) {
+	val, err := target.CreateBrowserContext(b, target.CreateBrowserContextArgs{
+		ProxyServer:     options.ProxyServer,
+		ProxyBypassList: options.ProxyBypassList,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &IsolatedContext{ID: val.BrowserContextId, browser: b}, nil
+}
+
+// IsolatedContexts returns IDs of all contexts created by CreateIsolatedContext (by any client) and not disposed yet
+func (b BrowserContext) IsolatedContexts() ([]common.BrowserContextID, error) {
+	val, err := target.GetBrowserContexts(b)
+	if err != nil {
+		return nil, err
+	}
+	return val.BrowserContextIds, nil
+}
+
+// CreatePageTarget opens a new tab in the context
+func (c IsolatedContext) CreatePageTarget(url string) (*Session, error) {
+	return c.CreatePageTargetWithOptions(url, TargetOptions{})
+}
+
+// CreatePageTargetWithOptions opens a new tab in the context, options.BrowserContextID is ignored
+func (c IsolatedContext) CreatePageTargetWithOptions(url string, options TargetOptions) (*Session, error) {
+	options.BrowserContextID = c.ID
+	return c.browser.CreatePageTargetWithOptions(url, options)
+}
+
+// Targets of the context
+func (c IsolatedContext) Targets() ([]*target.TargetInfo, error) {
+	all, err := c.browser.GetTargets()
+	if err != nil {
+		return nil, err
+	}
+	var list []*target.TargetInfo
+	for _, t := range all {
+		if t.BrowserContextId == c.ID {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+// Dispose closes all tabs of the context and deletes its data
+func (c IsolatedContext) Dispose() error {
+	return target.DisposeBrowserContext(c.browser, target.DisposeBrowserContextArgs{BrowserContextId: c.ID})
+}
@@ -0,0 +1,192 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ecwid/control/protocol/input"
+)
+
+// namedKeys non-printable keys by their KeyboardEvent.key names
+var namedKeys = map[string]KeyDefinition{
+	"Shift":      {KeyCode: 16, Key: "Shift", Code: "ShiftLeft", Location: 1},
+	"Control":    {KeyCode: 17, Key: "Control", Code: "ControlLeft", Location: 1},
+	"Alt":        {KeyCode: 18, Key: "Alt", Code: "AltLeft", Location: 1},
+	"Meta":       {KeyCode: 91, Key: "Meta", Code: "MetaLeft", Location: 1},
+	"Enter":      {KeyCode: 13, Key: "Enter", Code: "Enter", Text: "\r"},
+	"Tab":        {KeyCode: 9, Key: "Tab", Code: "Tab"},
+	"Backspace":  {KeyCode: 8, Key: "Backspace", Code: "Backspace"},
+	"Escape":     {KeyCode: 27, Key: "Escape", Code: "Escape"},
+	"Delete":     {KeyCode: 46, Key: "Delete", Code: "Delete"},
+	"Insert":     {KeyCode: 45, Key: "Insert", Code: "Insert"},
+	"Home":       {KeyCode: 36, Key: "Home", Code: "Home"},
+	"End":        {KeyCode: 35, Key: "End", Code: "End"},
+	"PageUp":     {KeyCode: 33, Key: "PageUp", Code: "PageUp"},
+	"PageDown":   {KeyCode: 34, Key: "PageDown", Code: "PageDown"},
+	"ArrowLeft":  {KeyCode: 37, Key: "ArrowLeft", Code: "ArrowLeft"},
+	"ArrowUp":    {KeyCode: 38, Key: "ArrowUp", Code: "ArrowUp"},
+	"ArrowRight": {KeyCode: 39, Key: "ArrowRight", Code: "ArrowRight"},
+	"ArrowDown":  {KeyCode: 40, Key: "ArrowDown", Code: "ArrowDown"},
+	"Space":      {KeyCode: 32, Key: " ", Code: "Space"},
+	"F1":         {KeyCode: 112, Key: "F1", Code: "F1"},
+	"F2":         {KeyCode: 113, Key: "F2", Code: "F2"},
+	"F3":         {KeyCode: 114, Key: "F3", Code: "F3"},
+	"F4":         {KeyCode: 115, Key: "F4", Code: "F4"},
+	"F5":         {KeyCode: 116, Key: "F5", Code: "F5"},
+	"F6":         {KeyCode: 117, Key: "F6", Code: "F6"},
+	"F7":         {KeyCode: 118, Key: "F7", Code: "F7"},
+	"F8":         {KeyCode: 119, Key: "F8", Code: "F8"},
+	"F9":         {KeyCode: 120, Key: "F9", Code: "F9"},
+	"F10":        {KeyCode: 121, Key: "F10", Code: "F10"},
+	"F11":        {KeyCode: 122, Key: "F11", Code: "F11"},
+	"F12":        {KeyCode: 123, Key: "F12", Code: "F12"},
+}
+
+var keyAliases = map[string]string{
+	"Ctrl":    "Control",
+	"Cmd":     "Meta",
+	"Command": "Meta",
+	"Option":  "Alt",
+	"Esc":     "Escape",
+	"Return":  "Enter",
+	"Left":    "ArrowLeft",
+	"Up":      "ArrowUp",
+	"Right":   "ArrowRight",
+	"Down":    "ArrowDown",
+}
+
+var modifierKeys = map[string]int{
+	"Alt":     ModifierAlt,
+	"Control": ModifierCtrl,
+	"Meta":    ModifierMeta,
+	"Shift":   ModifierShift,
+}
+
+// Keyboard key-level keyboard: keys are held between Down and Up, held modifiers apply to Mouse clicks too (e.g. Shift+Click).
+// Keys are KeyboardEvent.key names ("Enter", "ArrowLeft", "Control") or single characters
+type Keyboard struct {
+	input Input
+}
+
+func (s Session) Keyboard() Keyboard {
+	return Keyboard{input: s.Input}
+}
+
+func keyDefinition(name string) (KeyDefinition, error) {
+	if alias, ok := keyAliases[name]; ok {
+		name = alias
+	}
+	if key, ok := namedKeys[name]; ok {
+		return key, nil
+	}
+	if r := []rune(name); len(r) == 1 {
+		if key, ok := keyDefinitions[r[0]]; ok {
+			return key, nil
+		}
+		return KeyDefinition{Key: name, Text: name}, nil // not on US keyboard, typed as is
+	}
+	return KeyDefinition{}, fmt.Errorf("unknown key `%s`", name)
+}
+
+// Down presses the key and holds it until Up
+func (k Keyboard) Down(name string) error {
+	key, err := keyDefinition(name)
+	if err != nil {
+		return err
+	}
+	state := k.input.state
+	state.mx.Lock()
+	state.keys[key.Key] = true
+	state.mx.Unlock()
+	var (
+		modifiers = state.modifiers()
+		text      = key.Text
+	)
+	if text == "" && len([]rune(key.Key)) == 1 {
+		text = key.Key
+	}
+	if modifiers&ModifierShift != 0 {
+		text = strings.ToUpper(text)
+	}
+	args := input.DispatchKeyEventArgs{
+		Type:                  dispatchKeyEventKeyDown,
+		Modifiers:             modifiers,
+		Key:                   key.Key,
+		Code:                  key.Code,
+		WindowsVirtualKeyCode: key.KeyCode,
+		Location:              key.Location,
+		Text:                  text,
+	}
+	if modifiers&(ModifierCtrl|ModifierAlt|ModifierMeta) != 0 || text == "" {
+		// a chord or a non-printable key doesn't insert text, editing commands give the native behaviour of chords
+		args.Type, args.Text = "rawKeyDown", ""
+		args.Commands = chordCommands(key.Key, modifiers)
+	}
+	return input.DispatchKeyEvent(k.input.s, args)
+}
+
+// Up releases the key
+func (k Keyboard) Up(name string) error {
+	key, err := keyDefinition(name)
+	if err != nil {
+		return err
+	}
+	state := k.input.state
+	state.mx.Lock()
+	delete(state.keys, key.Key)
+	state.mx.Unlock()
+	return input.DispatchKeyEvent(k.input.s, input.DispatchKeyEventArgs{
+		Type:                  dispatchKeyEventKeyUp,
+		Modifiers:             state.modifiers(),
+		Key:                   key.Key,
+		Code:                  key.Code,
+		WindowsVirtualKeyCode: key.KeyCode,
+		Location:              key.Location,
+	})
+}
+
+// Press presses a key or a chord of keys joined with "+" (e.g. "Control+A", "Shift+ArrowLeft", "Meta+Shift+Z"),
+// keys are pressed in order and released in reverse order
+func (k Keyboard) Press(chord string) error {
+	keys := splitChord(chord)
+	for n, key := range keys {
+		if err := k.Down(key); err != nil {
+			for m := n - 1; m >= 0; m-- {
+				_ = k.Up(keys[m])
+			}
+			return err
+		}
+	}
+	for n := len(keys) - 1; n >= 0; n-- {
+		if err := k.Up(keys[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitChord(chord string) []string {
+	if strings.HasSuffix(chord, "+") { // the plus key itself
+		head := strings.TrimSuffix(strings.TrimSuffix(chord, "+"), "+")
+		if head == "" {
+			return []string{"+"}
+		}
+		return append(strings.Split(head, "+"), "+")
+	}
+	return strings.Split(chord, "+")
+}
+
+// chordCommands editing commands of the chord (see Shortcut), the OS doesn't handle synthetic keys
+func chordCommands(key string, modifiers int) []string {
+	if modifiers&(ModifierCtrl|ModifierMeta) == 0 {
+		return nil
+	}
+	for _, list := range []map[Shortcut]chord{macShortcuts, shortcuts} {
+		for _, c := range list {
+			if c.command != "" && strings.EqualFold(string(c.key), key) && c.shift == (modifiers&ModifierShift != 0) {
+				return []string{c.command}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package control
+
+import "fmt"
+
+var functionLayoutShifts = fmt.Sprintf(`new Promise(r=>{const sel=%s,rect=x=>({x:x.x,y:x.y,width:x.width,height:x.height}),l=[];
+try{const o=new PerformanceObserver(e=>e.getEntries().forEach(e=>l.push({value:e.value,startTime:e.startTime,hadRecentInput:e.hadRecentInput,
+sources:(e.sources||[]).map(s=>({selector:s.node&&s.node.nodeType===1?sel(s.node):(s.node&&s.node.parentElement?sel(s.node.parentElement):""),previousRect:rect(s.previousRect),currentRect:rect(s.currentRect)}))})));
+o.observe({type:"layout-shift",buffered:!0});setTimeout(()=>{o.disconnect();r(l)},100)}catch(e){r(l)}})`, atomSelectorOf)
+
+// LayoutShift entry of Layout Instability API
+type LayoutShift struct {
+	Value          float64       `json:"value"`
+	StartTime      float64       `json:"startTime"` // ms
+	HadRecentInput bool          `json:"hadRecentInput"`
+	Sources        []ShiftSource `json:"sources"`
+}
+
+// ShiftSource element moved by a layout shift
+type ShiftSource struct {
+	Selector     string `json:"selector"` // empty if the node is removed from the document
+	PreviousRect Rect   `json:"previousRect"`
+	CurrentRect  Rect   `json:"currentRect"`
+}
+
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// LayoutShifts CLS of the document with the shifts it consists of
+type LayoutShifts struct {
+	CLS    float64       // the largest session window of shifts without recent input
+	Shifts []LayoutShift // all shifts in order of occurrence
+}
+
+// Culprits selectors of elements which contributed to CLS, by contributed value
+func (l LayoutShifts) Culprits() map[string]float64 {
+	m := map[string]float64{}
+	for _, s := range l.Shifts {
+		if s.HadRecentInput {
+			continue
+		}
+		for _, src := range s.Sources {
+			m[src.Selector] += s.Value / float64(len(s.Sources))
+		}
+	}
+	return m
+}
+
+// LayoutShifts reports layout shifts of the document and identifies the shifting elements
+func (f Frame) LayoutShifts() (*LayoutShifts, error) {
+	var shifts []LayoutShift
+	if err := f.evaluateValue(functionLayoutShifts, true, &shifts); err != nil {
+		return nil, err
+	}
+	return &LayoutShifts{CLS: cumulativeLayoutShift(shifts), Shifts: shifts}, nil
+}
+
+// cumulativeLayoutShift session window algorithm: shifts less than 1s apart are grouped in windows up to 5s long,
+// the score is the largest window, see https://web.dev/cls/
+func cumulativeLayoutShift(shifts []LayoutShift) float64 {
+	var (
+		max, current       float64
+		windowStart, prior float64
+	)
+	started := false
+	for _, s := range shifts {
+		if s.HadRecentInput {
+			continue
+		}
+		if !started || s.StartTime-prior >= 1000 || s.StartTime-windowStart >= 5000 {
+			windowStart, current, started = s.StartTime, 0, true
+		}
+		current += s.Value
+		prior = s.StartTime
+		if current > max {
+			max = current
+		}
+	}
+	return max
+}
@@ -0,0 +1,104 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scriptLongTasks reports longtask entries (and long animation frames with script attribution) to the binding %[1]s,
+// the observers are kept in window["__control_"+binding] to be disconnected by scriptLongTasksStop
+const scriptLongTasks = `(()=>{const b=%[1]q,k="__control_"+b;if(window[k])return;const o=window[k]=[];
+const send=v=>{try{window[b](JSON.stringify(v))}catch(e){}};
+try{const p=new PerformanceObserver(l=>l.getEntries().forEach(e=>send({name:e.name,startTime:e.startTime,duration:e.duration,
+attribution:(e.attribution||[]).map(a=>({containerType:a.containerType,containerSrc:a.containerSrc,containerId:a.containerId,containerName:a.containerName}))})));p.observe({type:"longtask",buffered:!0});o.push(p)}catch(e){}
+try{const p=new PerformanceObserver(l=>l.getEntries().forEach(e=>e.blockingDuration>0&&send({name:"long-animation-frame",startTime:e.startTime,duration:e.duration,
+scripts:(e.scripts||[]).map(s=>({invoker:s.invoker,sourceURL:s.sourceURL,sourceFunctionName:s.sourceFunctionName,duration:s.duration}))})));p.observe({type:"long-animation-frame"});o.push(p)}catch(e){}})()`
+
+// scriptLongTasksStop disconnects observers of scriptLongTasks with the binding %[1]s and removes the binding function
+const scriptLongTasksStop = `(()=>{const b=%[1]q,k="__control_"+b;(window[k]||[]).forEach(o=>o.disconnect());delete window[k];delete window[b]})()`
+
+var longTaskBindings uint64
+
+// LongTaskError long tasks happened during an interaction guarded by AssertNoLongTasks
+type LongTaskError struct {
+	Limit time.Duration
+	Tasks []LongTask
+}
+
+func (e LongTaskError) Error() string {
+	return fmt.Sprintf("%d long task(s) over %s, the longest is %.0fms", len(e.Tasks), e.Limit, e.Tasks[0].Duration)
+}
+
+// ObserveLongTasks reports main thread tasks longer than 50ms with container attribution.
+// Where the browser supports long animation frames, frames blocked by scripts are reported as well with the scripts' attribution
+func (s Session) ObserveLongTasks(handler func(LongTask)) (cancel func(), err error) {
+	name := fmt.Sprintf("_on_longtask_%d", atomic.AddUint64(&longTaskBindings, 1))
+	removeBinding, err := s.AddBinding(name, func(payload string) {
+		var task LongTask
+		if json.Unmarshal([]byte(payload), &task) == nil {
+			handler(task)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	script := fmt.Sprintf(scriptLongTasks, name)
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(script)
+	if err != nil {
+		removeBinding()
+		return nil, err
+	}
+	_, _ = s.Page().Evaluate(script, false, false)
+	return func() {
+		_ = s.RemoveScriptToEvaluateOnNewDocument(identifier)
+		_, _ = s.Page().Evaluate(fmt.Sprintf(scriptLongTasksStop, name), false, false)
+		removeBinding()
+	}, nil
+}
+
+// AssertNoLongTasks runs action and returns LongTaskError if the page had tasks longer than limit meanwhile,
+// e.g. to guard interactivity budget of opening a menu
+func (s Session) AssertNoLongTasks(limit time.Duration, action func() error) error {
+	var (
+		mx    sync.Mutex
+		tasks []LongTask
+		start float64
+	)
+	if err := s.Page().evaluateValue(`performance.now()`, false, &start); err != nil {
+		return err
+	}
+	cancel, err := s.ObserveLongTasks(func(task LongTask) {
+		if task.StartTime+task.Duration < start || task.Duration < float64(limit.Milliseconds()) {
+			return // buffered entries of the past or short enough
+		}
+		mx.Lock()
+		tasks = append(tasks, task)
+		mx.Unlock()
+	})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	if err = action(); err != nil {
+		return err
+	}
+	// let observers deliver the entries of the last task
+	_, _ = s.Page().Evaluate(`new Promise(r=>setTimeout(r,100))`, true, false)
+	time.Sleep(time.Millisecond * 50)
+	mx.Lock()
+	defer mx.Unlock()
+	if len(tasks) > 0 {
+		longest := 0
+		for n := range tasks {
+			if tasks[n].Duration > tasks[longest].Duration {
+				longest = n
+			}
+		}
+		tasks[0], tasks[longest] = tasks[longest], tasks[0]
+		return LongTaskError{Limit: limit, Tasks: tasks}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Mailpit https://mailpit.axllent.org API client
+type Mailpit struct {
+	URL    string // e.g. http://localhost:8025
+	Client *http.Client
+}
+
+func (p Mailpit) Latest(ctx context.Context, address string) (*Message, error) {
+	var list struct {
+		Messages []struct {
+			ID string
+		}
+	}
+	u := strings.TrimRight(p.URL, "/") + "/api/v1/search?limit=1&query=" + url.QueryEscape("to:"+address)
+	if err := getJSON(ctx, p.Client, u, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Messages) == 0 {
+		return nil, nil
+	}
+	var v struct {
+		ID      string
+		From    struct{ Address string }
+		To      []struct{ Address string }
+		Subject string
+		Date    time.Time
+		Text    string
+		HTML    string
+	}
+	if err := getJSON(ctx, p.Client, strings.TrimRight(p.URL, "/")+"/api/v1/message/"+url.PathEscape(list.Messages[0].ID), &v); err != nil {
+		return nil, err
+	}
+	m := &Message{ID: v.ID, From: v.From.Address, Subject: v.Subject, Date: v.Date, Text: v.Text, HTML: v.HTML}
+	for _, to := range v.To {
+		m.To = append(m.To, to.Address)
+	}
+	return m, nil
+}
+
+// MailHog https://github.com/mailhog/MailHog API client
+type MailHog struct {
+	URL    string // e.g. http://localhost:8025
+	Client *http.Client
+}
+
+func (h MailHog) Latest(ctx context.Context, address string) (*Message, error) {
+	var list struct {
+		Items []struct {
+			ID  string
+			Raw struct{ Data string }
+		}
+	}
+	u := strings.TrimRight(h.URL, "/") + "/api/v2/search?kind=to&limit=1&query=" + url.QueryEscape(address)
+	if err := getJSON(ctx, h.Client, u, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	m, err := Parse(strings.NewReader(list.Items[0].Raw.Data))
+	if err != nil {
+		return nil, err
+	}
+	m.ID = list.Items[0].ID
+	return m, nil
+}
+
+// Parse reads a raw RFC 5322 message, text and HTML parts are decoded
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		decoder = new(mime.WordDecoder)
+		m       = &Message{}
+	)
+	m.Subject, err = decoder.DecodeHeader(raw.Header.Get("Subject"))
+	if err != nil {
+		m.Subject = raw.Header.Get("Subject")
+	}
+	if from, err := mail.ParseAddress(raw.Header.Get("From")); err == nil {
+		m.From = from.Address
+	}
+	if to, err := raw.Header.AddressList("To"); err == nil {
+		for _, a := range to {
+			m.To = append(m.To, a.Address)
+		}
+	}
+	m.Date, _ = raw.Header.Date()
+	err = m.readPart(raw.Header.Get("Content-Type"), raw.Header.Get("Content-Transfer-Encoding"), raw.Body, 0)
+	return m, err
+}
+
+func (m *Message) readPart(contentType, encoding string, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body) // line breaks are skipped
+	}
+	if strings.HasPrefix(mediaType, "multipart/") && depth < 8 {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = m.readPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	switch mediaType {
+	case "text/plain":
+		if m.Text == "" {
+			m.Text = string(b)
+		}
+	case "text/html":
+		if m.HTML == "" {
+			m.HTML = string(b)
+		}
+	}
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GET %s: %s %s", u, resp.Status, bytes.TrimSpace(b))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
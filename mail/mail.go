@@ -0,0 +1,112 @@
+// Package mail fetches emails sent by the application under test from a local SMTP catcher (Mailpit, MailHog)
+// and extracts links and codes, so signup and 2FA flows which bounce through email can be automated
+package mail
+
+import (
+	"context"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control/internal/wildcard"
+)
+
+var ErrNoLink = errors.New("no matching link in the message")
+
+// Message email as received by the catcher
+type Message struct {
+	ID      string
+	From    string
+	To      []string
+	Subject string
+	Date    time.Time
+	Text    string
+	HTML    string
+}
+
+// Mailbox source of messages, e.g. Mailpit or MailHog
+type Mailbox interface {
+	// Latest returns the newest message sent to the address, nil if there is none
+	Latest(ctx context.Context, address string) (*Message, error)
+}
+
+// Wait polls the mailbox until a message to the address newer than since arrives
+func Wait(ctx context.Context, box Mailbox, address string, since time.Time) (*Message, error) {
+	ticker := time.NewTicker(time.Millisecond * 500)
+	defer ticker.Stop()
+	for {
+		m, err := box.Latest(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		// catchers store seconds only
+		if m != nil && !m.Date.Before(since.Truncate(time.Second)) {
+			return m, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+var (
+	hrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+	urlPattern  = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+)
+
+// Links URLs of the message in order of appearance: hrefs of HTML part, then URLs of text part
+func (m Message) Links() []string {
+	var (
+		list []string
+		seen = map[string]bool{}
+	)
+	add := func(u string) {
+		u = strings.TrimRight(html.UnescapeString(u), ".,;:!?")
+		if !seen[u] && (strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")) {
+			seen[u] = true
+			list = append(list, u)
+		}
+	}
+	for _, match := range hrefPattern.FindAllStringSubmatch(m.HTML, -1) {
+		add(match[1])
+	}
+	for _, u := range urlPattern.FindAllString(m.Text, -1) {
+		add(u)
+	}
+	return list
+}
+
+// Link the first link matching the wildcard pattern ('*' -> zero or more, '?' -> exactly one), e.g. "*/confirm?token=*"
+func (m Message) Link(pattern string) (string, error) {
+	for _, u := range m.Links() {
+		if wildcard.Match(pattern, u) {
+			return u, nil
+		}
+	}
+	return "", ErrNoLink
+}
+
+var (
+	tagPattern    = regexp.MustCompile(`(?s)<style.*?</style>|<[^>]*>`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// Code the first standalone number of the given length in the text (or stripped HTML), e.g. a 6-digit verification code.
+// Empty if there is none
+func (m Message) Code(digits int) string {
+	text := m.Text
+	if strings.TrimSpace(text) == "" {
+		text = html.UnescapeString(tagPattern.ReplaceAllString(m.HTML, " "))
+	}
+	numbers := append(numberPattern.FindAllString(m.Subject, -1), numberPattern.FindAllString(text, -1)...)
+	for _, n := range numbers {
+		if len(n) == digits {
+			return n
+		}
+	}
+	return ""
+}
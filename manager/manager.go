@@ -0,0 +1,279 @@
+// Package manager runs sessions on several browsers: new sessions go to the least loaded browser,
+// crashed browsers are released and allocated again
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/allocator"
+	"github.com/ecwid/control/chrome"
+	"github.com/ecwid/control/protocol/browser"
+	"github.com/ecwid/control/transport"
+)
+
+var (
+	ErrNoBrowser = errors.New("no healthy browser has a free session slot")
+	ErrClosed    = errors.New("manager is closed")
+)
+
+// Spec of a managed browser: launched, remote or provided by an allocator
+type Spec struct {
+	Launch    *chrome.LaunchOptions
+	Remote    string // DevTools websocket URL of a running browser
+	Allocator allocator.Allocator
+}
+
+func (s Spec) allocator() allocator.Allocator {
+	switch {
+	case s.Allocator != nil:
+		return s.Allocator
+	case s.Launch != nil:
+		return allocator.Exec{Options: *s.Launch}
+	}
+	return allocator.Remote{URL: s.Remote}
+}
+
+// Options of the manager
+type Options struct {
+	Browsers      []Spec
+	MaxSessions   int                        // per browser, zero - unlimited
+	CheckInterval time.Duration              // how often browsers are pinged, zero or negative - 10 seconds
+	RestartDelay  time.Duration              // delay between restart attempts, zero or negative - 1 second
+	OnRestart     func(index int, err error) // err is nil if the browser is up again
+}
+
+// Health of a browser
+type Health struct {
+	Index     int
+	Spec      Spec
+	Alive     bool
+	Sessions  int
+	Restarts  int
+	LastError error
+}
+
+type instance struct {
+	index     int
+	spec      Spec
+	lifecycle sync.Mutex // held while the browser is stopped and started again
+	mx        sync.Mutex
+	alloc     *allocator.Allocation
+	client    *transport.Client
+	browser   control.BrowserContext
+	alive     bool
+	restarts  int
+	lastErr   error
+}
+
+// Manager owns browsers described by Options
+type Manager struct {
+	options   Options
+	instances []*instance
+	ctx       context.Context
+	cancel    func()
+	wg        sync.WaitGroup
+}
+
+// New starts (connects to) all browsers. The manager runs until Close or until ctx is done
+func New(ctx context.Context, options Options) (*Manager, error) {
+	if len(options.Browsers) == 0 {
+		return nil, errors.New("no browsers specified")
+	}
+	if options.CheckInterval <= 0 {
+		options.CheckInterval = time.Second * 10
+	}
+	if options.RestartDelay <= 0 {
+		options.RestartDelay = time.Second
+	}
+	m := &Manager{options: options}
+	m.ctx, m.cancel = context.WithCancel(ctx)
+	for n, spec := range options.Browsers {
+		i := &instance{index: n, spec: spec}
+		if err := m.start(i); err != nil {
+			_ = m.Close()
+			return nil, fmt.Errorf("browser #%d: %w", n, err)
+		}
+		m.instances = append(m.instances, i)
+		m.wg.Add(1)
+		go m.watch(i)
+	}
+	return m, nil
+}
+
+// start allocates the browser of the instance
+func (m *Manager) start(i *instance) error {
+	alloc, err := i.spec.allocator().Allocate(m.ctx)
+	if err != nil {
+		return err
+	}
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.alloc, i.client, i.browser, i.alive, i.lastErr = alloc, alloc.Client, control.New(alloc.Client), true, nil
+	return nil
+}
+
+// stop releases the browser
+func (i *instance) stop() {
+	i.mx.Lock()
+	alloc := i.alloc
+	i.alive, i.alloc, i.client = false, nil, nil
+	i.mx.Unlock()
+	if alloc != nil {
+		_ = alloc.Release()
+	}
+}
+
+// watch restarts the browser when its connection is lost or it doesn't respond
+func (m *Manager) watch(i *instance) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.options.CheckInterval)
+	defer ticker.Stop()
+	for {
+		i.mx.Lock()
+		client := i.client
+		i.mx.Unlock()
+		var done <-chan struct{}
+		if client != nil {
+			done = client.Context().Done()
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-done:
+			m.restart(i, client, errors.New("connection to the browser is lost"))
+		case <-ticker.C:
+			if client == nil {
+				i.mx.Lock()
+				err := i.lastErr
+				i.mx.Unlock()
+				m.restart(i, client, err)
+				continue
+			}
+			if err := m.ping(client); err != nil {
+				m.restart(i, client, err)
+			}
+		}
+	}
+}
+
+// ping checks the browser answers within CheckInterval
+func (m *Manager) ping(client *transport.Client) error {
+	ctx, cancel := context.WithTimeout(m.ctx, m.options.CheckInterval)
+	defer cancel()
+	err := client.CallContext(ctx, "", "Browser.getVersion", nil, &browser.GetVersionVal{})
+	if m.ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// restart the browser unless it was already replaced by another client (e.g. recycled)
+func (m *Manager) restart(i *instance, client *transport.Client, cause error) {
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+	i.mx.Lock()
+	replaced := i.client != client
+	i.mx.Unlock()
+	if replaced {
+		return
+	}
+	i.stop()
+	i.mx.Lock()
+	i.lastErr = cause
+	i.restarts++
+	i.mx.Unlock()
+	for {
+		err := m.start(i)
+		if m.options.OnRestart != nil {
+			m.options.OnRestart(i.index, err)
+		}
+		if err == nil {
+			return
+		}
+		i.mx.Lock()
+		i.lastErr = err
+		i.mx.Unlock()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.options.RestartDelay):
+		}
+	}
+}
+
+// NewSession opens a tab on the least loaded healthy browser
+func (m *Manager) NewSession(url string) (*control.Session, error) {
+	if m.ctx.Err() != nil {
+		return nil, ErrClosed
+	}
+	var (
+		best *instance
+		load = -1
+	)
+	for _, i := range m.instances {
+		i.mx.Lock()
+		alive, b := i.alive, i.browser
+		i.mx.Unlock()
+		if !alive {
+			continue
+		}
+		n := len(b.Health())
+		if m.options.MaxSessions > 0 && n >= m.options.MaxSessions {
+			continue
+		}
+		if load == -1 || n < load {
+			best, load = i, n
+		}
+	}
+	if best == nil {
+		return nil, ErrNoBrowser
+	}
+	best.mx.Lock()
+	b := best.browser
+	best.mx.Unlock()
+	return b.CreatePageTarget(url)
+}
+
+// Browsers returns browser contexts of alive browsers
+func (m *Manager) Browsers() []control.BrowserContext {
+	var list []control.BrowserContext
+	for _, i := range m.instances {
+		i.mx.Lock()
+		if i.alive {
+			list = append(list, i.browser)
+		}
+		i.mx.Unlock()
+	}
+	return list
+}
+
+// Health of all browsers
+func (m *Manager) Health() []Health {
+	list := make([]Health, len(m.instances))
+	for n, i := range m.instances {
+		i.mx.Lock()
+		h := Health{Index: i.index, Spec: i.spec, Alive: i.alive, Restarts: i.restarts, LastError: i.lastErr}
+		b := i.browser
+		i.mx.Unlock()
+		if h.Alive {
+			h.Sessions = len(b.Health())
+		}
+		list[n] = h
+	}
+	return list
+}
+
+// Close stops watching and closes all browsers
+func (m *Manager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	for _, i := range m.instances {
+		i.stop()
+	}
+	return nil
+}
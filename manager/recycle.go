@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/protocol/storage"
+)
+
+// State cookies and localStorage of the default browser context.
+// Isolated contexts are disposed with the browser and are not part of the state
+type State = control.StorageState
+
+// Snapshot collects cookies of the browser and localStorage of origins open in its tabs.
+// sessionStorage belongs to tabs which don't survive a restart, so it's dropped
+func Snapshot(b control.BrowserContext) (*State, error) {
+	val, err := storage.GetCookies(b, storage.GetCookiesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	state := &State{Cookies: val.Cookies}
+	targets, err := b.GetPageTargets()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		session, err := b.AttachPageTarget(t.TargetId)
+		if err != nil {
+			continue // the tab is closed meanwhile
+		}
+		tab, err := session.StorageState()
+		_ = session.Detach()
+		if err != nil {
+			continue // the tab is closed or navigated meanwhile
+		}
+		for _, o := range tab.Origins {
+			if len(o.LocalStorage) > 0 && state.Origin(o.Origin) == nil {
+				state.Origins = append(state.Origins, &control.OriginStorage{Origin: o.Origin, LocalStorage: o.LocalStorage})
+			}
+		}
+	}
+	return state, nil
+}
+
+// Restore sets cookies and localStorage of the state. localStorage is written from a temporary tab
+// navigated to each origin, the navigation is fulfilled with an empty page and never reaches the site
+func Restore(b control.BrowserContext, state *State) error {
+	if len(state.Cookies) > 0 {
+		if err := storage.SetCookies(b, storage.SetCookiesArgs{Cookies: control.CookieParams(state.Cookies)}); err != nil {
+			return err
+		}
+	}
+	if len(state.Origins) == 0 {
+		return nil
+	}
+	session, err := b.CreatePageTargetWithOptions(control.Blank, control.TargetOptions{Background: true})
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return session.RestoreStorageState(&State{Origins: state.Origins})
+}
+
+// Recycle restarts the browser to free memory leaked by long runs, cookies and localStorage survive the restart.
+// Sessions of the browser are closed, callers open new ones with NewSession
+func (m *Manager) Recycle(index int) error {
+	if index < 0 || index >= len(m.instances) {
+		return fmt.Errorf("no browser #%d", index)
+	}
+	i := m.instances[index]
+	i.lifecycle.Lock()
+	defer i.lifecycle.Unlock()
+	i.mx.Lock()
+	alive, b, lastErr := i.alive, i.browser, i.lastErr
+	i.mx.Unlock()
+	if !alive {
+		return fmt.Errorf("browser #%d is down: %v", index, lastErr)
+	}
+	state, err := Snapshot(b)
+	if err != nil {
+		return err
+	}
+	i.stop()
+	if err = m.start(i); err != nil {
+		i.mx.Lock()
+		i.lastErr = err
+		i.mx.Unlock()
+		return err // the watcher keeps restarting it, the state is lost
+	}
+	i.mx.Lock()
+	b = i.browser
+	i.mx.Unlock()
+	return Restore(b, state)
+}
@@ -0,0 +1,59 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ecwid/control/protocol/page"
+)
+
+var ErrNoManifest = errors.New("page has no web app manifest")
+
+// AppManifest web app manifest of the page and installability check results
+type AppManifest struct {
+	URL                  string
+	Data                 string // manifest as served
+	Errors               []*page.AppManifestError
+	InstallabilityErrors []*page.InstallabilityError
+}
+
+// AppManifest https://chromedevtools.github.io/devtools-protocol/tot/Page/#method-getAppManifest
+func (s Session) AppManifest() (*AppManifest, error) {
+	val, err := page.GetAppManifest(s)
+	if err != nil {
+		return nil, err
+	}
+	if val.Url == "" {
+		return nil, ErrNoManifest
+	}
+	installability, err := page.GetInstallabilityErrors(s)
+	if err != nil {
+		return nil, err
+	}
+	return &AppManifest{
+		URL:                  val.Url,
+		Data:                 val.Data,
+		Errors:               val.Errors,
+		InstallabilityErrors: installability.InstallabilityErrors,
+	}, nil
+}
+
+// Parsed is true if manifest has no critical parsing errors
+func (m AppManifest) Parsed() bool {
+	for _, e := range m.Errors {
+		if e.Critical != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Installable is true if browser doesn't see anything preventing the app installation
+func (m AppManifest) Installable() bool {
+	return m.Parsed() && len(m.InstallabilityErrors) == 0
+}
+
+// Decode unmarshals manifest JSON to v
+func (m AppManifest) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(m.Data), v)
+}
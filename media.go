@@ -0,0 +1,127 @@
+package control
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrNotMedia = errors.New("element is not an audio or video")
+
+// HTMLMediaElement.readyState values
+const (
+	MediaHaveNothing = iota
+	MediaHaveMetadata
+	MediaHaveCurrentData
+	MediaHaveFutureData
+	MediaHaveEnoughData
+)
+
+const (
+	functionMediaPlay        = `function(){return this.play()}`
+	functionMediaPause       = `function(){this.pause()}`
+	functionMediaSeek        = `function(t){return new Promise(r=>{this.addEventListener("seeked",()=>r(),{once:!0});this.currentTime=t})}`
+	functionMediaState       = `function(){const d=this.duration;return {currentTime:this.currentTime,duration:isFinite(d)?d:(isNaN(d)?0:-1),readyState:this.readyState,paused:this.paused,ended:this.ended}}`
+	functionMediaCanPlayThru = `function(t){return new Promise(r=>{if(this.readyState>=4)return r(!0);this.addEventListener("canplaythrough",()=>r(!0),{once:!0});setTimeout(()=>r(!1),t)})}`
+)
+
+// Media controls of <audio> and <video> elements
+type Media struct {
+	element Element
+}
+
+// MediaState snapshot of the media element's playback
+type MediaState struct {
+	CurrentTime float64 `json:"currentTime"` // seconds
+	Duration    float64 `json:"duration"`    // seconds, 0 if unknown yet, -1 for live streams
+	ReadyState  int     `json:"readyState"`
+	Paused      bool    `json:"paused"`
+	Ended       bool    `json:"ended"`
+}
+
+// Media returns playback controls if the element is <audio> or <video>
+func (e Element) Media() (*Media, error) {
+	switch e.node.NodeName {
+	case "AUDIO", "VIDEO":
+		return &Media{element: e}, nil
+	}
+	return nil, ErrNotMedia
+}
+
+// Play starts playback, an error is returned if the browser rejects it (e.g. by autoplay policy)
+func (m Media) Play() error {
+	_, err := m.element.CallFunction(functionMediaPlay, true, false, nil)
+	return err
+}
+
+func (m Media) Pause() error {
+	_, err := m.element.CallFunction(functionMediaPause, true, false, nil)
+	return err
+}
+
+// Seek sets playback position and waits for seeked event
+func (m Media) Seek(position time.Duration) error {
+	_, err := m.element.CallFunction(functionMediaSeek, true, false, NewSingleCallArgument(position.Seconds()))
+	return err
+}
+
+func (m Media) State() (*MediaState, error) {
+	val, err := m.element.CallFunction(functionMediaState, true, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	state := &MediaState{}
+	if err = primitiveRemoteObject(*val).Decode(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (m Media) CurrentTime() (time.Duration, error) {
+	state, err := m.State()
+	if err != nil {
+		return 0, err
+	}
+	return seconds(state.CurrentTime), nil
+}
+
+// Duration of the media, 0 if metadata is not loaded yet, -1 for live streams
+func (m Media) Duration() (time.Duration, error) {
+	state, err := m.State()
+	if err != nil {
+		return 0, err
+	}
+	if state.Duration < 0 {
+		return -1, nil
+	}
+	return seconds(state.Duration), nil
+}
+
+// ReadyState one of MediaHave* values
+func (m Media) ReadyState() (int, error) {
+	state, err := m.State()
+	if err != nil {
+		return 0, err
+	}
+	return state.ReadyState, nil
+}
+
+// WaitCanPlayThrough waits until enough data is loaded to play the media to the end without buffering
+func (m Media) WaitCanPlayThrough(timeout time.Duration) error {
+	val, err := m.element.CallFunction(functionMediaCanPlayThru, true, false, NewSingleCallArgument(timeout.Milliseconds()))
+	if err != nil {
+		return err
+	}
+	ready, err := primitiveRemoteObject(*val).Bool()
+	if err != nil {
+		return err
+	}
+	if !ready {
+		return FutureTimeoutError{timeout: timeout}
+	}
+	return nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
@@ -0,0 +1,55 @@
+package control
+
+import (
+	"time"
+
+	"github.com/ecwid/control/protocol/performance"
+)
+
+// Metrics run-time metrics of the page as reported by Performance.getMetrics
+type Metrics struct {
+	Timestamp           time.Duration // monotonic time of the measurement
+	Documents           int
+	Frames              int
+	JSEventListeners    int
+	Nodes               int
+	LayoutCount         int
+	RecalcStyleCount    int
+	LayoutDuration      time.Duration // total since the page was opened
+	RecalcStyleDuration time.Duration
+	ScriptDuration      time.Duration
+	TaskDuration        time.Duration
+	JSHeapUsedSize      int64 // bytes
+	JSHeapTotalSize     int64
+	All                 map[string]float64 // every metric as reported, including ones not mapped to fields
+}
+
+// Metrics returns current run-time metrics, durations accumulate since the page was opened,
+// so the difference of two calls measures an action
+func (s Session) Metrics() (*Metrics, error) {
+	if err := s.holdDomain("Performance"); err != nil {
+		return nil, err
+	}
+	val, err := performance.GetMetrics(s)
+	if err != nil {
+		return nil, err
+	}
+	m := &Metrics{All: make(map[string]float64, len(val.Metrics))}
+	for _, v := range val.Metrics {
+		m.All[v.Name] = v.Value
+	}
+	m.Timestamp = seconds(m.All["Timestamp"])
+	m.Documents = int(m.All["Documents"])
+	m.Frames = int(m.All["Frames"])
+	m.JSEventListeners = int(m.All["JSEventListeners"])
+	m.Nodes = int(m.All["Nodes"])
+	m.LayoutCount = int(m.All["LayoutCount"])
+	m.RecalcStyleCount = int(m.All["RecalcStyleCount"])
+	m.LayoutDuration = seconds(m.All["LayoutDuration"])
+	m.RecalcStyleDuration = seconds(m.All["RecalcStyleDuration"])
+	m.ScriptDuration = seconds(m.All["ScriptDuration"])
+	m.TaskDuration = seconds(m.All["TaskDuration"])
+	m.JSHeapUsedSize = int64(m.All["JSHeapUsedSize"])
+	m.JSHeapTotalSize = int64(m.All["JSHeapTotalSize"])
+	return m, nil
+}
@@ -25,8 +25,10 @@ var (
 )
 
 var (
-	iphoneUA = "Mozilla/5.0 (iPhone; CPU iPhone OS 11_0 like Mac OS X) AppleWebKit/604.1.38 (KHTML, like Gecko) Version/11.0 Mobile/15A372 Safari/604.1"
-	ipadUA   = "Mozilla/5.0 (iPad; CPU OS 11_0 like Mac OS X) AppleWebKit/604.1.34 (KHTML, like Gecko) Version/11.0 Mobile/15A5341f Safari/604.1"
+	iphoneUA   = "Mozilla/5.0 (iPhone; CPU iPhone OS 11_0 like Mac OS X) AppleWebKit/604.1.38 (KHTML, like Gecko) Version/11.0 Mobile/15A372 Safari/604.1"
+	ipadUA     = "Mozilla/5.0 (iPad; CPU OS 11_0 like Mac OS X) AppleWebKit/604.1.34 (KHTML, like Gecko) Version/11.0 Mobile/15A5341f Safari/604.1"
+	iphone16UA = "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
+	ipad16UA   = "Mozilla/5.0 (iPad; CPU OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
 )
 
 // Predefined devices
@@ -124,4 +126,116 @@ var (
 		},
 		UserAgent: iphoneUA,
 	}
+
+	IPhone12 = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             390,
+			Height:            844,
+			DeviceScaleFactor: 3,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: iphone16UA,
+	}
+	IPhone13 = IPhone12
+	IPhone14 = IPhone12
+
+	IPhone14ProMax = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             430,
+			Height:            932,
+			DeviceScaleFactor: 3,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: iphone16UA,
+	}
+
+	IPhoneSE = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             375,
+			Height:            667,
+			DeviceScaleFactor: 2,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: iphone16UA,
+	}
+
+	Pixel5 = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             393,
+			Height:            851,
+			DeviceScaleFactor: 2.75,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Mobile Safari/537.36",
+	}
+
+	Pixel7 = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             412,
+			Height:            915,
+			DeviceScaleFactor: 2.625,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Mobile Safari/537.36",
+	}
+
+	GalaxyS9 = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             360,
+			Height:            740,
+			DeviceScaleFactor: 3,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: "Mozilla/5.0 (Linux; Android 8.0.0; SM-G960F Build/R16NW) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Mobile Safari/537.36",
+	}
+
+	IPadAir = &Device{
+		Metrics: emulation.SetDeviceMetricsOverrideArgs{
+			Width:             820,
+			Height:            1180,
+			DeviceScaleFactor: 2,
+			Mobile:            true,
+			ScreenOrientation: ScreenOrientationPortrait,
+		},
+		UserAgent: ipad16UA,
+	}
 )
+
+// Devices catalog of predefined devices by name as in Chrome DevTools and Puppeteer
+var Devices = map[string]*Device{
+	"Galaxy S5":         GalaxyS5,
+	"Galaxy S9+":        GalaxyS9,
+	"Pixel 2":           Pixel2,
+	"Pixel 2 XL":        Pixel2XL,
+	"Pixel 5":           Pixel5,
+	"Pixel 7":           Pixel7,
+	"iPad":              IPad,
+	"iPad Mini":         IPadMini,
+	"iPad Pro":          IPadPro,
+	"iPad Air":          IPadAir,
+	"iPhone 6":          IPhone6,
+	"iPhone 7":          IPhone7,
+	"iPhone 8":          IPhone8,
+	"iPhone 6 Plus":     IPhone6Plus,
+	"iPhone 7 Plus":     IPhone7Plus,
+	"iPhone 8 Plus":     IPhone8Plus,
+	"iPhone X":          IPhoneX,
+	"iPhone SE":         IPhoneSE,
+	"iPhone 12":         IPhone12,
+	"iPhone 13":         IPhone13,
+	"iPhone 14":         IPhone14,
+	"iPhone 14 Pro Max": IPhone14ProMax,
+}
+
+// Landscape returns a copy of the device rotated to landscape
+func (d Device) Landscape() *Device {
+	d.Metrics.Width, d.Metrics.Height = d.Metrics.Height, d.Metrics.Width
+	d.Metrics.ScreenOrientation = ScreenOrientationLandscape
+	return &d
+}
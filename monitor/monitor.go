@@ -0,0 +1,98 @@
+// Package monitor runs scenarios on an interval and reports availability, latency and web vitals to sinks,
+// turning a browser into a synthetic uptime prober. Watcher reports content changes of pages
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+var ErrInterval = errors.New("monitor interval must be positive")
+
+// Scenario drives a fresh tab, a returned error (or panic) marks the run as failed
+type Scenario func(session *control.Session) error
+
+// Result of a single scenario run
+type Result struct {
+	Name     string             `json:"name"`
+	Start    time.Time          `json:"start"`
+	Duration time.Duration      `json:"duration"`
+	Success  bool               `json:"success"`
+	Error    string             `json:"error,omitempty"`
+	Vitals   *control.WebVitals `json:"vitals,omitempty"` // of the page the scenario finished on
+}
+
+// Sink receives results, e.g. Webhook or Pushgateway
+type Sink interface {
+	Push(Result) error
+}
+
+// Probe scenario with its schedule and sinks
+type Probe struct {
+	Name        string
+	Interval    time.Duration
+	Scenario    Scenario
+	Sinks       []Sink
+	OnSinkError func(error) // optional
+}
+
+// Run executes the scenario every Interval until ctx is done
+func (p Probe) Run(ctx context.Context, browser control.BrowserContext) error {
+	if p.Interval <= 0 {
+		return ErrInterval
+	}
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		p.push(p.Once(browser))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once runs the scenario in a new tab and closes the tab
+func (p Probe) Once(browser control.BrowserContext) (result Result) {
+	result = Result{Name: p.Name, Start: time.Now()}
+	session, err := browser.CreatePageTarget("")
+	if err != nil {
+		result.Duration = time.Since(result.Start)
+		result.Error = browser.RedactionPolicy().String(err.Error())
+		return result
+	}
+	defer func() { _ = session.Close() }()
+	err = p.run(session)
+	result.Duration = time.Since(result.Start)
+	result.Success = err == nil
+	if err != nil {
+		result.Error = browser.RedactionPolicy().String(err.Error())
+	}
+	if vitals, err := session.Page().WebVitals(); err == nil {
+		result.Vitals = vitals
+	}
+	return result
+}
+
+func (p Probe) run(session *control.Session) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return p.Scenario(session)
+}
+
+func (p Probe) push(r Result) {
+	for _, sink := range p.Sinks {
+		if err := sink.Push(r); err != nil && p.OnSinkError != nil {
+			p.OnSinkError(err)
+		}
+	}
+}
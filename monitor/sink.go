@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Webhook posts results (and changes of Watcher) as JSON
+type Webhook struct {
+	URL    string
+	Header http.Header
+	Client *http.Client // http.DefaultClient if nil
+}
+
+func (w Webhook) Push(r Result) error {
+	return w.post(r)
+}
+
+func (w Webhook) Notify(c Change) error {
+	return w.post(c)
+}
+
+func (w Webhook) post(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(w.Client, req)
+}
+
+// Pushgateway pushes results to Prometheus Pushgateway, the probe name is used as instance label
+type Pushgateway struct {
+	URL    string // e.g. http://pushgateway:9091
+	Job    string
+	Client *http.Client
+}
+
+func (p Pushgateway) Push(r Result) error {
+	b := &bytes.Buffer{}
+	success := 0
+	if r.Success {
+		success = 1
+	}
+	metric(b, "control_probe_success", "Whether the last run succeeded", float64(success))
+	metric(b, "control_probe_duration_seconds", "Duration of the last run", r.Duration.Seconds())
+	metric(b, "control_probe_last_run_timestamp_seconds", "Start time of the last run", float64(r.Start.Unix()))
+	if v := r.Vitals; v != nil {
+		metric(b, "control_probe_ttfb_seconds", "Time to first byte", v.TTFB.Seconds())
+		metric(b, "control_probe_fcp_seconds", "First contentful paint", v.FCP.Seconds())
+		metric(b, "control_probe_lcp_seconds", "Largest contentful paint", v.LCP.Seconds())
+		metric(b, "control_probe_cls", "Cumulative layout shift", v.CLS)
+	}
+	u := strings.TrimRight(p.URL, "/") + "/metrics/job/" + url.PathEscape(p.Job) + "/instance/" + url.PathEscape(r.Name)
+	req, err := http.NewRequest(http.MethodPut, u, b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	return do(p.Client, req)
+}
+
+func metric(w io.Writer, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL, resp.Status)
+	}
+	return nil
+}
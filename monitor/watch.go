@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+// Region part of the page compared by Watcher
+type Region struct {
+	Selector   string // all matching elements, empty - the whole body
+	Screenshot bool   // compare sha256 of the screenshot instead of the rendered text
+}
+
+func (r Region) String() string {
+	s := r.Selector
+	if s == "" {
+		s = "body"
+	}
+	if r.Screenshot {
+		s += " (screenshot)"
+	}
+	return s
+}
+
+// Change of a region detected by Watcher
+type Change struct {
+	Name   string    `json:"name"`
+	URL    string    `json:"url"`
+	Region string    `json:"region"`
+	Time   time.Time `json:"time"`
+	Before string    `json:"before"` // rendered text or hex sha256 of the screenshot
+	After  string    `json:"after"`
+	Diff   string    `json:"diff,omitempty"` // text regions only
+}
+
+// ChangeSink receives changes, e.g. Webhook or ChangeFunc
+type ChangeSink interface {
+	Notify(Change) error
+}
+
+// ChangeFunc callback as ChangeSink
+type ChangeFunc func(Change) error
+
+func (f ChangeFunc) Notify(c Change) error {
+	return f(c)
+}
+
+// Watcher re-visits URL on a schedule and notifies sinks when a region differs from the previous visit.
+// The first visit only remembers the state
+type Watcher struct {
+	Name     string
+	URL      string
+	Interval time.Duration
+	Timeout  time.Duration // of the navigation, zero - Interval (or a minute if Interval is zero too)
+	Regions  []Region      // empty - the rendered text of the whole body
+	Sinks    []ChangeSink
+	OnError  func(error) // optional, failed visits and sink errors
+
+	last map[Region]string
+}
+
+// Run visits the page every Interval until ctx is done
+func (w *Watcher) Run(ctx context.Context, browser control.BrowserContext) error {
+	if w.Interval <= 0 {
+		return ErrInterval
+	}
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Check(browser); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check visits the page once in a new tab, notifies sinks and returns the changes since the previous check
+func (w *Watcher) Check(browser control.BrowserContext) ([]Change, error) {
+	session, err := browser.CreatePageTarget("")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = session.Close() }()
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = w.Interval
+	}
+	if timeout <= 0 {
+		timeout = time.Minute // Check is called without Run
+	}
+	if err = session.Page().Navigate(w.URL, control.LifecycleLoad, timeout); err != nil {
+		return nil, err
+	}
+	regions := w.Regions
+	if len(regions) == 0 {
+		regions = []Region{{}}
+	}
+	if w.last == nil {
+		w.last = map[Region]string{}
+	}
+	var changes []Change
+	for _, r := range regions {
+		value, err := capture(session, r)
+		if err != nil {
+			return changes, err
+		}
+		before, seen := w.last[r]
+		w.last[r] = value
+		if !seen || before == value {
+			continue
+		}
+		c := Change{Name: w.Name, URL: w.URL, Region: r.String(), Time: time.Now(), Before: before, After: value}
+		if !r.Screenshot {
+			c.Diff = (&control.Snapshot{Text: before}).TextDiff(&control.Snapshot{Text: value}).String()
+		}
+		changes = append(changes, c)
+		for _, sink := range w.Sinks {
+			if err := sink.Notify(c); err != nil && w.OnError != nil {
+				w.OnError(err)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// capture rendered text or the screenshot hash of the region, an absent region is empty
+func capture(session *control.Session, r Region) (string, error) {
+	if r.Selector == "" && !r.Screenshot {
+		return session.Page().InnerText()
+	}
+	selector := r.Selector
+	if selector == "" {
+		selector = "body"
+	}
+	elements, err := session.Page().QuerySelectorAll(selector)
+	if err != nil {
+		return "", err
+	}
+	var (
+		texts []string
+		hash  = sha256.New()
+	)
+	for _, e := range elements {
+		if r.Screenshot {
+			b, err := e.Screenshot("png", 0)
+			if err != nil && err != control.ErrNodeIsNotVisible {
+				return "", err
+			}
+			hash.Write(b)
+			continue
+		}
+		text, err := e.GetText()
+		if err != nil {
+			return "", err
+		}
+		texts = append(texts, text)
+	}
+	if r.Screenshot {
+		if len(elements) == 0 {
+			return "", nil
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+	return strings.Join(texts, "\n"), nil
+}
@@ -0,0 +1,121 @@
+package control
+
+import (
+	"time"
+
+	"github.com/ecwid/control/protocol/input"
+)
+
+// Mouse coordinate-based mouse for canvas apps (maps, drawing tools, games) which have no DOM elements to query.
+// Coordinates are CSS pixels relative to the viewport
+type Mouse struct {
+	input Input
+}
+
+func (s Session) Mouse() Mouse {
+	return Mouse{input: s.Input}
+}
+
+// Position returns the last known position of the mouse
+func (m Mouse) Position() (x, y float64) {
+	return m.input.state.position()
+}
+
+func (m Mouse) pressed() input.MouseButton {
+	m.input.state.mx.Lock()
+	defer m.input.state.mx.Unlock()
+	if m.input.state.pressed == "" {
+		return MouseNone
+	}
+	return m.input.state.pressed
+}
+
+func (m Mouse) setPressed(button input.MouseButton) {
+	m.input.state.mx.Lock()
+	defer m.input.state.mx.Unlock()
+	m.input.state.pressed = button
+}
+
+// Move moves the mouse from the current position to (x, y) by steps intermediate mousemove events,
+// holding the pressed button if any
+func (m Mouse) Move(x, y float64, steps int) error {
+	if steps < 1 {
+		steps = 1
+	}
+	x0, y0 := m.Position()
+	button := m.pressed()
+	for n := 1; n <= steps; n++ {
+		t := float64(n) / float64(steps)
+		if err := m.input.mouseMoved(button, x0+(x-x0)*t, y0+(y-y0)*t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down presses the button at the current position
+func (m Mouse) Down(button input.MouseButton) error {
+	x, y := m.Position()
+	if err := m.input.MousePress(button, x, y); err != nil {
+		return err
+	}
+	m.setPressed(button)
+	return nil
+}
+
+// Up releases the button at the current position
+func (m Mouse) Up(button input.MouseButton) error {
+	x, y := m.Position()
+	m.setPressed(MouseNone)
+	return m.input.MouseRelease(button, x, y)
+}
+
+// Click clicks left button at (x, y)
+func (m Mouse) Click(x, y float64) error {
+	return m.input.Click(MouseLeft, x, y, time.Millisecond*10)
+}
+
+// Wheel scrolls by the deltas in CSS pixels at the current position, positive deltaY scrolls down
+func (m Mouse) Wheel(deltaX, deltaY float64) error {
+	x, y := m.Position()
+	return input.DispatchMouseEvent(m.input.s, input.DispatchMouseEventArgs{
+		Type:      "mouseWheel",
+		X:         x,
+		Y:         y,
+		DeltaX:    deltaX,
+		DeltaY:    deltaY,
+		Modifiers: m.input.state.modifiers(),
+	})
+}
+
+// Drag presses left button at (fromX, fromY), moves to (toX, toY) by steps mousemove events and releases the button
+func (m Mouse) Drag(fromX, fromY, toX, toY float64, steps int) error {
+	if err := m.input.mouseMoved(MouseNone, fromX, fromY); err != nil {
+		return err
+	}
+	if err := m.Down(MouseLeft); err != nil {
+		return err
+	}
+	if err := m.Move(toX, toY, steps); err != nil {
+		_ = m.Up(MouseLeft)
+		return err
+	}
+	return m.Up(MouseLeft)
+}
+
+// DragAndDrop drags the source element onto the middle of the target element, e.g. to reorder a list.
+// The intermediate mousemove events let drag libraries detect the gesture
+func (m Mouse) DragAndDrop(source, target *Element, steps int) error {
+	if err := source.ScrollIntoView(); err != nil {
+		return err
+	}
+	fromX, fromY, err := source.clickablePoint()
+	if err != nil {
+		return err
+	}
+	toX, toY, err := target.clickablePoint()
+	if err != nil {
+		return err
+	}
+	return m.Drag(fromX, fromY, toX, toY, steps)
+}
@@ -0,0 +1,136 @@
+package control
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/transport"
+)
+
+// SlowNavigation diagnostics of a navigation exceeded the soft budget
+type SlowNavigation struct {
+	URL             string
+	Elapsed         time.Duration
+	PendingRequests []PendingRequest
+	LongTasks       []LongTask // the longest tasks of the page so far
+}
+
+type PendingRequest struct {
+	URL     string
+	Type    network.ResourceType
+	Elapsed time.Duration
+}
+
+// LongTask entry of PerformanceObserver longtask API, times are in milliseconds
+type LongTask struct {
+	Name        string            `json:"name"`
+	StartTime   float64           `json:"startTime"`
+	Duration    float64           `json:"duration"`
+	Attribution []TaskAttribution `json:"attribution,omitempty"`
+	Scripts     []TaskScript      `json:"scripts,omitempty"` // if the browser supports long animation frames API
+}
+
+// TaskAttribution frame container the long task is attributed to
+type TaskAttribution struct {
+	ContainerType string `json:"containerType"` // window, iframe, embed or object
+	ContainerSrc  string `json:"containerSrc"`
+	ContainerID   string `json:"containerId"`
+	ContainerName string `json:"containerName"`
+}
+
+// TaskScript script which contributed to a long animation frame
+type TaskScript struct {
+	Invoker      string  `json:"invoker"` // e.g. "BUTTON#submit.onclick"
+	SourceURL    string  `json:"sourceURL"`
+	FunctionName string  `json:"sourceFunctionName"`
+	Duration     float64 `json:"duration"`
+}
+
+const functionBufferedLongTasks = `new Promise(r=>{try{const o=new PerformanceObserver(l=>{o.disconnect();r(l.getEntries().map(e=>({name:e.name,startTime:e.startTime,duration:e.duration})))});o.observe({type:'longtask',buffered:!0});setTimeout(()=>{o.disconnect();r([])},100)}catch(e){r([])}})`
+
+// SetNavigationBudget makes Navigate call the hook when navigation takes longer than soft budget (but before timeout),
+// so chronic slow pages become visible before they become hard timeouts. Zero budget disables diagnostics
+func (s Session) SetNavigationBudget(soft time.Duration, hook func(SlowNavigation)) {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	s.config.navigationBudget = soft
+	s.config.onSlowNavigation = hook
+}
+
+// watchNavigation tracks requests of the navigation and reports them if the budget is exceeded
+func (f Frame) watchNavigation(url string) (stop func()) {
+	s := f.session
+	s.config.mx.Lock()
+	budget, hook := s.config.navigationBudget, s.config.onSlowNavigation
+	s.config.mx.Unlock()
+	if budget <= 0 || hook == nil {
+		return func() {}
+	}
+	release, err := s.EnableDomain("Network")
+	if err != nil {
+		return func() {}
+	}
+	var (
+		mx      sync.Mutex
+		hookMx  sync.Mutex
+		stopped bool // set under hookMx by stop, so the hook isn't called after Navigate has returned
+		start   = time.Now()
+		pending = map[network.RequestId]PendingRequest{}
+		started = map[network.RequestId]time.Time{}
+	)
+	cancel := s.Subscribe("*", func(e transport.Event) error {
+		switch e.Method {
+		case "Network.requestWillBeSent":
+			var v = network.RequestWillBeSent{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				return err
+			}
+			mx.Lock()
+			pending[v.RequestId] = PendingRequest{URL: v.Request.Url, Type: v.Type}
+			started[v.RequestId] = time.Now()
+			mx.Unlock()
+		case "Network.loadingFinished", "Network.loadingFailed":
+			var v = network.LoadingFinished{} // requestId is the only field needed
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				return err
+			}
+			mx.Lock()
+			delete(pending, v.RequestId)
+			delete(started, v.RequestId)
+			mx.Unlock()
+		}
+		return nil
+	})
+	timer := time.AfterFunc(budget, func() {
+		report := SlowNavigation{URL: url, Elapsed: time.Since(start)}
+		mx.Lock()
+		for id, r := range pending {
+			r.Elapsed = time.Since(started[id])
+			report.PendingRequests = append(report.PendingRequests, r)
+		}
+		mx.Unlock()
+		_ = f.evaluateValue(functionBufferedLongTasks, true, &report.LongTasks)
+		sort.Slice(report.LongTasks, func(i, j int) bool {
+			return report.LongTasks[i].Duration > report.LongTasks[j].Duration
+		})
+		if len(report.LongTasks) > 5 {
+			report.LongTasks = report.LongTasks[:5]
+		}
+		hookMx.Lock()
+		defer hookMx.Unlock()
+		if !stopped {
+			hook(report)
+		}
+	})
+	return func() {
+		hookMx.Lock()
+		stopped = true
+		hookMx.Unlock()
+		timer.Stop()
+		cancel()
+		release()
+	}
+}
@@ -0,0 +1,52 @@
+package control
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Classes of navigation errors, use errors.Is(err, ErrNameNotResolved) to distinguish them
+var (
+	ErrNameNotResolved      = errors.New("name not resolved")
+	ErrConnectionRefused    = errors.New("connection refused")
+	ErrConnectionReset      = errors.New("connection reset")
+	ErrConnectionTimedOut   = errors.New("connection timed out")
+	ErrInternetDisconnected = errors.New("internet disconnected")
+	ErrCertInvalid          = errors.New("certificate is invalid")
+	ErrAborted              = errors.New("navigation aborted")
+	ErrBlockedByClient      = errors.New("blocked by client")
+)
+
+// NavigationError Page.navigate reported errorText (net::ERR_*)
+type NavigationError struct {
+	URL       string
+	ErrorText string
+}
+
+func (e NavigationError) Error() string {
+	return fmt.Sprintf("navigation to `%s` failed: %s", e.URL, e.ErrorText)
+}
+
+// Unwrap returns the class of the error or nil if it is unknown
+func (e NavigationError) Unwrap() error {
+	switch code := strings.TrimPrefix(e.ErrorText, "net::"); {
+	case code == "ERR_NAME_NOT_RESOLVED", code == "ERR_NAME_RESOLUTION_FAILED":
+		return ErrNameNotResolved
+	case code == "ERR_CONNECTION_REFUSED":
+		return ErrConnectionRefused
+	case code == "ERR_CONNECTION_RESET", code == "ERR_CONNECTION_CLOSED", code == "ERR_EMPTY_RESPONSE":
+		return ErrConnectionReset
+	case code == "ERR_CONNECTION_TIMED_OUT", code == "ERR_TIMED_OUT":
+		return ErrConnectionTimedOut
+	case code == "ERR_INTERNET_DISCONNECTED":
+		return ErrInternetDisconnected
+	case strings.HasPrefix(code, "ERR_CERT_"), code == "ERR_SSL_PROTOCOL_ERROR", code == "ERR_BAD_SSL_CLIENT_AUTH_CERT":
+		return ErrCertInvalid
+	case code == "ERR_ABORTED":
+		return ErrAborted
+	case code == "ERR_BLOCKED_BY_CLIENT", code == "ERR_BLOCKED_BY_RESPONSE":
+		return ErrBlockedByClient
+	}
+	return nil
+}
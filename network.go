@@ -12,7 +12,11 @@ import (
 func (s *Session) CaptureResponseReceived(condition func(request *network.Request) bool, rejectOnLoadingFailed bool) Future { // Future<network.ResponseReceived>
 	var requestID network.RequestId
 
-	return s.Observe("*", func(value transport.Event, resolve func(interface{}), reject func(error)) {
+	release, err := s.EnableDomain("Network")
+	if err != nil {
+		return rejectedFuture(err)
+	}
+	future := s.Observe("*", func(value transport.Event, resolve func(interface{}), reject func(error)) {
 		switch value.Method {
 
 		case "Network.requestWillBeSent":
@@ -50,6 +54,12 @@ func (s *Session) CaptureResponseReceived(condition func(request *network.Reques
 			}
 		}
 	})
+	unregister := future.promise.unregister
+	future.promise.unregister = func() {
+		unregister()
+		release()
+	}
+	return future
 }
 
 type Network struct {
@@ -68,6 +78,16 @@ func (n Network) SetCookies(cookies ...*network.CookieParam) error {
 	})
 }
 
+// DeleteCookies deletes cookies matching name and url or domain (and path)
+func (n Network) DeleteCookies(cookies ...*network.DeleteCookiesArgs) error {
+	for _, c := range cookies {
+		if err := network.DeleteCookies(n.s, *c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetCookies returns all browser cookies for the current URL
 func (n Network) GetCookies(urls ...string) ([]*network.Cookie, error) {
 	val, err := network.GetCookies(n.s, network.GetCookiesArgs{
@@ -81,6 +101,9 @@ func (n Network) GetCookies(urls ...string) ([]*network.Cookie, error) {
 
 // SetExtraHTTPHeaders Specifies whether to always send extra HTTP headers with the requests from this page.
 func (n Network) SetExtraHTTPHeaders(v map[string]string) error {
+	if err := n.s.holdDomain("Network"); err != nil {
+		return err
+	}
 	val := network.Headers(v)
 	return network.SetExtraHTTPHeaders(n.s, network.SetExtraHTTPHeadersArgs{
 		Headers: &val,
@@ -106,6 +129,9 @@ const (
 )
 
 func (n Network) EmulateNetworkConditions(offline bool, latency, downloadThroughput, uploadThroughput float64, connectionType network.ConnectionType) error {
+	if err := n.s.holdDomain("Network"); err != nil {
+		return err
+	}
 	return network.EmulateNetworkConditions(n.s, network.EmulateNetworkConditionsArgs{
 		Offline:            offline,
 		Latency:            latency,
@@ -117,6 +143,9 @@ func (n Network) EmulateNetworkConditions(offline bool, latency, downloadThrough
 
 // SetBlockedURLs ...
 func (n Network) SetBlockedURLs(urls []string) error {
+	if err := n.s.holdDomain("Network"); err != nil {
+		return err
+	}
 	return network.SetBlockedURLs(n.s, network.SetBlockedURLsArgs{
 		Urls: urls,
 	})
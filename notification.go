@@ -0,0 +1,70 @@
+package control
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const bindNotification = "_on_notification"
+
+// scriptNotification replaces Notification API with a shim reporting shown notifications to the binding
+const scriptNotification = `(()=>{if(window.__control_notification)return;window.__control_notification=!0;
+const report=(title,o)=>{o=o||{};try{window._on_notification(JSON.stringify({title:String(title),body:o.body||"",tag:o.tag||"",icon:o.icon||"",data:o.data===undefined?null:o.data,origin:location.origin}))}catch(e){}};
+class N extends EventTarget{constructor(title,o){super();o=o||{};this.title=title;this.body=o.body||"";this.tag=o.tag||"";this.icon=o.icon||"";this.data=o.data;report(title,o);setTimeout(()=>this.dispatchEvent(new Event("show")))}close(){this.dispatchEvent(new Event("close"))}
+static get permission(){return "granted"}static requestPermission(cb){cb&&cb("granted");return Promise.resolve("granted")}}
+window.Notification=N;
+if(window.ServiceWorkerRegistration){const p=ServiceWorkerRegistration.prototype;p.showNotification=function(title,o){report(title,o);return Promise.resolve()}}})()`
+
+// Notification shown by the page via new Notification() or ServiceWorkerRegistration.showNotification()
+type Notification struct {
+	Title  string          `json:"title"`
+	Body   string          `json:"body"`
+	Tag    string          `json:"tag"`
+	Icon   string          `json:"icon"`
+	Data   json.RawMessage `json:"data"`
+	Origin string          `json:"origin"`
+	Time   time.Time       `json:"-"`
+}
+
+// CaptureNotifications grants notifications permission and reports every notification shown by the page to the channel.
+// Notifications are dropped if the channel is full. The channel is never closed
+func (s Session) CaptureNotifications(buffer int) (notifications <-chan Notification, cancel func(), err error) {
+	var (
+		ch   = make(chan Notification, buffer)
+		done = make(chan struct{})
+		once sync.Once
+	)
+	if err = s.GrantPermissions("", PermissionNotifications); err != nil {
+		return nil, nil, err
+	}
+	removeBinding, err := s.AddBinding(bindNotification, func(payload string) {
+		var n Notification
+		if json.Unmarshal([]byte(payload), &n) != nil {
+			return
+		}
+		n.Time = time.Now()
+		select {
+		case ch <- n:
+		case <-done:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(scriptNotification)
+	if err != nil {
+		removeBinding()
+		return nil, nil, err
+	}
+	// install into the current document as well
+	_, _ = s.Page().Evaluate(scriptNotification, false, false)
+	return ch, func() {
+		once.Do(func() {
+			close(done)
+			removeBinding()
+			_ = s.RemoveScriptToEvaluateOnNewDocument(identifier)
+		})
+	}, nil
+}
@@ -0,0 +1,220 @@
+// Package oauth drives OAuth 2.0 / OpenID Connect logins through the identity provider's pages:
+// it fills credentials, accepts consent and captures the code or tokens of the redirect back to the application
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/network"
+)
+
+var ErrTimeout = errors.New("oauth redirect is not received in time")
+
+const (
+	DefaultUsernameSelector = `input[type=email], input[name=username], input[name=login], input[name=email], input[autocomplete=username], input#username`
+	DefaultPasswordSelector = `input[type=password]`
+	DefaultSubmitSelector   = `button[type=submit], input[type=submit], button[name=action], button#next`
+)
+
+// Flow of a login, the zero selectors are defaults matching most identity providers
+type Flow struct {
+	RedirectURL      string // wildcard pattern of the application's callback, e.g. "https://app.test/oauth/callback*"
+	ProviderURL      string // wildcard pattern of the identity provider pages credentials are typed into, empty - any page
+	Username         string
+	Password         string
+	UsernameSelector string
+	PasswordSelector string
+	SubmitSelector   string
+	ConsentSelector  string        // the button clicked when the consent page appears, empty - there is no consent step
+	Intercept        bool          // fulfill the callback with a stub page, so the application doesn't redeem the code
+	Timeout          time.Duration // of the whole flow, zero - 1 minute
+}
+
+// Result parameters of the redirect back to the application (from the query or the fragment of implicit flows)
+type Result struct {
+	URL         string
+	Code        string
+	State       string
+	AccessToken string
+	IDToken     string
+	Params      url.Values // all parameters, the fragment overrides the query
+}
+
+// Error redirect with error parameter
+type Error struct {
+	Code        string
+	Description string
+}
+
+func (e Error) Error() string {
+	if e.Description == "" {
+		return "oauth: " + e.Code
+	}
+	return "oauth: " + e.Code + ": " + e.Description
+}
+
+// Login calls start (e.g. navigates to the application's login or clicks "Sign in with ..."),
+// goes through the provider's pages and returns the parameters of the redirect.
+// Username and password may be on one page or on consecutive pages
+func (f Flow) Login(session *control.Session, start func() error) (*Result, error) {
+	f = f.withDefaults()
+	redirect := make(chan string, 1)
+	if f.Intercept {
+		cancel, err := session.Intercept(f.RedirectURL, control.StageRequest, func(i *control.Interception) {
+			select {
+			case redirect <- i.Request.Url + i.Request.UrlFragment:
+			default:
+			}
+			i.Fulfill(http.StatusOK, http.Header{"Content-Type": {"text/html"}}, []byte("<p>Signed in</p>"))
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+	} else {
+		future := session.WaitForRequest(f.RedirectURL)
+		defer future.Cancel()
+		go func() {
+			// resolves with nil when the future is cancelled
+			if v, err := future.Get(f.Timeout); err == nil {
+				if r, ok := v.(*network.Request); ok {
+					redirect <- r.Url + r.UrlFragment
+				}
+			}
+		}()
+	}
+	if err := start(); err != nil {
+		return nil, err
+	}
+	var (
+		deadline = time.After(f.Timeout)
+		ticker   = time.NewTicker(250 * time.Millisecond)
+		filled   = map[string]bool{} // page URL + step
+		lastErr  error
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case u := <-redirect:
+			return parse(u)
+		case <-deadline:
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w: %v", ErrTimeout, lastErr)
+			}
+			return nil, ErrTimeout
+		case <-ticker.C:
+		}
+		// the provider's pages navigate under the hands, a failed step is retried on the next tick
+		lastErr = f.step(session, filled)
+	}
+}
+
+// step fills the fields visible on the current page, every field is filled once per page (a failed one is filled again)
+func (f Flow) step(session *control.Session, filled map[string]bool) error {
+	page := session.Page()
+	v, err := page.Evaluate(`location.href`, false, true)
+	if err != nil {
+		return nil // navigation in progress
+	}
+	current, _ := v.(string)
+	if wildcard.Match(f.RedirectURL, current) || (f.ProviderURL != "" && !wildcard.Match(f.ProviderURL, current)) {
+		return nil
+	}
+	visible := func(selector string) *control.Element {
+		e, err := page.Find(selector, true, 0)
+		if err != nil {
+			return nil
+		}
+		return e
+	}
+	pending := func(step string) bool {
+		return !filled[current+" "+step]
+	}
+	done := func(step string) {
+		filled[current+" "+step] = true
+	}
+	if e := visible(f.UsernameSelector); e != nil && f.Username != "" && pending("username") {
+		if err = e.Type(f.Username, 0); err != nil {
+			return err
+		}
+		done("username")
+	}
+	if e := visible(f.PasswordSelector); e != nil && f.Password != "" && pending("password") {
+		if err = e.TypeSecret(f.Password); err != nil {
+			return err
+		}
+		done("password")
+	}
+	// submitted once per set of filled fields, so a password page behind the same URL is submitted again
+	if submit := fmt.Sprintf("submit %t %t", !pending("username"), !pending("password")); submit != "submit false false" && pending(submit) {
+		if e := visible(f.SubmitSelector); e != nil {
+			err = e.Click()
+		} else {
+			err = session.Keyboard().Press("Enter")
+		}
+		if err != nil {
+			return err
+		}
+		done(submit)
+	}
+	if f.ConsentSelector != "" {
+		if e := visible(f.ConsentSelector); e != nil && pending("consent") {
+			if err = e.Click(); err != nil {
+				return err
+			}
+			done("consent")
+		}
+	}
+	return nil
+}
+
+func (f Flow) withDefaults() Flow {
+	if f.UsernameSelector == "" {
+		f.UsernameSelector = DefaultUsernameSelector
+	}
+	if f.PasswordSelector == "" {
+		f.PasswordSelector = DefaultPasswordSelector
+	}
+	if f.SubmitSelector == "" {
+		f.SubmitSelector = DefaultSubmitSelector
+	}
+	if f.Timeout == 0 {
+		f.Timeout = time.Minute
+	}
+	return f
+}
+
+func parse(raw string) (*Result, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	params := u.Query()
+	if u.Fragment != "" {
+		fragment, err := url.ParseQuery(strings.TrimPrefix(u.Fragment, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("oauth: fragment of the redirect: %v", err)
+		}
+		for k, v := range fragment {
+			params[k] = v
+		}
+	}
+	if code := params.Get("error"); code != "" {
+		return nil, Error{Code: code, Description: params.Get("error_description")}
+	}
+	return &Result{
+		URL:         raw,
+		Code:        params.Get("code"),
+		State:       params.Get("state"),
+		AccessToken: params.Get("access_token"),
+		IDToken:     params.Get("id_token"),
+		Params:      params,
+	}, nil
+}
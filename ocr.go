@@ -0,0 +1,24 @@
+package control
+
+// TextRecognizer extracts text from PNG image, e.g. ocr.Tesseract (built with -tags tesseract)
+type TextRecognizer interface {
+	RecognizeText(png []byte) (string, error)
+}
+
+// RecognizeText extracts text rendered by the element as pixels (canvas charts, images, PDF pages drawn on canvas)
+// where DOM text isn't available. A canvas is read in its own resolution, other elements are screenshotted
+func (e Element) RecognizeText(r TextRecognizer) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if e.node.NodeName == "CANVAS" {
+		b, err = e.CaptureCanvas()
+	} else {
+		b, err = e.Screenshot("png", 0)
+	}
+	if err != nil {
+		return "", err
+	}
+	return r.RecognizeText(b)
+}
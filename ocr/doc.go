@@ -0,0 +1,3 @@
+// Package ocr implements control.TextRecognizer. Tesseract runs the tesseract command line tool
+// and is built with -tags tesseract only, so the package doesn't require it by default
+package ocr
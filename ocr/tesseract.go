@@ -0,0 +1,44 @@
+//go:build tesseract
+// +build tesseract
+
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Tesseract recognizes text with the tesseract command, the image is passed through stdin
+type Tesseract struct {
+	Path      string   // empty - "tesseract" from PATH
+	Languages []string // trained data names, e.g. "eng", "deu". Empty - tesseract's default
+	PSM       int      // page segmentation mode, zero - tesseract's default (3, fully automatic). 7 - a single line
+	Args      []string // extra arguments, e.g. "-c", "tessedit_char_whitelist=0123456789"
+}
+
+// RecognizeText implements control.TextRecognizer
+func (t Tesseract) RecognizeText(png []byte) (string, error) {
+	path := t.Path
+	if path == "" {
+		path = "tesseract"
+	}
+	args := []string{"stdin", "stdout"}
+	if len(t.Languages) > 0 {
+		args = append(args, "-l", strings.Join(t.Languages, "+"))
+	}
+	if t.PSM != 0 {
+		args = append(args, "--psm", strconv.Itoa(t.PSM))
+	}
+	args = append(args, t.Args...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(png)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tesseract: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,21 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AddOriginTrialTokens injects origin trial tokens into every document of the session as <meta http-equiv="origin-trial">,
+// so experimental features can be enabled for this tab only. Tokens must be issued for the page's origin (or be third-party tokens)
+func (s Session) AddOriginTrialTokens(tokens ...string) (cancel func(), err error) {
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return nil, err
+	}
+	script := fmt.Sprintf(`for(const t of %s){const m=document.createElement("meta");m.httpEquiv="origin-trial";m.content=t;(document.head||document.documentElement).appendChild(m)}`, b)
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(script)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = s.RemoveScriptToEvaluateOnNewDocument(identifier) }, nil
+}
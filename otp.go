@@ -0,0 +1,21 @@
+package control
+
+import (
+	"time"
+
+	"github.com/ecwid/control/totp"
+)
+
+// TypeTOTP types the current TOTP code of the base32 secret into the element.
+// If the code expires in less than 3 seconds the next one is waited for, so it isn't rejected on submit.
+// The code is typed with TypeSecret, so it doesn't get into recordings (nor into dumps if a redaction policy is set)
+func (e Element) TypeTOTP(secret string) error {
+	if left := totp.Remaining(time.Now(), totp.Options{}); left < 3*time.Second {
+		time.Sleep(left)
+	}
+	code, err := totp.Generate(secret, time.Now())
+	if err != nil {
+		return err
+	}
+	return e.TypeSecret(code)
+}
@@ -62,3 +62,15 @@ func (s Session) GetLayoutMetrics() (*page.GetLayoutMetricsVal, error) {
 	}
 	return view, nil
 }
+
+// Page lifecycle states for SetWebLifecycleState
+const (
+	WebLifecycleFrozen = "frozen"
+	WebLifecycleActive = "active"
+)
+
+// SetWebLifecycleState freezes or resumes the page, e.g. to check that a player survives tab discarding
+// https://chromedevtools.github.io/devtools-protocol/tot/Page/#method-setWebLifecycleState
+func (s Session) SetWebLifecycleState(state string) error {
+	return page.SetWebLifecycleState(s, page.SetWebLifecycleStateArgs{State: state})
+}
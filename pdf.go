@@ -0,0 +1,118 @@
+package control
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+// PaperSize in inches
+type PaperSize struct {
+	Width  float64
+	Height float64
+}
+
+var (
+	PaperLetter  = PaperSize{Width: 8.5, Height: 11}
+	PaperLegal   = PaperSize{Width: 8.5, Height: 14}
+	PaperTabloid = PaperSize{Width: 11, Height: 17}
+	PaperA3      = PaperSize{Width: 11.69, Height: 16.54}
+	PaperA4      = PaperSize{Width: 8.27, Height: 11.69}
+	PaperA5      = PaperSize{Width: 5.83, Height: 8.27}
+)
+
+// Margins in inches
+type Margins struct {
+	Top    float64
+	Bottom float64
+	Left   float64
+	Right  float64
+}
+
+// PDFOptions of PrintToPDF, the zero value prints Letter in portrait with default margins (~0.4 inch)
+type PDFOptions struct {
+	Paper             PaperSize // zero - Letter
+	Margins           *Margins  // nil - default margins
+	Landscape         bool
+	PrintBackground   bool
+	Scale             float64 // zero - 1, allowed range is 0.1..2
+	PageRanges        string  // e.g. "1-5, 8, 11-13", empty - all pages
+	PreferCSSPageSize bool    // @page size declared in CSS wins over Paper
+	// HeaderTemplate and FooterTemplate are HTML with elements of classes date, title, url, pageNumber, totalPages
+	// into which the values are injected. Both are shown only if at least one of them is set
+	HeaderTemplate string
+	FooterTemplate string
+}
+
+// zero margins are valid, so they can't be omitted as the generated args do
+type printToPDFArgs struct {
+	page.PrintToPDFArgs
+	MarginTop    *float64 `json:"marginTop,omitempty"`
+	MarginBottom *float64 `json:"marginBottom,omitempty"`
+	MarginLeft   *float64 `json:"marginLeft,omitempty"`
+	MarginRight  *float64 `json:"marginRight,omitempty"`
+}
+
+// PrintToPDF prints the page to PDF, NotSupportedError is returned if the browser doesn't implement printing
+func (s Session) PrintToPDF(options PDFOptions) ([]byte, error) {
+	var val = &page.PrintToPDFVal{}
+	if err := s.printToPDF(s.printToPDFArgs(options), val); err != nil {
+		return nil, err
+	}
+	return val.Data, nil
+}
+
+// PrintToPDFStream is PrintToPDF which returns the document as a stream, so large documents aren't kept in memory
+func (s Session) PrintToPDFStream(options PDFOptions) (*Stream, error) {
+	args := s.printToPDFArgs(options)
+	args.TransferMode = "ReturnAsStream"
+	var val = &page.PrintToPDFVal{}
+	if err := s.printToPDF(args, val); err != nil {
+		return nil, err
+	}
+	return NewStream(s, val.Stream), nil
+}
+
+// printToPDF calls Page.printToPDF, the protocol error of a browser which doesn't implement it is NotSupportedError
+func (s Session) printToPDF(args *printToPDFArgs, val *page.PrintToPDFVal) error {
+	err := s.Call("Page.printToPDF", args, val)
+	var e *transport.Error
+	if errors.As(err, &e) && (strings.Contains(e.Message, "not implemented") || strings.Contains(e.Message, "wasn't found")) {
+		product := "the browser"
+		if caps, capsErr := s.browser.Capabilities(); capsErr == nil {
+			product = caps.Product
+		}
+		return NotSupportedError{Feature: "Page.printToPDF", Product: product}
+	}
+	return err
+}
+
+func (s Session) printToPDFArgs(options PDFOptions) *printToPDFArgs {
+	args := &printToPDFArgs{PrintToPDFArgs: page.PrintToPDFArgs{
+		Landscape:           options.Landscape,
+		DisplayHeaderFooter: options.HeaderTemplate != "" || options.FooterTemplate != "",
+		PrintBackground:     options.PrintBackground,
+		Scale:               options.Scale,
+		PaperWidth:          options.Paper.Width,
+		PaperHeight:         options.Paper.Height,
+		PageRanges:          options.PageRanges,
+		HeaderTemplate:      options.HeaderTemplate,
+		FooterTemplate:      options.FooterTemplate,
+		PreferCSSPageSize:   options.PreferCSSPageSize,
+	}}
+	if args.DisplayHeaderFooter {
+		// an empty template means the default one (title and date) instead of nothing
+		if args.HeaderTemplate == "" {
+			args.HeaderTemplate = "<span></span>"
+		}
+		if args.FooterTemplate == "" {
+			args.FooterTemplate = "<span></span>"
+		}
+	}
+	if m := options.Margins; m != nil {
+		args.MarginTop, args.MarginBottom, args.MarginLeft, args.MarginRight = &m.Top, &m.Bottom, &m.Left, &m.Right
+	}
+	return args
+}
@@ -0,0 +1,260 @@
+package pdfcheck
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// PDF objects: nil, bool, float64, []byte (string), name, keyword, ref, array, dict, *stream
+type (
+	name    string
+	keyword string
+	array   []interface{}
+	dict    map[name]interface{}
+	ref     struct{ num, gen int }
+	stream  struct {
+		dict dict
+		raw  []byte
+	}
+)
+
+type lexer struct {
+	b   []byte
+	pos int
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		if c == '%' { // comment
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// token returns the next primitive value, a keyword or a delimiter ("[", "]", "<<", ">>") as keyword, nil keyword at EOF
+func (l *lexer) token() interface{} {
+	l.skipSpace()
+	if l.pos >= len(l.b) {
+		return keyword("")
+	}
+	c := l.b[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelimiter(l.b[l.pos]) {
+			l.pos++
+		}
+		return name(unescapeName(l.b[start:l.pos]))
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.b) && l.b[l.pos+1] == '<':
+		l.pos += 2
+		return keyword("<<")
+	case c == '>' && l.pos+1 < len(l.b) && l.b[l.pos+1] == '>':
+		l.pos += 2
+		return keyword(">>")
+	case c == '<':
+		return l.hexString()
+	case c == '[' || c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return keyword(c)
+	}
+	start := l.pos
+	for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelimiter(l.b[l.pos]) {
+		l.pos++
+	}
+	word := string(l.b[start:l.pos])
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f
+	}
+	return keyword(word)
+}
+
+func unescapeName(b []byte) string {
+	if bytes.IndexByte(b, '#') == -1 {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+func (l *lexer) literalString() []byte {
+	l.pos++ // (
+	var (
+		out   []byte
+		depth = 1
+	)
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			c = l.b[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for n := 0; n < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; n++ {
+						v = v*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *lexer) hexString() []byte {
+	l.pos++ // <
+	var (
+		out  []byte
+		high = -1
+	)
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v := hexValue(c)
+		if v < 0 {
+			continue
+		}
+		if high < 0 {
+			high = v
+		} else {
+			out = append(out, byte(high<<4|v))
+			high = -1
+		}
+	}
+	if high >= 0 {
+		out = append(out, byte(high<<4))
+	}
+	return out
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// value parses the next object: arrays, dictionaries and references are assembled from tokens
+func (l *lexer) value() interface{} {
+	t := l.token()
+	switch v := t.(type) {
+	case keyword:
+		switch v {
+		case "[":
+			var a array
+			for {
+				save := l.pos
+				if k, ok := l.token().(keyword); ok && (k == "]" || k == "") {
+					return a
+				}
+				l.pos = save
+				a = append(a, l.value())
+			}
+		case "<<":
+			d := dict{}
+			for {
+				key := l.token()
+				if k, ok := key.(keyword); ok && (k == ">>" || k == "") {
+					return d
+				}
+				if n, ok := key.(name); ok {
+					d[n] = l.value()
+				}
+			}
+		}
+	case float64:
+		// "num gen R" is a reference
+		save := l.pos
+		if gen, ok := l.token().(float64); ok {
+			if k, ok := l.token().(keyword); ok && k == "R" {
+				return ref{num: int(v), gen: int(gen)}
+			}
+		}
+		l.pos = save
+	}
+	return t
+}
@@ -0,0 +1,156 @@
+// Package pdfcheck extracts text and metadata of PDF files (downloaded or printed by the browser) for assertions,
+// e.g. that an exported invoice contains its number. It's a text extractor, not a renderer: encrypted files
+// and filters other than FlateDecode are not supported, text of fonts without ToUnicode maps may be missing
+package pdfcheck
+
+import (
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNotPDF    = errors.New("not a PDF file")
+	ErrEncrypted = errors.New("encrypted PDF is not supported")
+)
+
+// Document text and metadata of a PDF file
+type Document struct {
+	Pages []string          // text of every page, lines are separated by "\n"
+	Info  map[string]string // document information: Title, Author, Subject, Keywords, Creator, Producer, CreationDate, ModDate
+}
+
+// Parse reads the PDF file content
+func Parse(b []byte) (*Document, error) {
+	r, err := newReader(b)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Info: map[string]string{}}
+	for k, v := range r.dict(r.trailer["Info"]) {
+		if s, ok := r.resolve(v).([]byte); ok {
+			doc.Info[string(k)] = pdfDocString(s)
+		}
+	}
+	for _, page := range r.pages() {
+		var w textWriter
+		for _, c := range r.array(page.dict["Contents"]) {
+			if s, ok := r.resolve(c).(*stream); ok {
+				if data, err := r.decode(s); err == nil {
+					r.content(data, page.resources, &w, 0)
+					w.newline()
+				}
+			}
+		}
+		doc.Pages = append(doc.Pages, strings.TrimSpace(w.String()))
+	}
+	return doc, nil
+}
+
+// ParseFile reads the PDF file, e.g. the one saved by Session.WaitForDownload
+func ParseFile(path string) (*Document, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Text of all pages separated by form feeds
+func (d *Document) Text() string {
+	return strings.Join(d.Pages, "\f")
+}
+
+// Contains reports whether the text contains s, runs of white space are treated as a single space
+// since line breaks of the layout are unknown to the caller
+func (d *Document) Contains(s string) bool {
+	return strings.Contains(normalize(d.Text()), normalize(s))
+}
+
+// Missing returns those of strings which the text doesn't contain, see Contains
+func (d *Document) Missing(list ...string) []string {
+	var (
+		missing []string
+		text    = normalize(d.Text())
+	)
+	for _, s := range list {
+		if !strings.Contains(text, normalize(s)) {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// PagesContaining numbers (from 1) of pages which contain s
+func (d *Document) PagesContaining(s string) []int {
+	var list []int
+	for n, page := range d.Pages {
+		if strings.Contains(normalize(page), normalize(s)) {
+			list = append(list, n+1)
+		}
+	}
+	return list
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+type page struct {
+	dict      dict
+	resources dict
+}
+
+// pages in order of the page tree, resources are inherited from parents
+func (r *reader) pages() []page {
+	var (
+		list []page
+		seen = map[ref]bool{}
+		walk func(node interface{}, resources dict, depth int)
+	)
+	walk = func(node interface{}, resources dict, depth int) {
+		if depth > 64 {
+			return
+		}
+		// inline nodes (dicts, arrays) are not hashable and can't make a cycle
+		if rf, ok := node.(ref); ok {
+			if seen[rf] {
+				return
+			}
+			seen[rf] = true
+		}
+		d := r.dict(node)
+		if d == nil {
+			return
+		}
+		if res := r.dict(d["Resources"]); res != nil {
+			resources = res
+		}
+		if d["Type"] == name("Page") || (d["Kids"] == nil && d["Contents"] != nil) {
+			list = append(list, page{dict: d, resources: resources})
+			return
+		}
+		for _, kid := range r.array(d["Kids"]) {
+			walk(kid, resources, depth+1)
+		}
+	}
+	if root := r.dict(r.trailer["Root"]); root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+	if len(list) == 0 {
+		// no usable catalog, pages in order of object numbers
+		var nums []int
+		for num, v := range r.objects {
+			if d, ok := v.(dict); ok && d["Type"] == name("Page") {
+				nums = append(nums, num)
+			}
+		}
+		sort.Ints(nums)
+		for _, num := range nums {
+			d := r.objects[num].(dict)
+			list = append(list, page{dict: d, resources: r.dict(d["Resources"])})
+		}
+	}
+	return list
+}
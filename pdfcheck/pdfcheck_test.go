@@ -0,0 +1,94 @@
+package pdfcheck
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// minimalPDF one-page document, pages is the value of /Pages of the catalog
+func minimalPDF(pages string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages " + pages + " >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Length 44 >>\nstream\nBT /F1 12 Tf 20 100 Td (Hello world) Tj ET\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var (
+		b       strings.Builder
+		offsets []int
+	)
+	b.WriteString("%PDF-1.4\n")
+	for n, o := range objects {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", n+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return []byte(b.String())
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(minimalPDF("2 0 R"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Pages) != 1 || !doc.Contains("Hello world") {
+		t.Fatalf("unexpected pages %q", doc.Pages)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	// page tree nodes of unexpected types must not panic
+	for _, pages := range []string{
+		"<< /Type /Pages /Kids [3 0 R] >>",
+		"<< /Type /Pages /Kids [<< /Kids [] >> [1 2] (text) <abcd> 3 0 R] >>",
+		"[3 0 R]",
+		"(pages)",
+		"<< /Kids 2 0 R >>",
+		"1 0 R",
+		"null",
+	} {
+		if _, err := Parse(minimalPDF(pages)); err != nil {
+			t.Logf("%s: %v", pages, err)
+		}
+	}
+
+	valid := minimalPDF("2 0 R")
+	tokens := []string{"<<", ">>", "[", "]", "(", ")", "/Kids", "/Pages", "0 R", "obj", "endobj", "stream", "endstream", "xref", "trailer", "\\", "<", ">"}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		b := append([]byte(nil), valid...)
+		for n := r.Intn(4) + 1; n > 0 && len(b) > 0; n-- {
+			at := r.Intn(len(b))
+			switch r.Intn(4) {
+			case 0: // flip a byte
+				b[at] = byte(r.Intn(256))
+			case 1: // cut a range
+				end := at + r.Intn(16)
+				if end > len(b) {
+					end = len(b)
+				}
+				b = append(b[:at], b[end:]...)
+			case 2: // insert a token
+				b = append(b[:at], append([]byte(tokens[r.Intn(len(tokens))]), b[at:]...)...)
+			case 3: // truncate
+				b = b[:at]
+			}
+		}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Fatalf("Parse panicked on %q: %v", b, p)
+				}
+			}()
+			_, _ = Parse(b)
+		}()
+	}
+}
@@ -0,0 +1,227 @@
+package pdfcheck
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+)
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// reader objects of the file indexed by number, the xref table is not used so damaged files are readable too
+type reader struct {
+	objects map[int]interface{}
+	trailer dict
+}
+
+func newReader(b []byte) (*reader, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(b, " \t\r\n"), []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+	r := &reader{objects: map[int]interface{}{}, trailer: dict{}}
+	var objectStreams []*stream
+	for _, m := range objectHeader.FindAllSubmatchIndex(b, -1) {
+		num, _ := strconv.Atoi(string(b[m[2]:m[3]]))
+		l := &lexer{b: b, pos: m[1]}
+		v := l.value()
+		if d, ok := v.(dict); ok {
+			save := l.pos
+			if k, ok := l.token().(keyword); ok && k == "stream" {
+				s := &stream{dict: d, raw: streamData(b, l.pos, d)}
+				v = s
+				switch d["Type"] {
+				case name("ObjStm"):
+					objectStreams = append(objectStreams, s)
+				case name("XRef"):
+					r.mergeTrailer(d)
+				}
+			} else {
+				l.pos = save
+			}
+		}
+		r.objects[num] = v // incremental updates come later in the file
+	}
+	for _, m := range regexp.MustCompile(`trailer\s*<<`).FindAllIndex(b, -1) {
+		l := &lexer{b: b, pos: m[1] - 2}
+		if d, ok := l.value().(dict); ok {
+			r.mergeTrailer(d)
+		}
+	}
+	for _, s := range objectStreams {
+		r.readObjectStream(s)
+	}
+	if r.trailer["Encrypt"] != nil {
+		return nil, ErrEncrypted
+	}
+	return r, nil
+}
+
+func (r *reader) mergeTrailer(d dict) {
+	for k, v := range d {
+		r.trailer[k] = v
+	}
+}
+
+// streamData raw bytes of the stream starting at pos (right after the stream keyword)
+func streamData(b []byte, pos int, d dict) []byte {
+	if pos < len(b) && b[pos] == '\r' {
+		pos++
+	}
+	if pos < len(b) && b[pos] == '\n' {
+		pos++
+	}
+	if length, ok := d["Length"].(float64); ok {
+		end := pos + int(length)
+		if end <= len(b) && bytes.HasPrefix(bytes.TrimLeft(b[end:], " \t\r\n"), []byte("endstream")) {
+			return b[pos:end]
+		}
+	}
+	end := bytes.Index(b[pos:], []byte("endstream"))
+	if end == -1 {
+		return b[pos:]
+	}
+	return bytes.TrimRight(b[pos:pos+end], "\r\n")
+}
+
+// readObjectStream adds compressed objects, objects written directly in the file win
+func (r *reader) readObjectStream(s *stream) {
+	data, err := r.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := s.dict["N"].(float64)
+	first, _ := s.dict["First"].(float64)
+	header := &lexer{b: data}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := header.token().(float64)
+		offset, ok2 := header.token().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := r.objects[int(num)]; exists {
+			continue
+		}
+		pos := int(first) + int(offset)
+		if pos >= len(data) {
+			continue
+		}
+		r.objects[int(num)] = (&lexer{b: data, pos: pos}).value()
+	}
+}
+
+// resolve follows references
+func (r *reader) resolve(v interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = r.objects[ref.num]
+	}
+	return nil
+}
+
+func (r *reader) dict(v interface{}) dict {
+	switch v := r.resolve(v).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+func (r *reader) array(v interface{}) array {
+	switch v := r.resolve(v).(type) {
+	case array:
+		return v
+	case nil:
+		return nil
+	default:
+		return array{v}
+	}
+}
+
+// decode stream data, FlateDecode is the only supported filter (the one browsers and most tools write)
+func (r *reader) decode(s *stream) ([]byte, error) {
+	data := s.raw
+	for _, f := range r.array(s.dict["Filter"]) {
+		switch r.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			z, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			// a truncated stream still gives the text decoded so far
+			data, err = ioutil.ReadAll(z)
+			if len(data) == 0 && err != nil {
+				return nil, err
+			}
+			if parms := r.dict(s.dict["DecodeParms"]); parms != nil {
+				if predictor, _ := parms["Predictor"].(float64); predictor >= 10 {
+					columns, _ := parms["Columns"].(float64)
+					data = unpredictPNG(data, int(columns))
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", f)
+		}
+	}
+	return data, nil
+}
+
+// unpredictPNG reverses PNG predictors (used by xref and object streams)
+func unpredictPNG(data []byte, columns int) []byte {
+	if columns <= 0 {
+		columns = 1
+	}
+	var (
+		out  []byte
+		prev = make([]byte, columns)
+	)
+	for len(data) >= columns+1 {
+		kind, row := data[0], append([]byte(nil), data[1:columns+1]...)
+		data = data[columns+1:]
+		for i := range row {
+			var left, upLeft byte
+			if i > 0 {
+				left, upLeft = row[i-1], prev[i-1]
+			}
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += prev[i]
+			case 3:
+				row[i] += byte((int(left) + int(prev[i])) / 2)
+			case 4:
+				row[i] += paeth(left, prev[i], upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,299 @@
+package pdfcheck
+
+import (
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// font decodes strings of text operators to unicode
+type font struct {
+	cmap     map[string]string // code -> text, from ToUnicode
+	widths   []int             // code lengths in bytes of the cmap's codespace
+	twoBytes bool              // composite font without ToUnicode, codes are glyph ids
+}
+
+func (r *reader) font(v interface{}) *font {
+	d := r.dict(v)
+	f := &font{}
+	if d == nil {
+		return f
+	}
+	f.twoBytes = d["Subtype"] == name("Type0")
+	if s, ok := r.resolve(d["ToUnicode"]).(*stream); ok {
+		if data, err := r.decode(s); err == nil {
+			f.cmap, f.widths = parseCMap(data)
+		}
+	}
+	return f
+}
+
+func (f *font) decode(b []byte) string {
+	if f.cmap == nil {
+		if f.twoBytes {
+			return "" // glyph ids can't be mapped to text
+		}
+		return pdfDocString(b)
+	}
+	var out strings.Builder
+	widths := f.widths
+	if len(widths) == 0 {
+		widths = []int{1, 2}
+	}
+next:
+	for i := 0; i < len(b); {
+		for _, w := range widths {
+			if i+w > len(b) {
+				continue
+			}
+			if text, ok := f.cmap[string(b[i:i+w])]; ok {
+				out.WriteString(text)
+				i += w
+				continue next
+			}
+		}
+		i += widths[len(widths)-1] // unmapped code
+	}
+	return out.String()
+}
+
+// parseCMap reads bfchar and bfrange mappings of ToUnicode CMap
+func parseCMap(data []byte) (map[string]string, []int) {
+	var (
+		cmap   = map[string]string{}
+		widths []int
+		l      = &lexer{b: data}
+		seen   = map[int]bool{}
+	)
+	for {
+		t := l.token()
+		k, _ := t.(keyword)
+		switch k {
+		case "":
+			if _, ok := t.(keyword); ok {
+				return cmap, widths
+			}
+		case "begincodespacerange":
+			for {
+				lo, ok := l.value().([]byte)
+				if !ok {
+					break
+				}
+				l.value()
+				if !seen[len(lo)] {
+					seen[len(lo)] = true
+					widths = append(widths, len(lo))
+				}
+			}
+		case "beginbfchar":
+			for {
+				src, ok := l.value().([]byte)
+				if !ok {
+					break
+				}
+				if dst, ok := l.value().([]byte); ok {
+					cmap[string(src)] = utf16String(dst)
+				}
+			}
+		case "beginbfrange":
+			for {
+				lo, ok := l.value().([]byte)
+				if !ok {
+					break
+				}
+				hi, _ := l.value().([]byte)
+				dst := l.value()
+				if len(hi) != len(lo) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				from, to := codeValue(lo), codeValue(hi)
+				for c := from; c <= to && c-from < 65536; c++ {
+					code := string(codeBytes(c, len(lo)))
+					switch d := dst.(type) {
+					case []byte:
+						target := append([]byte(nil), d...)
+						if len(target) > 0 {
+							target[len(target)-1] += byte(c - from)
+						}
+						cmap[code] = utf16String(target)
+					case array:
+						if n := int(c - from); n < len(d) {
+							if b, ok := d[n].([]byte); ok {
+								cmap[code] = utf16String(b)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func codeValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func codeBytes(v uint32, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+func utf16String(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// pdfDocString text string of metadata or a simple font, UTF-16BE with BOM or PDFDocEncoding (taken as Latin-1)
+func pdfDocString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return utf16String(b[2:])
+	}
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// textWriter collects text of a page, lines are broken when the baseline changes
+type textWriter struct {
+	strings.Builder
+	y     float64
+	begun bool
+}
+
+func (w *textWriter) moveTo(y float64) {
+	if w.begun && math.Abs(y-w.y) > 0.5 {
+		w.newline()
+	}
+	w.y, w.begun = y, true
+}
+
+func (w *textWriter) newline() {
+	if w.Len() > 0 && !strings.HasSuffix(w.String(), "\n") {
+		w.WriteByte('\n')
+	}
+}
+
+func (w *textWriter) space() {
+	if s := w.String(); w.Len() > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.WriteByte(' ')
+	}
+}
+
+// content interprets text operators of the content stream, form XObjects are followed
+func (r *reader) content(data []byte, resources dict, w *textWriter, depth int) {
+	var (
+		l        = &lexer{b: data}
+		operands []interface{}
+		fonts    = r.dict(resources["Font"])
+		current  = &font{}
+		cache    = map[name]*font{}
+		leading  float64
+		lineY    float64 // y of the text line matrix
+	)
+	for {
+		t := l.value()
+		op, ok := t.(keyword)
+		if !ok {
+			operands = append(operands, t)
+			continue
+		}
+		if op == "" {
+			return
+		}
+		number := func(i int) float64 {
+			if i < len(operands) {
+				v, _ := operands[i].(float64)
+				return v
+			}
+			return 0
+		}
+		switch op {
+		case "BI": // inline image, skip its data
+			if end := strings.Index(string(data[l.pos:]), "EI"); end != -1 {
+				l.pos += end + 2
+			}
+		case "BT":
+			lineY = 0
+		case "Tf":
+			if len(operands) > 0 {
+				if n, ok := operands[0].(name); ok {
+					if cache[n] == nil {
+						cache[n] = r.font(fonts[n])
+					}
+					current = cache[n]
+				}
+			}
+		case "TL":
+			leading = number(0)
+		case "Td", "TD":
+			if op == "TD" {
+				leading = -number(1)
+			}
+			lineY += number(1)
+			w.moveTo(lineY)
+		case "Tm":
+			lineY = number(5)
+			w.moveTo(lineY)
+		case "T*":
+			lineY -= leading
+			w.newline()
+		case "Tj":
+			if len(operands) > 0 {
+				if b, ok := operands[0].([]byte); ok {
+					w.WriteString(current.decode(b))
+				}
+			}
+		case "'", "\"":
+			w.newline()
+			if len(operands) > 0 {
+				if b, ok := operands[len(operands)-1].([]byte); ok {
+					w.WriteString(current.decode(b))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				a, _ := operands[0].(array)
+				for _, item := range a {
+					switch v := item.(type) {
+					case []byte:
+						w.WriteString(current.decode(v))
+					case float64:
+						if v < -200 { // a gap wider than ~1/5 of the font size is a word break
+							w.space()
+						}
+					}
+				}
+			}
+		case "ET":
+			w.space()
+		case "Do":
+			if len(operands) > 0 && depth < 8 {
+				n, _ := operands[0].(name)
+				xobject, ok := r.resolve(r.dict(resources["XObject"])[n]).(*stream)
+				if ok && xobject.dict["Subtype"] == name("Form") {
+					if data, err := r.decode(xobject); err == nil {
+						inner := r.dict(xobject.dict["Resources"])
+						if inner == nil {
+							inner = resources
+						}
+						r.content(data, inner, w, depth+1)
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
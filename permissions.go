@@ -0,0 +1,47 @@
+package control
+
+import (
+	"github.com/ecwid/control/protocol/browser"
+	"github.com/ecwid/control/protocol/common"
+)
+
+// Frequently used permission types, see browser.PermissionType for the full list
+const (
+	PermissionVideoCapture  browser.PermissionType = "videoCapture"
+	PermissionAudioCapture  browser.PermissionType = "audioCapture"
+	PermissionNotifications browser.PermissionType = "notifications"
+	PermissionGeolocation   browser.PermissionType = "geolocation"
+	PermissionClipboardRead browser.PermissionType = "clipboardReadWrite"
+)
+
+// GrantPermissions grants permissions to origin (to all origins if empty) within the session's browser context
+func (s Session) GrantPermissions(origin string, permissions ...browser.PermissionType) error {
+	contextID, err := s.browserContextID()
+	if err != nil {
+		return err
+	}
+	return browser.GrantPermissions(s.browser, browser.GrantPermissionsArgs{
+		Permissions:      permissions,
+		Origin:           origin,
+		BrowserContextId: contextID,
+	})
+}
+
+// ResetPermissions resets all permissions of the session's browser context
+func (s Session) ResetPermissions() error {
+	contextID, err := s.browserContextID()
+	if err != nil {
+		return err
+	}
+	return browser.ResetPermissions(s.browser, browser.ResetPermissionsArgs{
+		BrowserContextId: contextID,
+	})
+}
+
+func (s Session) browserContextID() (common.BrowserContextID, error) {
+	info, err := s.TargetInfo()
+	if err != nil {
+		return "", err
+	}
+	return info.BrowserContextId, nil
+}
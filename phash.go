@@ -0,0 +1,121 @@
+package control
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// ImageHash 64-bit perceptual hash of an image, similar images have hashes with a small Distance
+type ImageHash uint64
+
+// Distance number of differing bits: 0 - the same picture, a few bits - the same picture with noise
+// (anti-aliasing, compression, slight scaling), about 32 - unrelated pictures
+func (h ImageHash) Distance(other ImageHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func (h ImageHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// PerceptualHash pHash of the element's screenshot
+func (e Element) PerceptualHash() (ImageHash, error) {
+	b, err := e.Screenshot("png", 0)
+	if err != nil {
+		return 0, err
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	return PerceptualHash(img), nil
+}
+
+// PerceptualHash pHash of the image: the low frequencies of DCT of the 32x32 grayscale thumbnail compared with their median
+func PerceptualHash(img image.Image) ImageHash {
+	const size, low = 32, 8
+	var (
+		pixels = resizeGray(img, size, size)
+		dct    [low * low]float64
+	)
+	for u := 0; u < low; u++ {
+		for v := 0; v < low; v++ {
+			var sum float64
+			for x := 0; x < size; x++ {
+				for y := 0; y < size; y++ {
+					sum += pixels[y*size+x] *
+						math.Cos(float64(2*x+1)*float64(u)*math.Pi/(2*size)) *
+						math.Cos(float64(2*y+1)*float64(v)*math.Pi/(2*size))
+				}
+			}
+			dct[v*low+u] = sum
+		}
+	}
+	// the DC term is the average brightness, it's left out of the median
+	sorted := append([]float64(nil), dct[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	var hash ImageHash
+	for n, value := range dct {
+		if value > median {
+			hash |= 1 << uint(n)
+		}
+	}
+	return hash
+}
+
+// DifferenceHash dHash of the image: brightness gradients between neighbouring pixels of the 9x8 grayscale thumbnail.
+// It's cheaper than PerceptualHash and is fine for detecting changes of the same layout
+func DifferenceHash(img image.Image) ImageHash {
+	const width, height = 9, 8
+	pixels := resizeGray(img, width, height)
+	var hash ImageHash
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			if pixels[y*width+x] > pixels[y*width+x+1] {
+				hash |= 1 << uint(y*(width-1)+x)
+			}
+		}
+	}
+	return hash
+}
+
+// resizeGray box-filtered grayscale thumbnail of the image in row-major order
+func resizeGray(img image.Image, width, height int) []float64 {
+	var (
+		r      = img.Bounds()
+		sums   = make([]float64, width*height)
+		counts = make([]float64, width*height)
+	)
+	if r.Empty() {
+		return sums
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		ty := (y - r.Min.Y) * height / r.Dy()
+		for x := r.Min.X; x < r.Max.X; x++ {
+			tx := (x - r.Min.X) * width / r.Dx()
+			sums[ty*width+tx] += luminance(img, x, y)
+			counts[ty*width+tx]++
+		}
+	}
+	for n := range sums {
+		if counts[n] > 0 {
+			sums[n] /= counts[n]
+			continue
+		}
+		// the image is smaller than the thumbnail
+		x, y := n%width, n/width
+		sums[n] = luminance(img, r.Min.X+x*r.Dx()/width, r.Min.Y+y*r.Dy()/height)
+	}
+	return sums
+}
+
+func luminance(img image.Image, x, y int) float64 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
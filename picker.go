@@ -0,0 +1,77 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ecwid/control/protocol/dom"
+	"github.com/ecwid/control/protocol/overlay"
+	"github.com/ecwid/control/transport"
+)
+
+// PickElement turns on the inspect mode of headful browser and waits until the user clicks an element.
+// Returns the element and its suggested selector
+func (s Session) PickElement(ctx context.Context) (*Element, string, error) {
+	picked := make(chan dom.BackendNodeId, 1)
+	unsubscribe := s.Subscribe("Overlay.inspectNodeRequested", func(e transport.Event) error {
+		var v = overlay.InspectNodeRequested{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		select {
+		case picked <- v.BackendNodeId:
+		default:
+		}
+		return nil
+	})
+	defer unsubscribe()
+	for _, name := range []string{"DOM", "Overlay"} {
+		release, err := s.EnableDomain(name)
+		if err != nil {
+			return nil, "", err
+		}
+		defer release()
+	}
+	err := overlay.SetInspectMode(s, overlay.SetInspectModeArgs{
+		Mode: "searchForNode",
+		HighlightConfig: &overlay.HighlightConfig{
+			ShowInfo:     true,
+			ContentColor: &dom.RGBA{R: 111, G: 168, B: 220, A: 0.66},
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = overlay.SetInspectMode(s, overlay.SetInspectModeArgs{Mode: "none"}) }()
+
+	var backendNodeID dom.BackendNodeId
+	select {
+	case backendNodeID = <-picked:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	case <-s.context.Done():
+		return nil, "", s.context.Err()
+	}
+	val, err := dom.ResolveNode(s, dom.ResolveNodeArgs{BackendNodeId: backendNodeID})
+	if err != nil {
+		return nil, "", err
+	}
+	element, err := s.Page().constructElement(val.Object)
+	if err != nil {
+		return nil, "", err
+	}
+	selector, err := element.Selector()
+	if err != nil {
+		return nil, "", err
+	}
+	return element, selector, nil
+}
+
+// Selector best-guess unique CSS selector of the element (id, test attributes, name or nth-of-type path)
+func (e Element) Selector() (string, error) {
+	val, err := e.CallFunction(functionSelector, true, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return primitiveRemoteObject(*val).String()
+}
@@ -0,0 +1,36 @@
+package control
+
+import (
+	"encoding/json"
+
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/target"
+	"github.com/ecwid/control/transport"
+)
+
+// ClosePopups closes tabs opened by this page (window.open, target=_blank) unless their URL matches one of allowed patterns.
+// Popups on about:blank are checked again as soon as they navigate somewhere
+func (s Session) ClosePopups(allowed ...string) (cancel func()) {
+	return s.Subscribe("*", func(e transport.Event) error {
+		switch e.Method {
+		case "Target.targetCreated", "Target.targetInfoChanged":
+		default:
+			return nil
+		}
+		var v = target.TargetInfoChanged{} // the same payload as Target.targetCreated
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		info := v.TargetInfo
+		if info == nil || info.Type != "page" || info.OpenerId != s.tid || info.Url == "" || info.Url == Blank {
+			return nil
+		}
+		for _, pattern := range allowed {
+			if wildcard.Match(pattern, info.Url) {
+				return nil
+			}
+		}
+		_ = s.browser.CloseTarget(info.TargetId) // popup may be already closed
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package control
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/systeminfo"
+)
+
+// ProcessUsage resources used by a browser process
+type ProcessUsage struct {
+	Type    string // browser, renderer, gpu, utility...
+	PID     int
+	CPUTime time.Duration // cumulative CPU time
+	CPU     float64       // CPU usage since the previous sample (1 = one core), 0 on the first sample
+	RSS     uint64        // resident memory in bytes, 0 if unknown (available for local browser on Linux only)
+	local   bool
+}
+
+// Kill kills the process, a killed renderer makes its targets crashed.
+// Only processes of a browser launched locally by this process can be killed (on Linux), otherwise ErrNotLocalProcess is returned
+func (p ProcessUsage) Kill() error {
+	if !p.local {
+		return ErrNotLocalProcess
+	}
+	process, err := os.FindProcess(p.PID)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
+
+type cpuSample struct {
+	at      time.Time
+	cpuTime time.Duration
+}
+
+// SystemInfo returns CPU and memory usage of the browser processes (SystemInfo.getProcessInfo)
+func (b BrowserContext) SystemInfo() ([]ProcessUsage, error) {
+	val, err := systeminfo.GetProcessInfo(b)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	samples := map[int]cpuSample{}
+	list := make([]ProcessUsage, 0, len(val.ProcessInfo))
+	browserPID := 0
+	for _, p := range val.ProcessInfo {
+		if p.Type == "browser" {
+			browserPID = p.Id
+		}
+	}
+	for _, p := range val.ProcessInfo {
+		usage := ProcessUsage{
+			Type:    p.Type,
+			PID:     p.Id,
+			CPUTime: time.Duration(p.CpuTime * float64(time.Second)),
+			local:   browserPID != 0 && localProcess(p.Id, browserPID),
+		}
+		if usage.local {
+			usage.RSS = residentMemory(p.Id)
+		}
+		if prev, ok := b.state.cpuSamples[p.Id]; ok && now.After(prev.at) {
+			usage.CPU = float64(usage.CPUTime-prev.cpuTime) / float64(now.Sub(prev.at))
+		}
+		samples[p.Id] = cpuSample{at: now, cpuTime: usage.CPUTime}
+		list = append(list, usage)
+	}
+	b.state.cpuSamples = samples
+	return list, nil
+}
+
+// ProcessLimits zero value means no limit
+type ProcessLimits struct {
+	CPU float64
+	RSS uint64
+}
+
+// MonitorProcesses samples SystemInfo with the interval and calls alert for every process exceeding the limits,
+// so runaway pages can be killed before they take down the host. ErrInterval is returned if the interval isn't positive
+func (b BrowserContext) MonitorProcesses(interval time.Duration, limits ProcessLimits, alert func(ProcessUsage)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, ErrInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			case <-b.Client.Context().Done():
+				return
+			}
+			list, err := b.SystemInfo()
+			if err != nil {
+				b.internalError(err)
+				continue
+			}
+			for _, p := range list {
+				if (limits.CPU > 0 && p.CPU > limits.CPU) || (limits.RSS > 0 && p.RSS > limits.RSS) {
+					alert(p)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
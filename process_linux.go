@@ -0,0 +1,60 @@
+//go:build linux
+// +build linux
+
+package control
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// residentMemory reads RSS of the local process from procfs
+func residentMemory(pid int) uint64 {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+// localProcess reports whether pid belongs to the process group of the browser process browserPID
+// and the browser is a child of this process, i.e. the browser was launched locally by chrome.Launch.
+// Process IDs reported by a remote browser mean nothing on this host
+func localProcess(pid, browserPID int) bool {
+	browserParent, _, ok := processStat(browserPID)
+	if !ok || browserParent != os.Getpid() {
+		return false
+	}
+	_, group, ok := processStat(pid)
+	return ok && group == browserPID
+}
+
+// processStat parent ID and process group ID of the process from procfs
+func processStat(pid int) (parent, group int, ok bool) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, false
+	}
+	// the command name in parentheses may contain spaces, the fields after it are state, ppid, pgrp...
+	n := strings.LastIndexByte(string(b), ')')
+	if n == -1 {
+		return 0, 0, false
+	}
+	fields := strings.Fields(string(b[n+1:]))
+	if len(fields) < 3 {
+		return 0, 0, false
+	}
+	parent, err1 := strconv.Atoi(fields[1])
+	group, err2 := strconv.Atoi(fields[2])
+	return parent, group, err1 == nil && err2 == nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package control
+
+func residentMemory(int) uint64 {
+	return 0
+}
+
+func localProcess(int, int) bool {
+	return false
+}
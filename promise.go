@@ -69,6 +69,16 @@ func (u Future) Get(timeout time.Duration) (interface{}, error) {
 	return u.promise.value, u.promise.error
 }
 
+// GetContext waits for the future until ctx is done, ctx.Err() is returned in the latter case
+func (u Future) GetContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-u.promise.context.Done():
+		return u.promise.value, u.promise.error
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (s Session) Observe(method string, condition func(transport.Event, func(interface{}), func(error))) Future {
 	u := &promise{
 		state: pending,
@@ -77,11 +87,24 @@ func (s Session) Observe(method string, condition func(transport.Event, func(int
 		error: nil,
 	}
 	u.context, u.cancel = context.WithCancel(s.context)
-	u.unregister = s.Subscribe(method, func(e transport.Event) error {
+	// the future fails rather than waits for the event it may have missed
+	u.unregister = s.subscribe(method, func(e transport.Event) error {
 		if u.isPending() {
 			condition(e, u.resolve, u.reject)
 		}
 		return nil
-	})
+	}, u.reject)
+	return Future{u}
+}
+
+// rejectedFuture is a future which is already rejected with the error
+func rejectedFuture(err error) Future {
+	u := &promise{
+		state:      pending,
+		mutex:      sync.Mutex{},
+		unregister: func() {},
+	}
+	u.context, u.cancel = context.WithCancel(context.Background())
+	u.reject(err)
 	return Future{u}
 }
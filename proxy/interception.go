@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/ecwid/control/internal/conditional"
+)
+
+type Stage int
+
+const (
+	StageRequest  Stage = iota // before the request is sent to the server
+	StageResponse              // after the response headers are received
+)
+
+type Handler func(*Interception)
+
+// Interception request (and response at StageResponse) paused by the proxy.
+// If a handler neither calls Abort nor Fulfill the request continues as is (with possible modifications of Request/Response)
+type Interception struct {
+	Request  *http.Request
+	Response *http.Response // nil at StageRequest
+	aborted  bool
+	done     bool
+}
+
+func (i *Interception) decided() bool {
+	return i.aborted || i.done
+}
+
+// Continue lets the request go as is skipping the rest of handlers
+func (i *Interception) Continue() {
+	i.done = true
+}
+
+// Abort fails the request with network error
+func (i *Interception) Abort() {
+	i.aborted = true
+}
+
+// Fulfill provides response to the request. A 200 response is turned into 304 Not Modified
+// if the request's If-None-Match or If-Modified-Since matches ETag or Last-Modified of the header
+func (i *Interception) Fulfill(status int, header http.Header, body []byte) {
+	if header == nil {
+		header = http.Header{}
+	}
+	if status == http.StatusOK && conditional.NotModified(i.Request.Method, i.Request.Header, header) {
+		status, header, body = http.StatusNotModified, conditional.Header(header), nil
+	}
+	if status != http.StatusNotModified {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	if i.Response != nil {
+		_ = i.Response.Body.Close()
+	}
+	i.Response = &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       i.Request,
+	}
+	i.done = true
+}
+
+// Body reads the whole response body (StageResponse only) keeping it available for the browser.
+// Note that reading the body disables streaming for this response
+func (i *Interception) Body() ([]byte, error) {
+	if i.Response == nil {
+		return nil, nil
+	}
+	b, err := ioutil.ReadAll(i.Response.Body)
+	_ = i.Response.Body.Close()
+	i.Response.Body = ioutil.NopCloser(bytes.NewReader(b))
+	i.Response.ContentLength = int64(len(b))
+	return b, err
+}
@@ -0,0 +1,316 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ecwid/control/internal/wildcard"
+)
+
+var ErrAborted = errors.New("request aborted by interceptor")
+
+// Proxy is a local MITM proxy the browser can be pointed at.
+// Unlike Fetch interception it sees all browser traffic including service worker fetches and streamed responses
+type Proxy struct {
+	listener  net.Listener
+	server    *http.Server
+	Transport *http.Transport // used to reach upstream servers
+	key       *ecdsa.PrivateKey
+	certs     *sync.Map // host -> *tls.Certificate
+	mx        sync.Mutex
+	guid      uint64
+	routes    map[uint64]route
+	rotation  *Rotation
+}
+
+type route struct {
+	pattern string
+	stage   Stage
+	handler Handler
+}
+
+// Listen starts a proxy on addr, use "127.0.0.1:0" to pick a free port
+func Listen(addr string) (*Proxy, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		listener: listener,
+		key:      key,
+		certs:    &sync.Map{},
+		routes:   map[uint64]route{},
+		Transport: &http.Transport{
+			ForceAttemptHTTP2:   false,
+			MaxIdleConnsPerHost: 16,
+		},
+	}
+	p.server = &http.Server{Handler: p}
+	go func() { _ = p.server.Serve(listener) }()
+	return p, nil
+}
+
+// Addr address the proxy is listening on
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Flags returns chrome command line flags to route the browser traffic through this proxy,
+// pass the proxy as chrome.LaunchOptions.MITMProxy to have them added on launch
+func (p *Proxy) Flags() ([]string, error) {
+	spki, err := x509.MarshalPKIXPublicKey(&p.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(spki)
+	return []string{
+		"--proxy-server=http://" + p.Addr(),
+		"--proxy-bypass-list=<-loopback>",
+		"--ignore-certificate-errors-spki-list=" + base64.StdEncoding.EncodeToString(hash[:]),
+	}, nil
+}
+
+// Intercept registers handler for requests matching URL pattern at the given stage.
+// Wildcards ('*' -> zero or more, '?' -> exactly one) are allowed, an empty pattern is equivalent to "*"
+func (p *Proxy) Intercept(pattern string, stage Stage, handler Handler) (cancel func()) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	uid := atomic.AddUint64(&p.guid, 1)
+	p.routes[uid] = route{pattern: pattern, stage: stage, handler: handler}
+	return func() {
+		p.mx.Lock()
+		defer p.mx.Unlock()
+		delete(p.routes, uid)
+	}
+}
+
+// Close stops the proxy
+func (p *Proxy) Close() error {
+	p.Transport.CloseIdleConnections()
+	if r := p.getRotation(); r != nil {
+		r.closeIdleConnections()
+	}
+	return p.server.Close()
+}
+
+func (p *Proxy) handlers(url string, stage Stage) []Handler {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	var list []Handler
+	for _, r := range p.routes {
+		if r.stage == stage && wildcard.Match(r.pattern, url) {
+			list = append(list, r.handler)
+		}
+	}
+	return list
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a proxy server", http.StatusBadRequest)
+		return
+	}
+	if isUpgrade(r) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "hijacking is not supported", http.StatusInternalServerError)
+			return
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = p.tunnel(conn, r)
+		return
+	}
+	resp, err := p.roundTrip(r)
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	defer resp.Body.Close()
+	removeHopHeaders(resp.Header)
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(flushWriter{w}, resp.Body)
+}
+
+func (p *Proxy) serveConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking is not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if _, err = io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+	host := r.URL.Hostname()
+	secure := tls.Server(conn, &tls.Config{
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.certificate(hello.ServerName)
+			}
+			return p.certificate(host)
+		},
+	})
+	if err = secure.Handshake(); err != nil {
+		return
+	}
+	defer secure.Close()
+	reader := bufio.NewReader(secure)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = r.Host
+		if isUpgrade(req) {
+			_ = p.tunnel(secure, req)
+			return
+		}
+		resp, err := p.roundTrip(req)
+		if err != nil {
+			return
+		}
+		removeHopHeaders(resp.Header)
+		err = resp.Write(secure)
+		resp.Body.Close()
+		if err != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+func (p *Proxy) roundTrip(r *http.Request) (*http.Response, error) {
+	r.RequestURI = ""
+	removeHopHeaders(r.Header)
+	url := r.URL.String()
+
+	i := &Interception{Request: r}
+	for _, h := range p.handlers(url, StageRequest) {
+		if h(i); i.decided() {
+			break
+		}
+	}
+	if i.aborted {
+		return nil, ErrAborted
+	}
+	if i.Response != nil {
+		return i.Response, nil
+	}
+
+	resp, err := p.upstreamRoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	i = &Interception{Request: r, Response: resp}
+	for _, h := range p.handlers(url, StageResponse) {
+		if h(i); i.decided() {
+			break
+		}
+	}
+	if i.aborted {
+		resp.Body.Close()
+		return nil, ErrAborted
+	}
+	return i.Response, nil
+}
+
+// tunnel passes an upgraded connection (websocket) through as is
+func (p *Proxy) tunnel(client net.Conn, r *http.Request) error {
+	var (
+		upstream net.Conn
+		err      error
+		addr     = r.URL.Host
+	)
+	if r.URL.Port() == "" {
+		if r.URL.Scheme == "https" || r.URL.Scheme == "wss" {
+			addr += ":443"
+		} else {
+			addr += ":80"
+		}
+	}
+	if upstream, err = p.dial(addr); err != nil {
+		return err
+	}
+	if r.URL.Scheme == "https" || r.URL.Scheme == "wss" {
+		secure := tls.Client(upstream, &tls.Config{ServerName: r.URL.Hostname()})
+		if err = secure.Handshake(); err != nil {
+			upstream.Close()
+			return err
+		}
+		upstream = secure
+	}
+	defer upstream.Close()
+	r.RequestURI = ""
+	if err = r.Write(upstream); err != nil {
+		return err
+	}
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(upstream, client); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(client, upstream); done <- struct{}{} }()
+	<-done
+	return nil
+}
+
+func isUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+var hopHeaders = []string{
+	"Proxy-Connection",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Keep-Alive",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (f flushWriter) Write(b []byte) (int, error) {
+	n, err := f.w.Write(b)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
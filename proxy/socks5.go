@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// dialSOCKS5 connects to addr through the SOCKS5 proxy (RFC 1928) with username/password authentication (RFC 1929)
+// if the proxy URL has credentials. The host name is resolved by the proxy
+func dialSOCKS5(proxy *url.URL, addr string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 0xffff {
+		return nil, fmt.Errorf("invalid port of %s", addr)
+	}
+	if len(host) > 255 {
+		return nil, fmt.Errorf("host name of %s is too long", addr)
+	}
+	server := proxy.Host
+	if proxy.Port() == "" {
+		server += ":1080"
+	}
+	conn, err := net.Dial("tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	if err = socks5Connect(conn, proxy.User, host, port); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("socks5 upstream %s: %w", proxy.Host, err)
+	}
+	return conn, nil
+}
+
+func socks5Connect(conn net.Conn, user *url.Userinfo, host string, port int) error {
+	methods := []byte{5, 1, 0} // no authentication
+	if user != nil {
+		methods = []byte{5, 2, 0, 2} // or username/password
+	}
+	if _, err := conn.Write(methods); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 5 {
+		return errors.New("not a SOCKS5 server")
+	}
+	switch reply[1] {
+	case 0:
+	case 2:
+		if user == nil {
+			return errors.New("authentication is required")
+		}
+		username := user.Username()
+		password, _ := user.Password()
+		if len(username) > 255 || len(password) > 255 {
+			return errors.New("credentials are too long")
+		}
+		auth := append([]byte{1, byte(len(username))}, username...)
+		auth = append(append(auth, byte(len(password))), password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0 {
+			return errors.New("authentication failed")
+		}
+	default:
+		return errors.New("no acceptable authentication method")
+	}
+
+	req := []byte{5, 1, 0} // CONNECT
+	if ip := net.ParseIP(host); ip == nil {
+		req = append(append(req, 3, byte(len(host))), host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(append(req, 1), ip4...)
+	} else {
+		req = append(append(req, 4), ip.To16()...)
+	}
+	req = append(req, 0, 0)
+	binary.BigEndian.PutUint16(req[len(req)-2:], uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return err
+	}
+	if head[1] != 0 {
+		return fmt.Errorf("CONNECT failed with code %d", head[1])
+	}
+	// skip the bound address
+	var skip int
+	switch head[3] {
+	case 1:
+		skip = net.IPv4len
+	case 4:
+		skip = net.IPv6len
+	case 3:
+		n := make([]byte, 1)
+		if _, err := io.ReadFull(conn, n); err != nil {
+			return err
+		}
+		skip = int(n[0])
+	default:
+		return fmt.Errorf("unknown address type %d", head[3])
+	}
+	_, err := io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
@@ -0,0 +1,23 @@
+package control
+
+import "fmt"
+
+// scriptSeedRandom replaces Math.random with mulberry32 seeded by the first argument,
+// the second one enables the same for crypto.getRandomValues and crypto.randomUUID
+const scriptSeedRandom = `((seed,crypto)=>{let s=seed>>>0;const r=()=>{s=s+0x6D2B79F5|0;let t=Math.imul(s^s>>>15,1|s);t=t+Math.imul(t^t>>>7,61|t)^t;return((t^t>>>14)>>>0)/4294967296};
+Math.random=r;if(!crypto||!window.Crypto)return;const c=Crypto.prototype,o=c.getRandomValues;
+c.getRandomValues=function(a){o.call(this,a);const b=new Uint8Array(a.buffer,a.byteOffset,a.byteLength);for(let i=0;i<b.length;i++)b[i]=r()*256|0;return a};
+if(c.randomUUID)c.randomUUID=function(){const b=this.getRandomValues(new Uint8Array(16));b[6]=b[6]&15|64;b[8]=b[8]&63|128;
+const h=Array.from(b,x=>x.toString(16).padStart(2,"0")).join("");return h.slice(0,8)+"-"+h.slice(8,12)+"-"+h.slice(12,16)+"-"+h.slice(16,20)+"-"+h.slice(20)}})(%d,%t)`
+
+// SeedRandom replaces Math.random (and crypto.getRandomValues with crypto.randomUUID if crypto is set) with a PRNG seeded by seed,
+// so pages which shuffle or sample data render the same way across runs. Every document starts the sequence anew.
+// It applies to documents loaded after the call, so call it before Navigate
+func (s Session) SeedRandom(seed int64, crypto bool) (cancel func(), err error) {
+	script := fmt.Sprintf(scriptSeedRandom, uint32(seed^seed>>32), crypto)
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(script)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = s.RemoveScriptToEvaluateOnNewDocument(identifier) }, nil
+}
@@ -0,0 +1,40 @@
+package control
+
+import (
+	"math/rand"
+	"time"
+)
+
+// SetRateLimit limits actions (Click, Type, InsertText, Navigate) of the session to actionsPerSecond
+// and adds a random delay up to jitter before each of them, so scrapers can mimic human pacing.
+// Zero values disable the limit
+func (s Session) SetRateLimit(actionsPerSecond float64, jitter time.Duration) {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	s.config.actionInterval = 0
+	if actionsPerSecond > 0 {
+		s.config.actionInterval = time.Duration(float64(time.Second) / actionsPerSecond)
+	}
+	s.config.actionJitter = jitter
+}
+
+// throttle blocks until the next action is allowed
+func (s Session) throttle() {
+	s.config.mx.Lock()
+	interval, jitter := s.config.actionInterval, s.config.actionJitter
+	if interval == 0 && jitter == 0 {
+		s.config.mx.Unlock()
+		return
+	}
+	var wait time.Duration
+	now := time.Now()
+	if s.config.nextAction.After(now) {
+		wait = s.config.nextAction.Sub(now)
+	}
+	if jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(jitter)))
+	}
+	s.config.nextAction = now.Add(wait + interval)
+	s.config.mx.Unlock()
+	time.Sleep(wait)
+}
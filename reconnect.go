@@ -0,0 +1,163 @@
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/fetch"
+	"github.com/ecwid/control/protocol/target"
+	"github.com/ecwid/control/transport"
+)
+
+// sessionID is shared by copies of Session, it changes when the target is attached again after reconnection
+type sessionID struct {
+	mx      sync.Mutex
+	value   target.SessionID
+	gen     uint64        // generation of the client's connection the session is ready on, see transport.Client.Generation
+	changed chan struct{} // closed and replaced when the session is ready again or re-attaching fails
+	err     error         // re-attaching failed
+}
+
+func newSessionID(value target.SessionID, gen uint64) *sessionID {
+	return &sessionID{value: value, gen: gen, changed: make(chan struct{})}
+}
+
+func (i *sessionID) get() target.SessionID {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	return i.value
+}
+
+// set the new ID, events are routed by it at once but the session isn't ready for commands until ready is called
+func (i *sessionID) set(value target.SessionID) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.value = value
+}
+
+func (i *sessionID) ready(gen uint64) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.gen = gen
+	close(i.changed)
+	i.changed = make(chan struct{})
+}
+
+func (i *sessionID) fail(err error) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.err = err
+	close(i.changed)
+	i.changed = make(chan struct{})
+}
+
+func (i *sessionID) failure() error {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	return i.err
+}
+
+// current returns the ID and the generation of the connection once the session is ready on the current connection of the client,
+// commands sent with the ID of the lost connection would fail with a protocol error
+func (i *sessionID) current(ctx context.Context, client *transport.Client) (target.SessionID, uint64, error) {
+	var timer *time.Timer
+	for {
+		i.mx.Lock()
+		value, gen, changed, err := i.value, i.gen, i.changed, i.err
+		i.mx.Unlock()
+		if err != nil {
+			return "", 0, err
+		}
+		if gen == client.Generation() {
+			return value, gen, nil
+		}
+		if timer == nil {
+			timer = time.NewTimer(client.Timeout)
+			defer timer.Stop()
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return "", 0, ctx.Err()
+		case <-client.Context().Done():
+			return "", 0, client.Context().Err()
+		case <-timer.C:
+			return "", 0, transport.ErrReconnected
+		}
+	}
+}
+
+// attachingCaller sends commands with the ID of the session being attached again, before the session is ready for its users
+type attachingCaller struct {
+	browser BrowserContext
+	id      target.SessionID
+}
+
+func (c attachingCaller) Call(method string, send, recv interface{}) error {
+	return c.browser.call(string(c.id), method, send, recv)
+}
+
+// reattach attaches live sessions to their targets again when the client has reconnected (see transport.DialOptions.Reconnect),
+// so sessions keep working with their subscriptions. Commands of the sessions wait until they are attached.
+// Sessions of targets which are gone are closed with ErrDetachedFromTarget
+func (b BrowserContext) reattach() {
+	gen := b.Client.Generation()
+	b.state.mx.Lock()
+	sessions := make([]*Session, 0, len(b.state.sessions))
+	for _, s := range b.state.sessions {
+		sessions = append(sessions, s)
+	}
+	b.state.mx.Unlock()
+	for _, s := range sessions {
+		if err := s.reattach(); err != nil {
+			b.internalError(SessionError{SessionID: s.ID(), Err: err})
+			s.id.fail(ErrDetachedFromTarget)
+			s.cancelCtx()
+			continue
+		}
+		s.id.ready(gen)
+	}
+}
+
+// reattach attaches to the target with a new session ID and restores enabled domains and interception.
+// Other protocol state of the lost session (emulation, scripts to evaluate on new document, etc.) is not restored
+func (s *Session) reattach() error {
+	val, err := target.AttachToTarget(s.browser, target.AttachToTargetArgs{TargetId: s.tid, Flatten: true})
+	if err != nil {
+		return err
+	}
+	prev := s.id.get()
+	s.id.set(val.SessionId)
+	s.browser.state.mx.Lock()
+	delete(s.browser.state.sessions, prev)
+	s.browser.state.sessions[val.SessionId] = s
+	s.browser.state.mx.Unlock()
+
+	// the state is read under locks which users never hold while calling the browser:
+	// a user in the middle of EnableDomain or Intercept waits for the session to be ready, its call then goes to the new session
+	caller := attachingCaller{browser: s.browser, id: val.SessionId}
+	for _, name := range s.domains.enabled() {
+		if err = domainSwitches[name].enable(caller); err != nil {
+			return err
+		}
+	}
+	if err = setup(caller); err != nil {
+		return err
+	}
+	if args := s.interceptor.fetchState(); args != nil {
+		return fetch.Enable(caller, *args)
+	}
+	return nil
+}
+
+// closedErr reason the session is closed
+func (s Session) closedErr() error {
+	if err := s.id.failure(); err != nil {
+		return err
+	}
+	if s.exitCode != nil {
+		return s.exitCode
+	}
+	return s.context.Err()
+}
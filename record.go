@@ -0,0 +1,72 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+const bindRecord = "_on_record"
+
+// scriptRecord reports user's interactions to the binding, values of password fields are not sent
+var scriptRecord = fmt.Sprintf(`(()=>{if(window.__control_record||window!==window.top)return;window.__control_record=!0;const sel=%s,
+r=(type,e,value)=>{const secret=e.type==="password";try{window._on_record(JSON.stringify({type:type,selector:sel(e),value:value===undefined||secret?"":String(value),secret:secret}))}catch(x){}};
+document.addEventListener("click",e=>{const t=e.target.closest("a,button,input,select,textarea,label,[role],[onclick]")||e.target;if(e.isTrusted&&!(t.localName==="select"||t.localName==="input"&&!["checkbox","radio","submit","button"].includes(t.type)||t.localName==="textarea"))r("click",t)},!0);
+document.addEventListener("change",e=>{const t=e.target;if(!e.isTrusted)return;if(t.localName==="select")r("select",t,Array.from(t.selectedOptions).map(o=>o.value).join("\n"));else if(t.localName==="textarea"||t.localName==="input"&&!["checkbox","radio","submit","button","file"].includes(t.type))r("input",t,t.value)},!0);
+document.addEventListener("keydown",e=>{if(e.isTrusted&&e.key==="Enter"&&e.target.localName==="input")r("input",e.target,e.target.value),r("enter",e.target)},!0)})()`, atomSelectorOf)
+
+// Action types reported by Session.Record
+const (
+	ActionNavigate = "navigate"
+	ActionClick    = "click"
+	ActionInput    = "input"  // text field value is committed (change event)
+	ActionSelect   = "select" // Value is newline separated selected values
+	ActionEnter    = "enter"  // Enter pressed in a text field
+)
+
+// Action user's interaction with the page
+type Action struct {
+	Type     string    `json:"type"`
+	Selector string    `json:"selector,omitempty"`
+	Value    string    `json:"value,omitempty"`  // typed text, selected values or URL of navigation
+	Secret   bool      `json:"secret,omitempty"` // a password field, its value never leaves the page and Value is empty
+	Time     time.Time `json:"time"`
+}
+
+// Record reports interactions of a human driving the (headful) browser: clicks, typed values, selects and main frame navigations
+func (s Session) Record(handler func(Action)) (cancel func(), err error) {
+	removeBinding, err := s.AddBinding(bindRecord, func(payload string) {
+		var a Action
+		if json.Unmarshal([]byte(payload), &a) == nil {
+			a.Time = time.Now()
+			handler(a)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(scriptRecord)
+	if err != nil {
+		removeBinding()
+		return nil, err
+	}
+	_, _ = s.Page().Evaluate(scriptRecord, false, false)
+	unsubscribe := s.Subscribe("Page.frameNavigated", func(e transport.Event) error {
+		var v = page.FrameNavigated{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		if v.Frame.ParentId == "" && v.Frame.Id == s.Page().id {
+			handler(Action{Type: ActionNavigate, Value: v.Frame.Url, Time: time.Now()})
+		}
+		return nil
+	})
+	return func() {
+		unsubscribe()
+		removeBinding()
+		_ = s.RemoveScriptToEvaluateOnNewDocument(identifier)
+	}, nil
+}
@@ -0,0 +1,160 @@
+// Package recorder records interactions of a human driving a headful browser and generates Go code replaying them
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control"
+)
+
+// navigations which follow an interaction within this interval are considered to be caused by it
+const causedNavigation = time.Second * 5
+
+type Recorder struct {
+	mx      sync.Mutex
+	actions []control.Action
+	cancel  func()
+}
+
+// Start records actions in the session until Stop is called
+func Start(session *control.Session) (*Recorder, error) {
+	r := &Recorder{}
+	cancel, err := session.Record(r.add)
+	if err != nil {
+		return nil, err
+	}
+	r.cancel = cancel
+	return r, nil
+}
+
+func (r *Recorder) add(a control.Action) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	// the field is committed again (e.g. change then Enter), keep the latest value only
+	if n := len(r.actions); n > 0 && a.Type == control.ActionInput {
+		if last := r.actions[n-1]; last.Type == control.ActionInput && last.Selector == a.Selector {
+			r.actions[n-1] = a
+			return
+		}
+	}
+	r.actions = append(r.actions, a)
+}
+
+func (r *Recorder) Stop() {
+	r.cancel()
+}
+
+// Actions recorded so far
+func (r *Recorder) Actions() []control.Action {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return append([]control.Action(nil), r.actions...)
+}
+
+// WriteGo writes gofmt-ed Go program replaying recorded actions
+func (r *Recorder) WriteGo(w io.Writer) error {
+	src, err := Generate(r.Actions())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// Generate Go program replaying actions. Password values are never written, they are read from environment variables
+func Generate(actions []control.Action) ([]byte, error) {
+	var (
+		b       = &bytes.Buffer{}
+		secrets = 0
+		last    control.Action
+	)
+	b.WriteString(header)
+	for _, a := range actions {
+		switch a.Type {
+		case control.ActionNavigate:
+			if last.Type != "" && last.Type != control.ActionNavigate && a.Time.Sub(last.Time) < causedNavigation {
+				fmt.Fprintf(b, "// navigated to %s\n", strings.ReplaceAll(a.Value, "\n", " "))
+				b.WriteString("if err = page.RequestDOMIdle(time.Millisecond*500, time.Second*30); err != nil {\nreturn err\n}\n")
+			} else {
+				fmt.Fprintf(b, "if err = page.Navigate(%s, control.LifecycleLoad, time.Minute); err != nil {\nreturn err\n}\n", strconv.Quote(a.Value))
+			}
+		case control.ActionClick:
+			query(b, a.Selector)
+			b.WriteString("if err = el.Click(); err != nil {\nreturn err\n}\n")
+		case control.ActionInput:
+			query(b, a.Selector)
+			if a.Secret {
+				secrets++
+				fmt.Fprintf(b, "if err = el.TypeSecret(os.Getenv(\"SECRET_%d\")); err != nil {\nreturn err\n}\n", secrets)
+			} else {
+				fmt.Fprintf(b, "if err = el.InsertText(%s); err != nil {\nreturn err\n}\n", strconv.Quote(a.Value))
+			}
+		case control.ActionSelect:
+			query(b, a.Selector)
+			var values []string
+			for _, v := range strings.Split(a.Value, "\n") {
+				values = append(values, strconv.Quote(v))
+			}
+			fmt.Fprintf(b, "if err = el.SelectValues(%s); err != nil {\nreturn err\n}\n", strings.Join(values, ", "))
+		case control.ActionEnter:
+			b.WriteString("if err = page.Session().Input.PressKey('\\r'); err != nil {\nreturn err\n}\n")
+		default:
+			continue
+		}
+		last = a
+	}
+	b.WriteString(footer)
+	src := b.Bytes()
+	if secrets == 0 {
+		src = bytes.Replace(src, []byte("\t\"os\"\n"), nil, 1)
+	}
+	return format.Source(src)
+}
+
+func query(b *bytes.Buffer, selector string) {
+	fmt.Fprintf(b, "if el, err = page.QuerySelector(%s); err != nil {\nreturn err\n}\n", strconv.Quote(selector))
+}
+
+const header = `// Code generated by recorder. Review the selectors before use.
+
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/ecwid/control"
+	"github.com/ecwid/control/chrome"
+)
+
+func main() {
+	browser, err := chrome.Launch(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	defer browser.Close()
+	session, err := control.New(browser.GetClient()).CreatePageTarget("")
+	if err != nil {
+		panic(err)
+	}
+	if err = scenario(session.Page()); err != nil {
+		panic(err)
+	}
+}
+
+func scenario(page *control.Frame) (err error) {
+var el *control.Element
+_ = el
+`
+
+const footer = `return nil
+}
+`
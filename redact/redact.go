@@ -0,0 +1,266 @@
+// Package redact masks credentials in data which leaves the process: logs, error messages, HAR files and other artifacts
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted values
+const Mask = "****"
+
+// Policy describes what has to be masked. A nil *Policy masks nothing
+type Policy struct {
+	Headers     []string // header names, case-insensitive
+	Cookies     []string // cookie names in Cookie and Set-Cookie headers
+	QueryParams []string // URL query parameters
+	// JSONPaths keys of JSON bodies. A path without dots matches the key at any depth ("password"),
+	// a dotted path is matched from the root where "*" matches any key or array index ("user.*.token")
+	JSONPaths []string
+
+	mx      sync.RWMutex
+	secrets []string
+}
+
+// Default policy which covers common authorization headers, cookies and parameters
+func Default() *Policy {
+	return &Policy{
+		Headers: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			"X-Api-Key",
+			"X-Auth-Token",
+			"X-Csrf-Token",
+		},
+		QueryParams: []string{"access_token", "api_key", "apikey", "token", "password", "secret", "sig", "signature"},
+		JSONPaths:   []string{"password", "access_token", "refresh_token", "id_token", "client_secret", "secret"},
+	}
+}
+
+// AddSecret registers literal value which is masked wherever it appears (e.g. a password typed into a form)
+func (p *Policy) AddSecret(secret string) {
+	if p == nil || secret == "" {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	for _, s := range p.secrets {
+		if s == secret {
+			return
+		}
+	}
+	p.secrets = append(p.secrets, secret)
+}
+
+// RemoveSecret forgets the value registered with AddSecret
+func (p *Policy) RemoveSecret(secret string) {
+	if p == nil {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	for n, s := range p.secrets {
+		if s == secret {
+			p.secrets = append(p.secrets[:n], p.secrets[n+1:]...)
+			return
+		}
+	}
+}
+
+// ClearSecrets forgets all values registered with AddSecret
+func (p *Policy) ClearSecrets() {
+	if p == nil {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.secrets = nil
+}
+
+// String masks registered secrets in s
+func (p *Policy) String(s string) string {
+	if p == nil {
+		return s
+	}
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+	for _, secret := range p.secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// Header returns the header value safe to store
+func (p *Policy) Header(name, value string) string {
+	if p == nil {
+		return value
+	}
+	if contains(p.Headers, name, true) {
+		return Mask
+	}
+	switch {
+	case strings.EqualFold(name, "Cookie"):
+		value = p.cookies(value, "; ")
+	case strings.EqualFold(name, "Set-Cookie"):
+		// only the first pair is the cookie, the rest are attributes
+		n := strings.IndexByte(value, ';')
+		if n == -1 {
+			n = len(value)
+		}
+		value = p.cookies(value[:n], "; ") + value[n:]
+	}
+	return p.String(value)
+}
+
+// HTTPHeader returns redacted copy of h
+func (p *Policy) HTTPHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for name, values := range h {
+		for _, v := range values {
+			c[name] = append(c[name], p.Header(name, v))
+		}
+	}
+	return c
+}
+
+// HeaderMap redacted copy of headers map as reported by the browser (network.Headers)
+func (p *Policy) HeaderMap(h map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(h))
+	for name, v := range h {
+		if s, ok := v.(string); ok {
+			c[name] = p.Header(name, s)
+		} else {
+			c[name] = v
+		}
+	}
+	return c
+}
+
+// URL masks query parameters of the raw URL
+func (p *Policy) URL(raw string) string {
+	if p == nil {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return p.String(raw)
+	}
+	var changed = false
+	pairs := strings.Split(u.RawQuery, "&")
+	for n, pair := range pairs {
+		key := pair
+		if i := strings.IndexByte(pair, '='); i != -1 {
+			key = pair[:i]
+		}
+		if k, err := url.QueryUnescape(key); err == nil && contains(p.QueryParams, k, false) {
+			pairs[n] = key + "=" + Mask
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = strings.Join(pairs, "&")
+	}
+	return p.String(u.String())
+}
+
+// JSON masks JSONPaths of the document, non-JSON input is treated as a plain string
+func (p *Policy) JSON(b []byte) []byte {
+	if p == nil {
+		return b
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return []byte(p.String(string(b)))
+	}
+	out, err := json.Marshal(p.walk(v, nil))
+	if err != nil {
+		return []byte(p.String(string(b)))
+	}
+	return []byte(p.String(string(out)))
+}
+
+// Value returns JSON-serializable value with masked JSONPaths and secrets, e.g. for protocol call arguments
+func (p *Policy) Value(v interface{}) interface{} {
+	if p == nil || v == nil {
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	return json.RawMessage(p.JSON(b))
+}
+
+func (p *Policy) cookies(value, sep string) string {
+	if len(p.Cookies) == 0 {
+		return value
+	}
+	pairs := strings.Split(value, ";")
+	for n, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if i := strings.IndexByte(pair, '='); i != -1 && contains(p.Cookies, pair[:i], false) {
+			pair = pair[:i+1] + Mask
+		}
+		pairs[n] = pair
+	}
+	return strings.Join(pairs, sep)
+}
+
+func (p *Policy) walk(v interface{}, path []string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, value := range t {
+			sub := append(path[:len(path):len(path)], k)
+			if p.matchPath(sub) {
+				t[k] = Mask
+			} else {
+				t[k] = p.walk(value, sub)
+			}
+		}
+	case []interface{}:
+		for n, value := range t {
+			t[n] = p.walk(value, append(path[:len(path):len(path)], "*"))
+		}
+	}
+	return v
+}
+
+func (p *Policy) matchPath(path []string) bool {
+	for _, pattern := range p.JSONPaths {
+		parts := strings.Split(pattern, ".")
+		if len(parts) == 1 {
+			if parts[0] == path[len(path)-1] {
+				return true
+			}
+			continue
+		}
+		if len(parts) != len(path) {
+			continue
+		}
+		matched := true
+		for n := range parts {
+			if parts[n] != "*" && parts[n] != path[n] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string, fold bool) bool {
+	for _, v := range list {
+		if v == s || fold && strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
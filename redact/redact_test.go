@@ -0,0 +1,83 @@
+package redact
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	p := &Policy{Headers: []string{"Authorization"}, Cookies: []string{"sid"}}
+	for _, c := range []struct {
+		name, value, want string
+	}{
+		{"authorization", "Bearer abc", Mask},
+		{"Accept", "text/html", "text/html"},
+		{"Cookie", "sid=abc; theme=dark", "sid=" + Mask + "; theme=dark"},
+		{"Set-Cookie", "sid=abc; Path=/; HttpOnly", "sid=" + Mask + "; Path=/; HttpOnly"},
+		{"Set-Cookie", "theme=dark; Path=/", "theme=dark; Path=/"},
+	} {
+		if got := p.Header(c.name, c.value); got != c.want {
+			t.Errorf("%s: %s: got %q, want %q", c.name, c.value, got, c.want)
+		}
+	}
+	h := p.HTTPHeader(http.Header{"Authorization": {"Basic x"}})
+	if h.Get("Authorization") != Mask {
+		t.Errorf("got %v", h)
+	}
+}
+
+func TestURL(t *testing.T) {
+	p := Default()
+	for _, c := range []struct {
+		url, want string
+	}{
+		{"https://example.com/a?b=1", "https://example.com/a?b=1"},
+		{"https://example.com/a?token=abc&b=1", "https://example.com/a?token=" + Mask + "&b=1"},
+		{"https://example.com/a?Token=abc", "https://example.com/a?Token=abc"}, // names are case-sensitive
+		{"https://example.com/a?api%5Fkey=abc", "https://example.com/a?api%5Fkey=" + Mask},
+	} {
+		if got := p.URL(c.url); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.url, got, c.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	p := &Policy{JSONPaths: []string{"password", "user.*.token"}}
+	for _, c := range []struct {
+		in, want string
+	}{
+		{`{"password":"x","name":"y"}`, `{"name":"y","password":"****"}`},
+		{`{"a":{"b":[{"password":1}]}}`, `{"a":{"b":[{"password":"****"}]}}`},
+		{`{"user":{"home":{"token":"x"},"token":"y"}}`, `{"user":{"home":{"token":"****"},"token":"y"}}`},
+		{`{"user":[{"token":"x"}]}`, `{"user":[{"token":"****"}]}`},
+		{`not json`, `not json`},
+	} {
+		if got := string(p.JSON([]byte(c.in))); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	p := &Policy{}
+	p.AddSecret("hunter2")
+	p.AddSecret("")
+	if got := p.String("password is hunter2"); got != "password is "+Mask {
+		t.Errorf("got %q", got)
+	}
+	p.RemoveSecret("hunter2")
+	if got := p.String("password is hunter2"); got != "password is hunter2" {
+		t.Errorf("removed secret is masked: %q", got)
+	}
+	p.AddSecret("a")
+	p.ClearSecrets()
+	if got := p.String("abc"); got != "abc" {
+		t.Errorf("cleared secret is masked: %q", got)
+	}
+	var none *Policy
+	none.AddSecret("x")
+	if got := none.String("x"); got != "x" {
+		t.Errorf("nil policy masks: %q", got)
+	}
+}
@@ -0,0 +1,13 @@
+package control
+
+import "github.com/ecwid/control/redact"
+
+// SetRedactionPolicy sets policy which masks credentials in logs, error messages, HAR and other captured artifacts
+func (b BrowserContext) SetRedactionPolicy(policy *redact.Policy) {
+	b.Client.SetRedaction(policy)
+}
+
+// RedactionPolicy returns current policy, nil if nothing is redacted
+func (b BrowserContext) RedactionPolicy() *redact.Policy {
+	return b.Client.Redaction()
+}
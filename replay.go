@@ -0,0 +1,133 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ecwid/control/protocol/target"
+	"github.com/ecwid/control/transport"
+)
+
+// Replay re-emits events of a protocol dump (see transport.Client.SetDump) to virtual sessions,
+// so event handlers can be debugged against a capture offline. Commands of virtual sessions fail with transport.ErrOffline
+type Replay struct {
+	Browser  BrowserContext
+	entries  []transport.DumpEntry
+	sessions map[string]*Session
+	order    []*Session
+}
+
+// NewReplay reads the dump and creates a virtual session for every session found in it
+func NewReplay(ctx context.Context, r io.Reader) (*Replay, error) {
+	entries, err := transport.ReadDump(r)
+	if err != nil {
+		return nil, err
+	}
+	replay := &Replay{
+		Browser:  New(transport.NewOfflineClient(ctx)),
+		entries:  entries,
+		sessions: map[string]*Session{},
+	}
+	targets := map[string]target.TargetID{} // session -> target
+	for _, e := range entries {
+		switch {
+		case e.Method == "Target.attachedToTarget" && e.IsEvent():
+			var v = target.AttachedToTarget{}
+			if json.Unmarshal(e.Params, &v) == nil && v.TargetInfo != nil {
+				targets[string(v.SessionId)] = v.TargetInfo.TargetId
+			}
+		case e.Direction == transport.DumpSend && e.Method == "Target.attachToTarget":
+			var args = target.AttachToTargetArgs{}
+			if json.Unmarshal(e.Params, &args) != nil {
+				continue
+			}
+			for _, r := range entries { // the response has the same ID
+				var val = target.AttachToTargetVal{}
+				if r.Direction == transport.DumpReceive && r.ID == e.ID && json.Unmarshal(r.Result, &val) == nil {
+					targets[string(val.SessionId)] = args.TargetId
+					break
+				}
+			}
+		}
+	}
+	for _, e := range entries {
+		if !e.IsEvent() || e.SessionID == "" || replay.sessions[e.SessionID] != nil {
+			continue
+		}
+		tid, ok := targets[e.SessionID]
+		if !ok {
+			tid = target.TargetID(e.SessionID)
+		}
+		s := replay.Browser.newSession(tid, target.SessionID(e.SessionID))
+		replay.sessions[e.SessionID] = s
+		replay.order = append(replay.order, s)
+	}
+	return replay, nil
+}
+
+// Sessions virtual sessions in order of their first event
+func (r *Replay) Sessions() []*Session {
+	return r.order
+}
+
+// Session virtual session by ID, nil if the dump has no events of the session
+func (r *Replay) Session(id string) *Session {
+	return r.sessions[id]
+}
+
+// Events number of events in the dump
+func (r *Replay) Events() int {
+	var n int
+	for _, e := range r.entries {
+		if e.IsEvent() {
+			n++
+		}
+	}
+	return n
+}
+
+// Run emits events in order. Speed 1 keeps the original intervals between events, 2 is twice as fast,
+// zero emits events without delays. Subscribers are asynchronous, they may still be handling events when Run returns
+func (r *Replay) Run(ctx context.Context, speed float64) error {
+	var last time.Time
+	for _, e := range r.entries {
+		if !e.IsEvent() {
+			continue
+		}
+		if speed > 0 && !last.IsZero() {
+			if wait := time.Duration(float64(e.Time.Sub(last)) / speed); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		last = e.Time
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var (
+			event = transport.Event{Method: e.Method, Params: e.Params}
+			err   error
+		)
+		if e.SessionID != "" {
+			err = r.Browser.Client.Notify(e.SessionID, event)
+		} else {
+			err = r.Browser.Client.Broadcast(event)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops virtual sessions
+func (r *Replay) Close() error {
+	return r.Browser.Client.Close()
+}
@@ -0,0 +1,198 @@
+package control
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/common"
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/transport"
+)
+
+// RequestEntry request made by the page, redirects are recorded as separate entries with the same ID
+type RequestEntry struct {
+	ID              network.RequestId
+	URL             string
+	Method          string
+	Type            network.ResourceType
+	FrameID         common.FrameId
+	Started         time.Time
+	Duration        time.Duration // until the response is loaded or failed
+	Status          int
+	StatusText      string
+	MimeType        string
+	Protocol        string
+	RemoteAddress   string
+	RequestHeaders  map[string]interface{}
+	ResponseHeaders map[string]interface{}
+	PostData        string
+	EncodedBytes    float64 // transferred over the network including headers
+	FromCache       bool
+	Timing          *network.ResourceTiming
+	Finished        bool
+	Failed          bool
+	ErrorText       string
+
+	timestamp network.MonotonicTime
+}
+
+// RequestLog requests captured by CaptureRequests
+type RequestLog struct {
+	mx      sync.Mutex
+	entries []*RequestEntry
+	current map[network.RequestId]*RequestEntry
+	cancel  func()
+}
+
+// CaptureRequests records all requests of the page until Stop is called
+func (s Session) CaptureRequests() (*RequestLog, error) {
+	l := &RequestLog{current: map[network.RequestId]*RequestEntry{}}
+	unsubscribe := s.Subscribe("*", l.update)
+	release, err := s.EnableDomain("Network")
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+	l.cancel = func() {
+		unsubscribe()
+		release()
+	}
+	return l, nil
+}
+
+// Stop capturing, entries captured so far are kept
+func (l *RequestLog) Stop() {
+	l.cancel()
+}
+
+// Entries captured so far in order of requests
+func (l *RequestLog) Entries() []RequestEntry {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	list := make([]RequestEntry, len(l.entries))
+	for n, e := range l.entries {
+		list[n] = *e
+	}
+	return list
+}
+
+// Reset forgets captured entries
+func (l *RequestLog) Reset() {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.entries = nil
+	l.current = map[network.RequestId]*RequestEntry{}
+}
+
+func (l *RequestLog) update(e transport.Event) error {
+	switch e.Method {
+	case "Network.requestWillBeSent":
+		var v = network.RequestWillBeSent{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if prev := l.current[v.RequestId]; prev != nil && v.RedirectResponse != nil {
+			prev.response(v.RedirectResponse)
+			prev.finish(v.Timestamp)
+		}
+		entry := &RequestEntry{
+			ID:             v.RequestId,
+			URL:            v.Request.Url,
+			Method:         v.Request.Method,
+			Type:           v.Type,
+			FrameID:        v.FrameId,
+			Started:        wallTime(v.WallTime),
+			RequestHeaders: headersMap(v.Request.Headers),
+			PostData:       v.Request.PostData,
+			timestamp:      v.Timestamp,
+		}
+		l.current[v.RequestId] = entry
+		l.entries = append(l.entries, entry)
+
+	case "Network.responseReceived":
+		var v = network.ResponseReceived{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if entry := l.current[v.RequestId]; entry != nil {
+			entry.Type = v.Type
+			entry.response(v.Response)
+		}
+
+	case "Network.requestServedFromCache":
+		var v = network.RequestServedFromCache{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if entry := l.current[v.RequestId]; entry != nil {
+			entry.FromCache = true
+		}
+
+	case "Network.loadingFinished":
+		var v = network.LoadingFinished{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if entry := l.current[v.RequestId]; entry != nil {
+			entry.EncodedBytes = v.EncodedDataLength
+			entry.finish(v.Timestamp)
+			delete(l.current, v.RequestId)
+		}
+
+	case "Network.loadingFailed":
+		var v = network.LoadingFailed{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if entry := l.current[v.RequestId]; entry != nil {
+			entry.Failed = true
+			entry.ErrorText = v.ErrorText
+			entry.finish(v.Timestamp)
+			delete(l.current, v.RequestId)
+		}
+	}
+	return nil
+}
+
+func (e *RequestEntry) response(r *network.Response) {
+	e.Status = r.Status
+	e.StatusText = r.StatusText
+	e.MimeType = r.MimeType
+	e.Protocol = r.Protocol
+	e.RemoteAddress = r.RemoteIPAddress
+	e.ResponseHeaders = headersMap(r.Headers)
+	e.Timing = r.Timing
+	e.EncodedBytes = r.EncodedDataLength
+	e.FromCache = e.FromCache || r.FromDiskCache || r.FromPrefetchCache
+	if h := headersMap(r.RequestHeaders); h != nil {
+		e.RequestHeaders = h // the actual headers sent including cookies
+	}
+}
+
+func (e *RequestEntry) finish(timestamp network.MonotonicTime) {
+	e.Finished = true
+	e.Duration = seconds(float64(timestamp - e.timestamp))
+}
+
+func headersMap(h *network.Headers) map[string]interface{} {
+	if h == nil {
+		return nil
+	}
+	m, _ := (*h).(map[string]interface{})
+	return m
+}
+
+func wallTime(t common.TimeSinceEpoch) time.Time {
+	return time.Unix(0, int64(float64(t)*float64(time.Second)))
+}
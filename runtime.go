@@ -1,6 +1,8 @@
 package control
 
 import (
+	"encoding/json"
+
 	"github.com/ecwid/control/protocol/runtime"
 )
 
@@ -29,6 +31,15 @@ func (p primitiveRemoteObject) Bool() (bool, error) {
 	}
 }
 
+// Decode RemoteObject's value returned by value to v
+func (p primitiveRemoteObject) Decode(v interface{}) error {
+	b, err := json.Marshal(p.Value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
 func (f Frame) getProperties(objectID runtime.RemoteObjectId, ownProperties, accessorPropertiesOnly bool) ([]*runtime.PropertyDescriptor, error) {
 	val, err := runtime.GetProperties(f, runtime.GetPropertiesArgs{
 		ObjectId:               objectID,
@@ -43,3 +54,12 @@ func (f Frame) getProperties(objectID runtime.RemoteObjectId, ownProperties, acc
 	}
 	return val.Result, nil
 }
+
+// evaluateValue evaluates expression and decodes its JSON-serializable result to v
+func (f Frame) evaluateValue(expression string, await bool, v interface{}) error {
+	val, err := f.evaluate(expression, await, true)
+	if err != nil {
+		return err
+	}
+	return primitiveRemoteObject(*val).Decode(v)
+}
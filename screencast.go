@@ -0,0 +1,200 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+var ErrScreencastStarted = errors.New("screencast is already started")
+
+// ScreencastOptions of StartScreencast, the zero value streams jpeg frames of the viewport size
+type ScreencastOptions struct {
+	Format        string // "jpeg" or "png", empty - jpeg
+	Quality       int    // jpeg only, 0..100
+	MaxWidth      int
+	MaxHeight     int
+	EveryNthFrame int
+	Buffer        int // frames kept while the reader is busy, zero - 16. The browser waits for the reader when the buffer is full
+}
+
+// ScreencastFrame decoded frame of the screencast
+type ScreencastFrame struct {
+	Data     []byte
+	Time     time.Time // when the frame was painted
+	Metadata *page.ScreencastFrameMetadata
+}
+
+// Screencast streams frames painted by the page until Stop is called
+type Screencast struct {
+	Frames <-chan ScreencastFrame // closed when the screencast is stopped
+	Format string
+
+	s      *Session
+	mx     sync.Mutex // guards sending into frames against closing it
+	closed bool
+	frames chan ScreencastFrame
+	done   chan struct{}
+	once   sync.Once
+	cancel func()
+}
+
+// StartScreencast streams frames into Screencast.Frames. Every frame is acknowledged when it is put into the channel,
+// so a slow reader slows down the stream instead of missing states in the middle. One screencast per session
+func (s Session) StartScreencast(options ScreencastOptions) (*Screencast, error) {
+	if options.Format == "" {
+		options.Format = "jpeg"
+	}
+	if options.Buffer == 0 {
+		options.Buffer = 16
+	}
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.screencast != nil {
+		return nil, ErrScreencastStarted
+	}
+	sc := &Screencast{
+		Format: options.Format,
+		s:      &s,
+		frames: make(chan ScreencastFrame, options.Buffer),
+		done:   make(chan struct{}),
+	}
+	sc.Frames = sc.frames
+	unsubscribe := s.Subscribe("Page.screencastFrame", sc.update)
+	release, err := s.EnableDomain("Page")
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+	sc.cancel = func() {
+		unsubscribe()
+		release()
+	}
+	err = page.StartScreencast(s, page.StartScreencastArgs{
+		Format:        options.Format,
+		Quality:       options.Quality,
+		MaxWidth:      options.MaxWidth,
+		MaxHeight:     options.MaxHeight,
+		EveryNthFrame: options.EveryNthFrame,
+	})
+	if err != nil {
+		sc.cancel()
+		return nil, err
+	}
+	s.config.screencast = sc
+	go func() {
+		select {
+		case <-s.context.Done():
+			_ = sc.Stop()
+		case <-sc.done:
+		}
+	}()
+	return sc, nil
+}
+
+// StopScreencast stops the screencast started by StartScreencast, if any
+func (s Session) StopScreencast() error {
+	s.config.mx.Lock()
+	sc := s.config.screencast
+	s.config.mx.Unlock()
+	if sc == nil {
+		return nil
+	}
+	return sc.Stop()
+}
+
+// Stop the screencast and close Frames, frames buffered so far can still be read.
+// The screencast is stopped when the session is closed as well
+func (sc *Screencast) Stop() (err error) {
+	sc.once.Do(func() {
+		close(sc.done)
+		if !sc.s.IsClosed() {
+			err = page.StopScreencast(sc.s)
+		}
+		sc.cancel()
+		sc.mx.Lock()
+		sc.closed = true
+		close(sc.frames)
+		sc.mx.Unlock()
+		sc.s.config.mx.Lock()
+		if sc.s.config.screencast == sc {
+			sc.s.config.screencast = nil
+		}
+		sc.s.config.mx.Unlock()
+	})
+	return err
+}
+
+func (sc *Screencast) update(e transport.Event) error {
+	var v = page.ScreencastFrame{}
+	if err := json.Unmarshal(e.Params, &v); err != nil {
+		return err
+	}
+	frame := ScreencastFrame{Data: v.Data, Metadata: v.Metadata, Time: time.Now()}
+	if v.Metadata != nil && v.Metadata.Timestamp > 0 {
+		frame.Time = wallTime(v.Metadata.Timestamp)
+	}
+	sc.mx.Lock()
+	if sc.closed {
+		sc.mx.Unlock()
+		return nil
+	}
+	select {
+	case sc.frames <- frame:
+	case <-sc.done:
+	}
+	sc.mx.Unlock()
+	select {
+	case <-sc.done:
+		return nil
+	default:
+	}
+	return page.ScreencastFrameAck(sc.s, page.ScreencastFrameAckArgs{SessionId: v.SessionId})
+}
+
+// SaveFrames writes frames into dir as an image sequence (frame-00001.jpg, frame-00002.jpg, ...) until the screencast is stopped.
+// Frames come only when the page repaints, so durations are written to frames.ffconcat as well:
+// ffmpeg -f concat -i frames.ffconcat -vsync vfr video.mp4
+func (sc *Screencast) SaveFrames(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	ext := "jpg"
+	if sc.Format == "png" {
+		ext = "png"
+	}
+	var (
+		count  int
+		list   = "ffconcat version 1.0\n"
+		name   string
+		last   time.Time
+		failed error
+	)
+	for frame := range sc.Frames {
+		if failed != nil {
+			continue // drain, so the stream isn't stuck
+		}
+		if name != "" {
+			list += fmt.Sprintf("file %s\nduration %.3f\n", name, frame.Time.Sub(last).Seconds())
+		}
+		count++
+		name, last = fmt.Sprintf("frame-%05d.%s", count, ext), frame.Time
+		failed = ioutil.WriteFile(filepath.Join(dir, name), frame.Data, 0644)
+	}
+	if failed != nil {
+		return count, failed
+	}
+	if name != "" {
+		// the last frame is repeated, otherwise its duration is ignored
+		list += fmt.Sprintf("file %s\nduration 0.040\nfile %s\n", name, name)
+	}
+	return count, ioutil.WriteFile(filepath.Join(dir, "frames.ffconcat"), []byte(list), 0644)
+}
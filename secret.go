@@ -0,0 +1,22 @@
+package control
+
+import (
+	"strings"
+
+	"github.com/ecwid/control/redact"
+)
+
+// TypeSecret inserts value into the element at once (no per-key events, so the value can't be reconstructed from keystrokes).
+// The value is masked as **** in the returned error. If a redaction policy is set (see SetRedactionPolicy),
+// the value is registered with it and masked in logs and dumps until RedactionPolicy().RemoveSecret is called
+func (e Element) TypeSecret(value string) error {
+	e.frame.session.browser.RedactionPolicy().AddSecret(value)
+	if err := e.InsertText(value); err != nil {
+		message := err.Error()
+		if value != "" {
+			message = strings.ReplaceAll(message, value, redact.Mask)
+		}
+		return SecretError{Err: err, message: message}
+	}
+	return nil
+}
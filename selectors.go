@@ -0,0 +1,64 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ordered XPath result as an array, so it can be turned into elements as NodeList is
+const functionXPathAll = `function(xpath){const r=document.evaluate(xpath,document,null,XPathResult.ORDERED_NODE_SNAPSHOT_TYPE,null),list=[];for(let i=0;i<r.snapshotLength;i++)list.push(r.snapshotItem(i));return list}`
+
+// document and open shadow roots in document order, closed shadow roots are not reachable from the page
+const functionDeepQueryAll = `function(selector,first){const found=[],walk=root=>{for(const e of root.querySelectorAll(selector)){found.push(e);if(first)return true}
+for(const e of root.querySelectorAll("*"))if(e.shadowRoot&&walk(e.shadowRoot))return true;return false};walk(document);return found}`
+
+// QueryXPath returns the first node matching XPath expression, e.g. //button[contains(., "Save")]
+func (f Frame) QueryXPath(xpath string) (*Element, error) {
+	list, err := f.queryFunction(functionXPathAll, xpath, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, NoSuchElementError{Selector: xpath}
+	}
+	return list[0], nil
+}
+
+// QueryXPathAll returns all nodes matching XPath expression in document order
+func (f Frame) QueryXPathAll(xpath string) ([]*Element, error) {
+	return f.queryFunction(functionXPathAll, xpath, false)
+}
+
+// QueryDeep is QuerySelector which also looks into open shadow roots at any depth
+func (f Frame) QueryDeep(selector string) (*Element, error) {
+	list, err := f.queryFunction(functionDeepQueryAll, selector, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, NoSuchElementError{Selector: selector}
+	}
+	return list[0], nil
+}
+
+// QueryDeepAll is QuerySelectorAll which also looks into open shadow roots at any depth.
+// A selector is matched within a single tree, it can't cross a shadow boundary (e.g. "my-app button" doesn't
+// match a button of my-app's shadow root), use QueryDeep on the part inside the shadow root
+func (f Frame) QueryDeepAll(selector string) ([]*Element, error) {
+	return f.queryFunction(functionDeepQueryAll, selector, false)
+}
+
+func (f Frame) queryFunction(function, query string, first bool) ([]*Element, error) {
+	b, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	array, err := f.evaluate(fmt.Sprintf("(%s)(%s,%t)", function, b, first), true, false)
+	if err != nil {
+		return nil, err
+	}
+	if array == nil || array.Description == "Array(0)" {
+		return nil, nil
+	}
+	return f.constructElements(array)
+}
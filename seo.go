@@ -0,0 +1,100 @@
+package control
+
+import (
+	"net/url"
+	"strings"
+)
+
+const functionSEO = `(()=>{const m=s=>{const e=document.querySelector(s);return e?(e.getAttribute("content")||e.getAttribute("href")||""):""},og={};
+document.querySelectorAll('meta[property^="og:"],meta[name^="twitter:"]').forEach(e=>og[e.getAttribute("property")||e.getAttribute("name")]=e.getAttribute("content")||"");
+return{url:location.href,title:document.title,description:m('meta[name="description"]'),canonical:m('link[rel="canonical"]'),
+robots:m('meta[name="robots"]'),lang:document.documentElement.lang||"",
+hreflang:Array.from(document.querySelectorAll('link[rel="alternate"][hreflang]')).map(e=>({lang:e.hreflang,url:e.href})),
+h1:Array.from(document.querySelectorAll("h1")).map(e=>e.innerText.trim()),social:og}})()`
+
+// SEO metadata of the document
+type SEO struct {
+	URL            string            `json:"url"`
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	Canonical      string            `json:"canonical"` // href attribute as written, not resolved against the document URL
+	Robots         string            `json:"robots"`
+	Lang           string            `json:"lang"`
+	Hreflang       []Alternate       `json:"hreflang"`
+	H1             []string          `json:"h1"`
+	Social         map[string]string `json:"social"` // Open Graph (og:*) and Twitter card (twitter:*) properties
+	StructuredData *StructuredData   `json:"-"`
+}
+
+// Alternate language version of the page
+type Alternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// SEO extracts title, description, canonical, robots, hreflang, Open Graph and structured data of the main frame
+func (s Session) SEO() (*SEO, error) {
+	seo := &SEO{}
+	if err := s.Page().evaluateValue(functionSEO, false, seo); err != nil {
+		return nil, err
+	}
+	data, err := s.StructuredData()
+	if err != nil {
+		return nil, err
+	}
+	seo.StructuredData = data
+	return seo, nil
+}
+
+// Problems basic validity checks: title and description length, single H1, absolute canonical,
+// indexability, hreflang consistency and structured data
+func (s SEO) Problems() []string {
+	var problems []string
+	switch l := len([]rune(strings.TrimSpace(s.Title))); {
+	case l == 0:
+		problems = append(problems, "title is missing")
+	case l > 60:
+		problems = append(problems, "title is longer than 60 characters")
+	}
+	switch l := len([]rune(strings.TrimSpace(s.Description))); {
+	case l == 0:
+		problems = append(problems, "meta description is missing")
+	case l < 50:
+		problems = append(problems, "meta description is shorter than 50 characters")
+	case l > 160:
+		problems = append(problems, "meta description is longer than 160 characters")
+	}
+	switch len(s.H1) {
+	case 0:
+		problems = append(problems, "h1 is missing")
+	case 1:
+	default:
+		problems = append(problems, "more than one h1")
+	}
+	if s.Canonical == "" {
+		problems = append(problems, "canonical link is missing")
+	} else if u, err := url.Parse(s.Canonical); err != nil || !u.IsAbs() {
+		problems = append(problems, "canonical link is not an absolute URL")
+	}
+	if robots := strings.ToLower(s.Robots); strings.Contains(robots, "noindex") {
+		problems = append(problems, "page is not indexable (robots noindex)")
+	}
+	if len(s.Hreflang) > 0 {
+		self := false
+		for _, a := range s.Hreflang {
+			if a.URL == s.URL || a.URL == s.Canonical {
+				self = true
+			}
+		}
+		if !self {
+			problems = append(problems, "hreflang alternates don't reference the page itself")
+		}
+	}
+	if s.StructuredData != nil {
+		problems = append(problems, s.StructuredData.Validate()...)
+	}
+	if s.Social["og:title"] == "" || s.Social["og:image"] == "" {
+		problems = append(problems, "Open Graph title or image is missing")
+	}
+	return problems
+}
@@ -0,0 +1,151 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ecwid/control/protocol/serviceworker"
+	"github.com/ecwid/control/transport"
+)
+
+var ErrNoServiceWorker = errors.New("no activated service worker controls the page")
+
+// ServiceWorker activated service worker registration of the page
+type ServiceWorker struct {
+	Origin         string
+	RegistrationID serviceworker.RegistrationID
+	ScopeURL       string
+	session        Session
+	release        func()
+}
+
+// ServiceWorker waits for an activated service worker whose scope covers the page's URL.
+// The handle keeps the ServiceWorker domain enabled, call Close when it's not needed anymore
+func (s Session) ServiceWorker(timeout time.Duration) (*ServiceWorker, error) {
+	info, err := s.TargetInfo()
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(info.Url)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		registrations = map[serviceworker.RegistrationID]*serviceworker.ServiceWorkerRegistration{}
+		activated     = map[serviceworker.RegistrationID]bool{}
+	)
+	// the longest scope which is a prefix of the page URL wins, as it does in the browser
+	match := func() *serviceworker.ServiceWorkerRegistration {
+		var found *serviceworker.ServiceWorkerRegistration
+		for id, r := range registrations {
+			if !activated[id] || !strings.HasPrefix(info.Url, r.ScopeURL) {
+				continue
+			}
+			if found == nil || len(r.ScopeURL) > len(found.ScopeURL) {
+				found = r
+			}
+		}
+		return found
+	}
+	future := s.Observe("*", func(e transport.Event, resolve func(interface{}), reject func(error)) {
+		switch e.Method {
+		case "ServiceWorker.workerRegistrationUpdated":
+			var v = serviceworker.WorkerRegistrationUpdated{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			for _, r := range v.Registrations {
+				if r.IsDeleted {
+					delete(registrations, r.RegistrationId)
+				} else {
+					registrations[r.RegistrationId] = r
+				}
+			}
+		case "ServiceWorker.workerVersionUpdated":
+			var v = serviceworker.WorkerVersionUpdated{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			for _, version := range v.Versions {
+				if version.Status == "activated" {
+					activated[version.RegistrationId] = true
+				}
+			}
+		default:
+			return
+		}
+		if r := match(); r != nil {
+			resolve(r)
+		}
+	})
+	defer future.Cancel()
+	release, err := s.EnableDomain("ServiceWorker")
+	if err != nil {
+		return nil, err
+	}
+	val, err := future.Get(timeout)
+	if err != nil {
+		release()
+		var timeoutError FutureTimeoutError
+		if errors.As(err, &timeoutError) {
+			return nil, ErrNoServiceWorker
+		}
+		return nil, err
+	}
+	r := val.(*serviceworker.ServiceWorkerRegistration)
+	return &ServiceWorker{
+		Origin:         u.Scheme + "://" + u.Host,
+		RegistrationID: r.RegistrationId,
+		ScopeURL:       r.ScopeURL,
+		session:        s,
+		release:        release,
+	}, nil
+}
+
+// Close releases the ServiceWorker domain held by the handle
+func (w ServiceWorker) Close() {
+	w.release()
+}
+
+// DeliverPush dispatches push event with data to the service worker as if it came from a push service
+func (w ServiceWorker) DeliverPush(data string) error {
+	return serviceworker.DeliverPushMessage(w.session, serviceworker.DeliverPushMessageArgs{
+		Origin:         w.Origin,
+		RegistrationId: w.RegistrationID,
+		Data:           data,
+	})
+}
+
+// SimulatePush delivers push message to the service worker controlling the page
+func (s Session) SimulatePush(payload string) error {
+	w, err := s.ServiceWorker(time.Second * 10)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return w.DeliverPush(payload)
+}
+
+// DispatchSync fires Background Sync event with tag. lastChance tells the worker it's the last retry
+func (w ServiceWorker) DispatchSync(tag string, lastChance bool) error {
+	return serviceworker.DispatchSyncEvent(w.session, serviceworker.DispatchSyncEventArgs{
+		Origin:         w.Origin,
+		RegistrationId: w.RegistrationID,
+		Tag:            tag,
+		LastChance:     lastChance,
+	})
+}
+
+// DispatchPeriodicSync fires Periodic Background Sync event with tag
+func (w ServiceWorker) DispatchPeriodicSync(tag string) error {
+	return serviceworker.DispatchPeriodicSyncEvent(w.session, serviceworker.DispatchPeriodicSyncEventArgs{
+		Origin:         w.Origin,
+		RegistrationId: w.RegistrationID,
+		Tag:            tag,
+	})
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"runtime/debug"
 	"sync"
+	"time"
 
+	"github.com/ecwid/control/protocol"
 	"github.com/ecwid/control/protocol/common"
+	"github.com/ecwid/control/protocol/page"
 	"github.com/ecwid/control/protocol/runtime"
 	"github.com/ecwid/control/protocol/target"
 	"github.com/ecwid/control/transport"
@@ -15,37 +19,79 @@ import (
 const (
 	Blank     = "about:blank"
 	bindClick = "_on_click"
+
+	subscriberQueueSize = 1000
+
+	// how long evaluation waits for a frame's execution context to be recreated after navigation
+	executionContextWait = time.Second * 3
 )
 
 type Session struct {
-	browser    BrowserContext
-	id         target.SessionID
-	tid        target.TargetID
-	executions *sync.Map
-	eventPool  chan transport.Event
-	publisher  *transport.Publisher
-	exitCode   error
-	context    context.Context
-	cancelCtx  func()
-	detach     func()
+	browser     BrowserContext
+	id          *sessionID
+	tid         target.TargetID
+	executions  *sync.Map // frameID -> unique id of the frame's default execution context
+	detached    *sync.Map // frameID -> reason of Page.frameDetached
+	eventPool   chan transport.Event
+	publisher   *transport.Publisher
+	exitCode    error
+	context     context.Context
+	cancelCtx   func()
+	detach      func()
+	domains     *domains
+	config      *sessionConfig
+	activity    *activity
+	interceptor *interceptor
+	bindings    *bindings
 
 	Network   Network
 	Input     Input
 	Emulation Emulation
 }
 
+// Call sends any protocol method, send and recv are typed args and result of the method (see protocol packages)
+// or any other JSON-serializable values for methods which are not generated yet
 func (s Session) Call(method string, send, recv interface{}) error {
+	return s.CallContext(context.Background(), method, send, recv)
+}
+
+// CallContext is Call which gives up when ctx is done
+func (s Session) CallContext(ctx context.Context, method string, send, recv interface{}) error {
 	select {
 	case <-s.context.Done():
-		if s.exitCode != nil {
-			return s.exitCode
-		}
-		return s.context.Err()
+		return s.closedErr()
 	default:
-		return s.browser.Client.Call(string(s.id), method, send, recv)
+	}
+	defer s.activity.begin(method)()
+	for {
+		id, gen, err := s.id.current(ctx, s.browser.Client)
+		if err != nil {
+			return err
+		}
+		// the client may reconnect meanwhile, then the command isn't sent and waits for the session to be attached again
+		err = s.browser.callContext(transport.WithGeneration(ctx, gen), string(id), method, send, recv)
+		if err != transport.ErrStaleGeneration {
+			return err
+		}
 	}
 }
 
+// WithContext returns protocol.Caller of the session bound to ctx, so generated protocol methods can be cancelled:
+//
+//	page.Reload(session.WithContext(ctx), page.ReloadArgs{})
+func (s Session) WithContext(ctx context.Context) protocol.Caller {
+	return contextCaller{ctx: ctx, session: s}
+}
+
+type contextCaller struct {
+	ctx     context.Context
+	session Session
+}
+
+func (c contextCaller) Call(method string, send, recv interface{}) error {
+	return c.session.CallContext(c.ctx, method, send, recv)
+}
+
 func (s Session) GetBrowserContext() BrowserContext {
 	return s.browser
 }
@@ -54,8 +100,17 @@ func (s Session) GetTargetID() target.TargetID {
 	return s.tid
 }
 
+// TargetInfo current state of the session's target (URL, title, browser context)
+func (s Session) TargetInfo() (*target.TargetInfo, error) {
+	val, err := target.GetTargetInfo(s.browser, target.GetTargetInfoArgs{TargetId: s.tid})
+	if err != nil {
+		return nil, err
+	}
+	return val.TargetInfo, nil
+}
+
 func (s Session) ID() string {
-	return string(s.id)
+	return string(s.id.get())
 }
 
 func (s Session) Name() string {
@@ -93,10 +148,46 @@ func (s *Session) handle(e transport.Event) error {
 	case "Runtime.executionContextCreated":
 		var v = runtime.ExecutionContextCreated{}
 		if err := json.Unmarshal(e.Params, &v); err != nil {
-			return err
+			s.browser.internalError(err)
+			break
+		}
+		if aux, ok := v.Context.AuxData.(map[string]interface{}); ok {
+			if isDefault, ok := aux["isDefault"].(bool); ok && !isDefault {
+				break // isolated worlds
+			}
+			if frameID, ok := aux["frameId"].(string); ok {
+				s.executions.Store(common.FrameId(frameID), v.Context.UniqueId)
+				s.detached.Delete(common.FrameId(frameID))
+			}
 		}
-		frameID := common.FrameId((v.Context.AuxData.(map[string]interface{}))["frameId"].(string))
-		s.executions.Store(frameID, v.Context.UniqueId)
+
+	case "Runtime.executionContextDestroyed":
+		var v = runtime.ExecutionContextDestroyed{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			s.browser.internalError(err)
+			break
+		}
+		s.executions.Range(func(key, value interface{}) bool {
+			if value == v.ExecutionContextUniqueId {
+				s.executions.Delete(key)
+			}
+			return true
+		})
+
+	case "Runtime.executionContextsCleared":
+		s.executions.Range(func(key, _ interface{}) bool {
+			s.executions.Delete(key)
+			return true
+		})
+
+	case "Page.frameDetached":
+		var v = page.FrameDetached{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			s.browser.internalError(err)
+			break
+		}
+		s.executions.Delete(v.FrameId)
+		s.detached.Store(v.FrameId, v.Reason)
 
 	case "Target.targetCrashed":
 		var v = target.TargetCrashed{}
@@ -119,7 +210,7 @@ func (s *Session) handle(e transport.Event) error {
 		if err := json.Unmarshal(e.Params, &v); err != nil {
 			return err
 		}
-		if v.SessionId == s.id {
+		if v.SessionId == s.id.get() {
 			return ErrDetachedFromTarget
 		}
 
@@ -127,14 +218,31 @@ func (s *Session) handle(e transport.Event) error {
 	return s.publisher.Notify(e.Method, e)
 }
 
+// safeHandle doesn't let a panic take the process down from the goroutine users don't own
+func (s *Session) safeHandle(e transport.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.browser.internalError(InternalPanicError{Value: r, Stack: debug.Stack()})
+			err = nil
+		}
+	}()
+	return s.handle(e)
+}
+
 func (s *Session) handleEventPool() {
 	defer func() {
 		s.detach() // detach from the transport updates
 		s.cancelCtx()
+		s.browser.forgetSession(s.id.get())
 	}()
-	for e := range s.eventPool {
-		if err := s.handle(e); err != nil {
-			s.exitCode = err
+	for {
+		select {
+		case e := <-s.eventPool:
+			if err := s.safeHandle(e); err != nil {
+				s.exitCode = err
+				return
+			}
+		case <-s.context.Done():
 			return
 		}
 	}
@@ -154,14 +262,49 @@ func (s Session) onBindingCalled(name string, function func(string)) (cancel fun
 	})
 }
 
+// Subscribe calls v for every event with the given name ("*" - any event).
+// Each subscription has its own goroutine, errors and panics of v are reported to the error hook.
+// A subscription which falls behind by more than 1000 events is closed with transport.ObserverOverflowError reported
 func (s Session) Subscribe(event string, v func(e transport.Event) error) (cancel func()) {
-	return s.publisher.Register(transport.NewSimpleObserver(event, v))
+	return s.subscribe(event, v, nil)
+}
+
+// subscribe is Subscribe which also calls overflow if the subscription is closed because v doesn't keep up
+func (s Session) subscribe(event string, v func(e transport.Event) error, overflow func(error)) (cancel func()) {
+	onError := s.subscriberError
+	if overflow != nil {
+		onError = func(err error) {
+			s.subscriberError(err)
+			var e transport.ObserverOverflowError
+			if errors.As(err, &e) {
+				overflow(err)
+			}
+		}
+	}
+	observer := transport.NewAsyncObserver(s.context, event, subscriberQueueSize, v, onError)
+	unregister := s.publisher.Register(observer)
+	return func() {
+		unregister()
+		observer.Close()
+	}
+}
+
+func (s Session) subscriberError(err error) {
+	s.browser.internalError(SessionError{SessionID: s.ID(), Err: err})
 }
 
 func (s Session) Close() error {
 	return s.browser.CloseTarget(s.tid)
 }
 
+// Detach releases the session and all its subscriptions but leaves the tab alive
+func (s Session) Detach() error {
+	err := target.DetachFromTarget(s.browser, target.DetachFromTargetArgs{SessionId: s.id.get()})
+	s.publisher.Clear()
+	s.cancelCtx()
+	return err
+}
+
 func (s Session) IsClosed() bool {
 	select {
 	case <-s.context.Done():
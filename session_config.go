@@ -0,0 +1,21 @@
+package control
+
+import (
+	"sync"
+	"time"
+)
+
+// sessionConfig is shared between all copies of Session
+type sessionConfig struct {
+	mx                sync.Mutex
+	navigationBudget  time.Duration
+	onSlowNavigation  func(SlowNavigation)
+	actionInterval    time.Duration
+	actionJitter      time.Duration
+	nextAction        time.Time
+	dialogCancel      func()
+	traffic           *trafficMeter
+	downloads         *downloads
+	screencast        *Screencast
+	fileChooserCancel func()
+}
@@ -0,0 +1,83 @@
+package control
+
+import (
+	"context"
+
+	"github.com/ecwid/control/protocol/browser"
+)
+
+// shim maps a deprecated method to its replacement available since the major version of the browser,
+// so the same code works on both older and newer releases
+type shim struct {
+	deprecated  string
+	replacement string
+	since       int
+	downgrade   func(args interface{}) interface{} // converts replacement's args to deprecated ones, optional
+}
+
+var shims = []shim{
+	{deprecated: "Page.setDownloadBehavior", replacement: "Browser.setDownloadBehavior", since: 78, downgrade: downgradeDownloadBehavior},
+	{deprecated: "Page.setGeolocationOverride", replacement: "Emulation.setGeolocationOverride", since: 60},
+	{deprecated: "Page.clearGeolocationOverride", replacement: "Emulation.clearGeolocationOverride", since: 60},
+	{deprecated: "Page.setDeviceMetricsOverride", replacement: "Emulation.setDeviceMetricsOverride", since: 60},
+	{deprecated: "Page.clearDeviceMetricsOverride", replacement: "Emulation.clearDeviceMetricsOverride", since: 60},
+	{deprecated: "Page.setTouchEmulationEnabled", replacement: "Emulation.setTouchEmulationEnabled", since: 60},
+}
+
+func findShim(method string) *shim {
+	for n := range shims {
+		if shims[n].deprecated == method || shims[n].replacement == method {
+			return &shims[n]
+		}
+	}
+	return nil
+}
+
+// call sends the command to the browser replacing deprecated methods
+func (b BrowserContext) call(sessionID, method string, send, recv interface{}) error {
+	return b.callContext(context.Background(), sessionID, method, send, recv)
+}
+
+func (b BrowserContext) callContext(ctx context.Context, sessionID, method string, send, recv interface{}) error {
+	method, send = b.shim(method, send)
+	return b.Client.CallContext(ctx, sessionID, method, send, recv)
+}
+
+func (b BrowserContext) shim(method string, args interface{}) (string, interface{}) {
+	s := findShim(method)
+	if s == nil {
+		return method, args
+	}
+	caps, err := b.Capabilities()
+	if err != nil || caps.Major == 0 {
+		return method, args
+	}
+	if caps.Major >= s.since {
+		return s.replacement, args
+	}
+	if method == s.replacement && s.downgrade != nil {
+		args = s.downgrade(args)
+	}
+	return s.deprecated, args
+}
+
+type pageSetDownloadBehaviorArgs struct {
+	Behavior     string `json:"behavior"`
+	DownloadPath string `json:"downloadPath,omitempty"`
+}
+
+func downgradeDownloadBehavior(args interface{}) interface{} {
+	var v browser.SetDownloadBehaviorArgs
+	switch a := args.(type) {
+	case browser.SetDownloadBehaviorArgs:
+		v = a
+	case *browser.SetDownloadBehaviorArgs:
+		v = *a
+	default:
+		return args
+	}
+	if v.Behavior == "allowAndName" {
+		v.Behavior = "allow"
+	}
+	return pageSetDownloadBehaviorArgs{Behavior: v.Behavior, DownloadPath: v.DownloadPath}
+}
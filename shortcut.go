@@ -0,0 +1,70 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Shortcut string
+
+const (
+	ShortcutSelectAll Shortcut = "selectAll"
+	ShortcutCopy      Shortcut = "copy"
+	ShortcutCut       Shortcut = "cut"
+	ShortcutPaste     Shortcut = "paste"
+	ShortcutUndo      Shortcut = "undo"
+	ShortcutRedo      Shortcut = "redo"
+	ShortcutFind      Shortcut = "find"
+)
+
+type chord struct {
+	key     rune
+	shift   bool
+	command string
+}
+
+var (
+	shortcuts = map[Shortcut]chord{
+		ShortcutSelectAll: {key: 'a', command: "selectAll"},
+		ShortcutCopy:      {key: 'c', command: "copy"},
+		ShortcutCut:       {key: 'x', command: "cut"},
+		ShortcutPaste:     {key: 'v', command: "paste"},
+		ShortcutUndo:      {key: 'z', command: "undo"},
+		ShortcutRedo:      {key: 'y', command: "redo"},
+		ShortcutFind:      {key: 'f'},
+	}
+	macShortcuts = map[Shortcut]chord{
+		ShortcutRedo: {key: 'z', shift: true, command: "redo"},
+	}
+)
+
+// Shortcut presses OS-specific key chord, e.g. Ctrl+A on Windows/Linux and Cmd+A on Mac for ShortcutSelectAll.
+// The platform is detected from the browser's user agent
+func (s Session) Shortcut(name Shortcut) error {
+	caps, err := s.browser.Capabilities()
+	if err != nil {
+		return err
+	}
+	var (
+		mac       = strings.Contains(caps.UserAgent, "Macintosh")
+		modifiers = ModifierCtrl
+	)
+	c, ok := shortcuts[name]
+	if !ok {
+		return fmt.Errorf("unknown shortcut `%s`", name)
+	}
+	if mac {
+		modifiers = ModifierMeta
+		if m, ok := macShortcuts[name]; ok {
+			c = m
+		}
+	}
+	if c.shift {
+		modifiers |= ModifierShift
+	}
+	var commands []string
+	if c.command != "" {
+		commands = append(commands, c.command)
+	}
+	return s.Input.PressWithModifiers(keyDefinitions[c.key], modifiers, commands...)
+}
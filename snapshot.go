@@ -0,0 +1,179 @@
+package control
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// element per line indented by depth: tag#id.class [name type role aria-label] and own text (80 chars max),
+// invisible elements and scripts are skipped, values of password fields are never taken
+const functionSnapshot = `(()=>{const skip=/^(script|style|noscript|template|link|meta|head)$/,lines=[],
+text=n=>(n.nodeValue||"").replace(/\s+/g," ").trim(),short=t=>t.length>80?t.slice(0,79)+"…":t,
+hidden=e=>{const s=getComputedStyle(e);return s.display==="none"||s.visibility==="hidden"},
+walk=(e,d)=>{if(skip.test(e.localName)||hidden(e))return;let l="  ".repeat(d)+e.localName;if(e.id)l+="#"+e.id;
+if(typeof e.className==="string"&&e.className.trim())l+="."+e.className.trim().split(/\s+/).slice(0,3).join(".");
+for(const a of ["name","type","role","aria-label","href"]){const v=e.getAttribute(a);if(v)l+=" ["+a+"="+short(v)+"]"}
+if((e.localName==="input"&&e.type!=="password")||e.localName==="textarea"||e.localName==="select")l+=" value="+JSON.stringify(short(String(e.value)));
+let t="";for(const c of e.childNodes)if(c.nodeType===3)t+=" "+text(c);t=t.trim();if(t)l+=" "+JSON.stringify(short(t));lines.push(l);
+for(const c of e.children)walk(c,d+1)};
+if(document.body)walk(document.body,0);
+return{url:location.href,title:document.title,dom:lines,text:document.body?document.body.innerText:""}})()`
+
+// Snapshot lightweight state of the main frame's document
+type Snapshot struct {
+	Time  time.Time
+	URL   string   `json:"url"`
+	Title string   `json:"title"`
+	DOM   []string `json:"dom"`  // outline of visible elements, one per line indented by depth
+	Text  string   `json:"text"` // rendered text of the body
+}
+
+// Diff changes of the DOM outline from s to next
+func (s *Snapshot) Diff(next *Snapshot) Diff {
+	return diffLines(s.DOM, next.DOM)
+}
+
+// TextDiff changes of the rendered text from s to next, blank lines and surrounding spaces are ignored
+func (s *Snapshot) TextDiff(next *Snapshot) Diff {
+	return diffLines(textLines(s.Text), textLines(next.Text))
+}
+
+// String URL, title and the DOM outline
+func (s *Snapshot) String() string {
+	return s.Time.Format(time.RFC3339Nano) + " " + s.URL + " " + s.Title + "\n" + strings.Join(s.DOM, "\n")
+}
+
+// Snapshot captures the outline and the rendered text of the main frame's document
+func (s Session) Snapshot() (*Snapshot, error) {
+	var snapshot *Snapshot
+	if err := s.Page().evaluateValue(functionSnapshot, false, &snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.Time = time.Now()
+	if policy := s.browser.RedactionPolicy(); policy != nil {
+		// snapshots are dumped on failures, secrets typed into the page must not get there
+		snapshot.URL = policy.URL(snapshot.URL)
+		snapshot.Title = policy.String(snapshot.Title)
+		snapshot.Text = policy.String(snapshot.Text)
+		for n, line := range snapshot.DOM {
+			snapshot.DOM[n] = policy.String(line)
+		}
+	}
+	return snapshot, nil
+}
+
+// TextDiff changes of the rendered text from before to after, the current state of the page is taken if after is nil.
+// E.g. len(diff.Inserted()) == 1 asserts that exactly one row was added
+func (s Session) TextDiff(before, after *Snapshot) (Diff, error) {
+	if after == nil {
+		var err error
+		if after, err = s.Snapshot(); err != nil {
+			return nil, err
+		}
+	}
+	return before.TextDiff(after), nil
+}
+
+// SnapshotRecorder snapshots taken by SnapshotEvery
+type SnapshotRecorder struct {
+	mx        sync.Mutex
+	snapshots []*Snapshot
+	keep      int
+	done      chan struct{}
+	once      sync.Once
+}
+
+// SnapshotEvery takes Snapshot with the interval until Stop is called or the session is closed and keeps the last keep of them
+// (zero - all). A snapshot equal to the previous one is not kept, failed snapshots (e.g. in the middle of navigation) are skipped.
+// ErrInterval is returned if the interval isn't positive
+func (s Session) SnapshotEvery(interval time.Duration, keep int) (*SnapshotRecorder, error) {
+	if interval <= 0 {
+		return nil, ErrInterval
+	}
+	r := &SnapshotRecorder{keep: keep, done: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if snapshot, err := s.Snapshot(); err == nil {
+				r.add(snapshot)
+			}
+			select {
+			case <-ticker.C:
+			case <-r.done:
+				return
+			case <-s.context.Done():
+				return
+			}
+		}
+	}()
+	return r, nil
+}
+
+func (r *SnapshotRecorder) add(snapshot *Snapshot) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if n := len(r.snapshots); n > 0 {
+		last := r.snapshots[n-1]
+		if last.URL == snapshot.URL && last.Title == snapshot.Title && last.Text == snapshot.Text && equalLines(last.DOM, snapshot.DOM) {
+			return
+		}
+	}
+	r.snapshots = append(r.snapshots, snapshot)
+	if r.keep > 0 && len(r.snapshots) > r.keep {
+		r.snapshots = append(r.snapshots[:0:0], r.snapshots[len(r.snapshots)-r.keep:]...)
+	}
+}
+
+// Stop taking snapshots, snapshots taken so far are kept
+func (r *SnapshotRecorder) Stop() {
+	r.once.Do(func() { close(r.done) })
+}
+
+// Snapshots taken so far, the oldest first
+func (r *SnapshotRecorder) Snapshots() []*Snapshot {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return append([]*Snapshot(nil), r.snapshots...)
+}
+
+// String every snapshot as the diff from the previous one, the first one is written in full
+func (r *SnapshotRecorder) String() string {
+	var (
+		b    strings.Builder
+		list = r.Snapshots()
+	)
+	for n, snapshot := range list {
+		if n == 0 {
+			b.WriteString(snapshot.String())
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString("\n" + snapshot.Time.Format(time.RFC3339Nano) + " " + snapshot.URL + " " + snapshot.Title + "\n")
+		b.WriteString(list[n-1].Diff(snapshot).String())
+	}
+	return b.String()
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func textLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
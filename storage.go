@@ -0,0 +1,87 @@
+package control
+
+import (
+	"github.com/ecwid/control/protocol/domstorage"
+)
+
+// Storage localStorage or sessionStorage of an origin
+type Storage struct {
+	Origin string
+	local  bool
+	s      *Session
+}
+
+// LocalStorage of the current origin of the main frame
+func (s Session) LocalStorage() (*Storage, error) {
+	return s.storage(true)
+}
+
+// SessionStorage of the current origin of the main frame
+func (s Session) SessionStorage() (*Storage, error) {
+	return s.storage(false)
+}
+
+func (s Session) storage(local bool) (*Storage, error) {
+	var origin string
+	if err := s.Page().evaluateValue(`location.origin`, false, &origin); err != nil {
+		return nil, err
+	}
+	if err := s.holdDomain("DOMStorage"); err != nil {
+		return nil, err
+	}
+	return &Storage{Origin: origin, local: local, s: &s}, nil
+}
+
+func (t Storage) id() *domstorage.StorageId {
+	return &domstorage.StorageId{SecurityOrigin: t.Origin, IsLocalStorage: t.local}
+}
+
+// Items all key-value pairs of the storage
+func (t Storage) Items() (map[string]string, error) {
+	val, err := domstorage.GetDOMStorageItems(t.s, domstorage.GetDOMStorageItemsArgs{StorageId: t.id()})
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string]string, len(val.Entries))
+	for _, e := range val.Entries {
+		if len(e) == 2 {
+			items[e[0]] = e[1]
+		}
+	}
+	return items, nil
+}
+
+// Get value of the key, false if there is no such key
+func (t Storage) Get(key string) (string, bool, error) {
+	items, err := t.Items()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := items[key]
+	return value, ok, nil
+}
+
+// Set value of the key
+func (t Storage) Set(key, value string) error {
+	return domstorage.SetDOMStorageItem(t.s, domstorage.SetDOMStorageItemArgs{StorageId: t.id(), Key: key, Value: value})
+}
+
+// SetItems sets all the pairs, e.g. to restore items saved by Items
+func (t Storage) SetItems(items map[string]string) error {
+	for key, value := range items {
+		if err := t.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove the key
+func (t Storage) Remove(key string) error {
+	return domstorage.RemoveDOMStorageItem(t.s, domstorage.RemoveDOMStorageItemArgs{StorageId: t.id(), Key: key})
+}
+
+// Clear removes all keys
+func (t Storage) Clear() error {
+	return domstorage.Clear(t.s, domstorage.ClearArgs{StorageId: t.id()})
+}
@@ -0,0 +1,164 @@
+package control
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ecwid/control/protocol/common"
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/protocol/storage"
+)
+
+// StorageState cookies and web storage of a session, it's serializable to JSON,
+// so a login can be saved once and restored by RestoreStorageState in other sessions and runs
+type StorageState struct {
+	Cookies []*network.Cookie `json:"cookies"`
+	Origins []*OriginStorage  `json:"origins"`
+}
+
+// OriginStorage localStorage and sessionStorage items of an origin
+type OriginStorage struct {
+	Origin         string            `json:"origin"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
+}
+
+// Origin storage of the origin, nil if there is none
+func (s StorageState) Origin(origin string) *OriginStorage {
+	for _, o := range s.Origins {
+		if o.Origin == origin {
+			return o
+		}
+	}
+	return nil
+}
+
+// StorageState collects cookies of the session's browser context and web storage of origins of the page's frames
+func (s Session) StorageState() (*StorageState, error) {
+	info, err := s.TargetInfo()
+	if err != nil {
+		return nil, err
+	}
+	val, err := storage.GetCookies(s.browser, storage.GetCookiesArgs{BrowserContextId: info.BrowserContextId})
+	if err != nil {
+		return nil, err
+	}
+	state := &StorageState{Cookies: val.Cookies}
+	tree, err := s.FrameTree()
+	if err != nil {
+		return nil, err
+	}
+	var walk func(*FrameTree) error
+	walk = func(t *FrameTree) error {
+		if origin := webOrigin(t.URL); origin != "" && state.Origin(origin) == nil {
+			o := &OriginStorage{Origin: origin}
+			if o.LocalStorage, err = s.storageItems(origin, true); err != nil {
+				return err
+			}
+			if o.SessionStorage, err = s.storageItems(origin, false); err != nil {
+				return err
+			}
+			if len(o.LocalStorage) > 0 || len(o.SessionStorage) > 0 {
+				state.Origins = append(state.Origins, o)
+			}
+		}
+		for _, child := range t.Children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = walk(tree); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// RestoreStorageState sets cookies and web storage of the state. Storage is written with the session navigated to each origin,
+// the navigations are fulfilled with an empty page and never reach the sites, the session is left at Blank.
+// sessionStorage belongs to the tab, so it's restored in this session only
+func (s Session) RestoreStorageState(state *StorageState) error {
+	if len(state.Cookies) > 0 {
+		info, err := s.TargetInfo()
+		if err != nil {
+			return err
+		}
+		err = storage.SetCookies(s.browser, storage.SetCookiesArgs{Cookies: CookieParams(state.Cookies), BrowserContextId: info.BrowserContextId})
+		if err != nil {
+			return err
+		}
+	}
+	if len(state.Origins) == 0 {
+		return nil
+	}
+	for _, o := range state.Origins {
+		if err := s.restoreOrigin(o); err != nil {
+			return fmt.Errorf("storage of %s: %w", o.Origin, err)
+		}
+	}
+	return s.Page().Navigate(Blank, LifecycleDOMContentLoaded, time.Second*10)
+}
+
+func (s Session) restoreOrigin(o *OriginStorage) error {
+	cancel, err := s.Intercept(o.Origin+"/", StageRequest, func(i *Interception) {
+		i.Fulfill(http.StatusOK, http.Header{"Content-Type": {"text/html"}}, []byte("<html></html>"))
+	})
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	if err = s.Page().Navigate(o.Origin+"/", LifecycleDOMContentLoaded, time.Second*10); err != nil {
+		return err
+	}
+	if err = s.holdDomain("DOMStorage"); err != nil {
+		return err
+	}
+	if err = (Storage{Origin: o.Origin, local: true, s: &s}).SetItems(o.LocalStorage); err != nil {
+		return err
+	}
+	return Storage{Origin: o.Origin, local: false, s: &s}.SetItems(o.SessionStorage)
+}
+
+func (s Session) storageItems(origin string, local bool) (map[string]string, error) {
+	if err := s.holdDomain("DOMStorage"); err != nil {
+		return nil, err
+	}
+	return Storage{Origin: origin, local: local, s: &s}.Items()
+}
+
+// CookieParams converts cookies as returned by the browser to the parameters to set them again
+func CookieParams(cookies []*network.Cookie) []*network.CookieParam {
+	params := make([]*network.CookieParam, len(cookies))
+	for n, c := range cookies {
+		params[n] = &network.CookieParam{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			Secure:       c.Secure,
+			HttpOnly:     c.HttpOnly,
+			SameSite:     c.SameSite,
+			Priority:     c.Priority,
+			SameParty:    c.SameParty,
+			SourceScheme: c.SourceScheme,
+			SourcePort:   c.SourcePort,
+			PartitionKey: c.PartitionKey,
+		}
+		if !c.Session {
+			params[n].Expires = common.TimeSinceEpoch(c.Expires)
+		}
+	}
+	return params
+}
+
+// webOrigin origin of http(s) URL, empty for about:blank, data: and the like
+func webOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,78 @@
+package control
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/ecwid/control/protocol"
+	cdpio "github.com/ecwid/control/protocol/io"
+)
+
+// StreamChunkSize bytes requested by a single IO.read unless the reader asks for more
+const StreamChunkSize = 1 << 20
+
+// Stream reads data of a stream handle returned by the browser (PDF, trace, response body, heap snapshot)
+// piece by piece with IO.read, so the whole payload is never held in memory. Close releases the handle
+type Stream struct {
+	caller protocol.Caller
+	handle cdpio.StreamHandle
+	mx     sync.Mutex
+	buf    []byte
+	eof    bool
+	closed bool
+}
+
+// NewStream reader of the handle, caller is the session (or browser) which the handle belongs to
+func NewStream(caller protocol.Caller, handle cdpio.StreamHandle) *Stream {
+	return &Stream{caller: caller, handle: handle}
+}
+
+// Handle of the stream
+func (s *Stream) Handle() cdpio.StreamHandle {
+	return s.handle
+}
+
+func (s *Stream) Read(p []byte) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.closed {
+		return 0, errors.New("read of closed stream")
+	}
+	for len(s.buf) == 0 {
+		if s.eof {
+			return 0, io.EOF
+		}
+		size := len(p)
+		if size < StreamChunkSize {
+			size = StreamChunkSize
+		}
+		val, err := cdpio.Read(s.caller, cdpio.ReadArgs{Handle: s.handle, Size: size})
+		if err != nil {
+			return 0, err
+		}
+		s.eof = val.Eof
+		if val.Base64Encoded {
+			if s.buf, err = base64.StdEncoding.DecodeString(val.Data); err != nil {
+				return 0, err
+			}
+		} else {
+			s.buf = []byte(val.Data)
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// Close releases the handle in the browser, it's safe to call it several times
+func (s *Stream) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed, s.buf = true, nil
+	return cdpio.Close(s.caller, cdpio.CloseArgs{Handle: s.handle})
+}
@@ -0,0 +1,212 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const functionStructuredData = `(()=>{const val=e=>{if(e.hasAttribute("itemscope"))return item(e);if(e.hasAttribute("content"))return e.getAttribute("content");
+switch(e.localName){case"a":case"link":case"area":return e.href;case"img":case"audio":case"video":case"source":case"iframe":case"embed":return e.src;case"meta":return e.content||"";
+case"time":return e.getAttribute("datetime")||e.textContent.trim();case"data":case"meter":return e.getAttribute("value")||"";}return e.textContent.trim()},
+item=s=>{const o={},t=(s.getAttribute("itemtype")||"").trim();if(t)o["@type"]=t.split(/\s+/).map(x=>x.replace(/^https?:\/\/schema\.org\//,""));
+const walk=n=>{for(const c of n.children){if(c.hasAttribute("itemprop"))for(const p of c.getAttribute("itemprop").trim().split(/\s+/)){const v=val(c);o[p]=p in o?[].concat(o[p],v):v}
+if(!c.hasAttribute("itemscope"))walk(c)}};walk(s);if(o["@type"]&&o["@type"].length===1)o["@type"]=o["@type"][0];return o};
+return{jsonld:Array.from(document.querySelectorAll('script[type="application/ld+json"]')).map(e=>e.textContent),
+microdata:Array.from(document.querySelectorAll("[itemscope]:not([itemprop])")).map(item)}})()`
+
+// required properties of common schema.org types for rich results
+var structuredDataRequired = map[string][]string{
+	"Product":        {"name"},
+	"Offer":          {"price", "priceCurrency"},
+	"Recipe":         {"name", "image"},
+	"JobPosting":     {"title", "description", "datePosted", "hiringOrganization"},
+	"Article":        {"headline"},
+	"NewsArticle":    {"headline"},
+	"BlogPosting":    {"headline"},
+	"Event":          {"name", "startDate", "location"},
+	"Organization":   {"name"},
+	"BreadcrumbList": {"itemListElement"},
+	"FAQPage":        {"mainEntity"},
+	"Review":         {"itemReviewed", "reviewRating", "author"},
+}
+
+// StructuredItem schema.org entity from JSON-LD or microdata
+type StructuredItem map[string]interface{}
+
+// Types @type of the item
+func (i StructuredItem) Types() []string {
+	switch t := i["@type"].(type) {
+	case string:
+		return []string{strings.TrimPrefix(strings.TrimPrefix(t, "http://schema.org/"), "https://schema.org/")}
+	case []interface{}:
+		var list []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// Is true if the item has the type
+func (i StructuredItem) Is(typ string) bool {
+	for _, t := range i.Types() {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// String property value as string, the first one if there are several
+func (i StructuredItem) String(property string) string {
+	switch v := i[property].(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// Item nested entity of the property, e.g. product.Item("offers")
+func (i StructuredItem) Item(property string) StructuredItem {
+	switch v := i[property].(type) {
+	case map[string]interface{}:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			if m, ok := v[0].(map[string]interface{}); ok {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// Validate reports missing required properties of the item and its nested entities
+func (i StructuredItem) Validate() []string {
+	var problems []string
+	for _, t := range i.Types() {
+		for _, p := range structuredDataRequired[t] {
+			if v, ok := i[p]; !ok || v == nil || v == "" {
+				problems = append(problems, fmt.Sprintf("%s: required property `%s` is missing", t, p))
+			}
+		}
+	}
+	if i.Is("Product") && i["offers"] == nil && i["review"] == nil && i["aggregateRating"] == nil {
+		problems = append(problems, "Product: one of `offers`, `review` or `aggregateRating` is required")
+	}
+	for _, v := range i {
+		for _, nested := range nestedItems(v) {
+			problems = append(problems, nested.Validate()...)
+		}
+	}
+	return problems
+}
+
+func nestedItems(v interface{}) []StructuredItem {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if _, ok := t["@type"]; ok {
+			return []StructuredItem{t}
+		}
+	case []interface{}:
+		var list []StructuredItem
+		for _, item := range t {
+			list = append(list, nestedItems(item)...)
+		}
+		return list
+	}
+	return nil
+}
+
+// StructuredData JSON-LD and microdata entities of the document
+type StructuredData struct {
+	JSONLD    []StructuredItem
+	Microdata []StructuredItem
+	Errors    []string // unparseable JSON-LD blocks
+}
+
+// Find entities of the type from both sources
+func (d StructuredData) Find(typ string) []StructuredItem {
+	var list []StructuredItem
+	for _, i := range append(append([]StructuredItem{}, d.JSONLD...), d.Microdata...) {
+		if i.Is(typ) {
+			list = append(list, i)
+		}
+	}
+	return list
+}
+
+// Validate parsing errors and missing required properties of all entities
+func (d StructuredData) Validate() []string {
+	problems := append([]string{}, d.Errors...)
+	for _, i := range d.JSONLD {
+		problems = append(problems, i.Validate()...)
+	}
+	for _, i := range d.Microdata {
+		problems = append(problems, i.Validate()...)
+	}
+	return problems
+}
+
+// StructuredData extracts JSON-LD blocks and microdata of the main frame
+func (s Session) StructuredData() (*StructuredData, error) {
+	var raw struct {
+		JSONLD    []string                 `json:"jsonld"`
+		Microdata []map[string]interface{} `json:"microdata"`
+	}
+	if err := s.Page().evaluateValue(functionStructuredData, false, &raw); err != nil {
+		return nil, err
+	}
+	d := &StructuredData{}
+	for n, block := range raw.JSONLD {
+		items, err := parseJSONLD(block)
+		if err != nil {
+			d.Errors = append(d.Errors, fmt.Sprintf("JSON-LD block #%d: %v", n+1, err))
+			continue
+		}
+		d.JSONLD = append(d.JSONLD, items...)
+	}
+	for _, m := range raw.Microdata {
+		d.Microdata = append(d.Microdata, m)
+	}
+	return d, nil
+}
+
+// parseJSONLD flattens a block which may be an object, an array or an object with @graph
+func parseJSONLD(block string) ([]StructuredItem, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(block), &v); err != nil {
+		return nil, err
+	}
+	var list []StructuredItem
+	add := func(items []interface{}) {
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				list = append(list, m)
+			}
+		}
+	}
+	switch t := v.(type) {
+	case []interface{}:
+		add(t)
+	case map[string]interface{}:
+		if graph, ok := t["@graph"].([]interface{}); ok {
+			add(graph)
+		} else {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,172 @@
+// Package tablecheck reads downloaded CSV and XLSX exports into tables of strings for assertions
+package tablecheck
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnknownFormat = errors.New("unknown table format")
+
+// Table rows of cells, rows may have different lengths
+type Table [][]string
+
+// ReadFile reads CSV (.csv, .tsv, .txt) or the first sheet of XLSX (.xlsx) by the file extension,
+// e.g. the file saved by Session.WaitForDownload
+func ReadFile(path string) (Table, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv", ".txt":
+		return ParseCSV(b)
+	case ".xlsx", ".xlsm":
+		return ParseXLSX(b, "")
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ParseCSV parses CSV, the delimiter (comma, semicolon or tab) is detected by the first line. UTF-8 BOM is skipped
+func ParseCSV(b []byte) (Table, error) {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = detectDelimiter(b)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	return Table(rows), err
+}
+
+func detectDelimiter(b []byte) rune {
+	line := b
+	if n := bytes.IndexByte(b, '\n'); n != -1 {
+		line = b[:n]
+	}
+	best, count := ',', bytes.Count(line, []byte{','})
+	for _, c := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(c))); n > count {
+			best, count = c, n
+		}
+	}
+	return best
+}
+
+// Header the first row
+func (t Table) Header() []string {
+	if len(t) == 0 {
+		return nil
+	}
+	return t[0]
+}
+
+// Rows all rows but the header
+func (t Table) Rows() Table {
+	if len(t) == 0 {
+		return nil
+	}
+	return t[1:]
+}
+
+// Cell value at row and column (from 0), empty if there is no such cell
+func (t Table) Cell(row, col int) string {
+	if row < 0 || row >= len(t) || col < 0 || col >= len(t[row]) {
+		return ""
+	}
+	return t[row][col]
+}
+
+// ColumnIndex index of the header cell equal to name (ignoring case and surrounding spaces), -1 if there is none
+func (t Table) ColumnIndex(name string) int {
+	for n, h := range t.Header() {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+			return n
+		}
+	}
+	return -1
+}
+
+// Column values of the named column without the header
+func (t Table) Column(name string) ([]string, error) {
+	col := t.ColumnIndex(name)
+	if col == -1 {
+		return nil, fmt.Errorf("no column `%s`", name)
+	}
+	values := make([]string, 0, len(t.Rows()))
+	for n := range t.Rows() {
+		values = append(values, t.Cell(n+1, col))
+	}
+	return values, nil
+}
+
+// Records rows as header -> value maps
+func (t Table) Records() []map[string]string {
+	var list []map[string]string
+	for _, row := range t.Rows() {
+		record := make(map[string]string, len(t.Header()))
+		for n, h := range t.Header() {
+			if n < len(row) {
+				record[h] = row[n]
+			} else {
+				record[h] = ""
+			}
+		}
+		list = append(list, record)
+	}
+	return list
+}
+
+// Find position of the first cell equal to value, -1, -1 if there is none
+func (t Table) Find(value string) (row, col int) {
+	for r, cells := range t {
+		for c, cell := range cells {
+			if cell == value {
+				return r, c
+			}
+		}
+	}
+	return -1, -1
+}
+
+// ContainsRow reports whether a row starts with the values
+func (t Table) ContainsRow(values ...string) bool {
+	for _, row := range t {
+		if len(row) < len(values) {
+			continue
+		}
+		match := true
+		for n, v := range values {
+			if row[n] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsRecord reports whether a row has all the values in the named columns
+func (t Table) ContainsRecord(record map[string]string) bool {
+	for _, r := range t.Records() {
+		match := true
+		for k, v := range record {
+			col := t.ColumnIndex(k)
+			if col == -1 || r[t.Header()[col]] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package tablecheck
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want Table
+	}{
+		{"a,b\n1,2\n", Table{{"a", "b"}, {"1", "2"}}},
+		{"\xef\xbb\xbfa;b\n1;\"2;3\"\n", Table{{"a", "b"}, {"1", "2;3"}}},
+		{"a\tb\n1\n", Table{{"a", "b"}, {"1"}}},
+	} {
+		got, err := ParseCSV([]byte(c.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := Table{{"Name", " Price "}, {"apple", "1"}, {"pear"}}
+	if col, err := table.Column("price"); err != nil || !reflect.DeepEqual(col, []string{"1", ""}) {
+		t.Errorf("column %q %v", col, err)
+	}
+	if !table.ContainsRow("apple", "1") || table.ContainsRow("pear", "2") {
+		t.Error("ContainsRow")
+	}
+	if !table.ContainsRecord(map[string]string{"name": "pear", "price": ""}) {
+		t.Error("ContainsRecord")
+	}
+	if r, c := table.Find("1"); r != 1 || c != 1 {
+		t.Errorf("Find %d %d", r, c)
+	}
+}
+
+// xlsx workbook of one sheet with the sheet data
+func xlsx(t *testing.T, sheetData string) []byte {
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Data" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Name</t></si><si><r><t>ap</t></r><r><t>ple</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	} {
+		w, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestParseXLSX(t *testing.T) {
+	b := xlsx(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>Ok</t></is></c></row>`+
+		`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3"><v>1.5</v></c><c r="C3" t="b"><v>1</v></c></row>`)
+	got, err := ParseXLSX(b, "Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Table{{"Name", "", "Ok"}, nil, {"apple", "1.5", "TRUE"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err = ParseXLSX(b, "Other"); err == nil {
+		t.Error("missing sheet is found")
+	}
+}
+
+func TestParseXLSXOutOfRange(t *testing.T) {
+	for _, data := range []string{
+		`<row r="2000000"><c r="A2000000"><v>1</v></c></row>`,
+		`<row r="-1"><c><v>1</v></c></row>`,
+		`<row r="1"><c r="ZZZZ1"><v>1</v></c></row>`,
+		`<row r="1"><c r="1"><v>1</v></c></row>`,
+	} {
+		if _, err := ParseXLSX(xlsx(t, data), ""); err == nil {
+			t.Errorf("%s: no error", data)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package tablecheck
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText text of a shared or inline string, rich text runs are concatenated
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string    `xml:"r,attr"`
+			T      string    `xml:"t,attr"`
+			V      string    `xml:"v"`
+			Inline *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ParseXLSX reads the named sheet of XLSX workbook (empty - the first one).
+// Cells are stored values: numbers as written by the producer, booleans as "TRUE"/"FALSE",
+// dates as serial numbers (see ExcelDate), formulas as their cached results
+func ParseXLSX(b []byte, sheet string) (Table, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+	var (
+		workbook xlsxWorkbook
+		rels     xlsxRelationships
+		shared   struct {
+			Items []xlsxText `xml:"si"`
+		}
+		data xlsxSheet
+	)
+	if err = readXML(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if err = readXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = readXML(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	rid := ""
+	for _, s := range workbook.Sheets {
+		if sheet == "" || s.Name == sheet {
+			rid = s.RID
+			break
+		}
+	}
+	if rid == "" {
+		return nil, fmt.Errorf("no sheet `%s`", sheet)
+	}
+	target := ""
+	for _, r := range rels.Relationships {
+		if r.ID == rid {
+			target = r.Target
+		}
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+	if err = readXML(files, target, &data); err != nil {
+		return nil, err
+	}
+	var table Table
+	for _, row := range data.Rows {
+		if row.R < 0 || row.R > maxRows {
+			return nil, fmt.Errorf("row number %d is out of range", row.R)
+		}
+		r := row.R - 1
+		if row.R == 0 { // the position is optional
+			r = len(table)
+		}
+		for len(table) <= r {
+			table = append(table, nil)
+		}
+		for _, c := range row.Cells {
+			col := len(table[r])
+			if c.R != "" {
+				col = columnIndex(c.R)
+			}
+			if col < 0 || col >= maxColumns {
+				return nil, fmt.Errorf("invalid cell reference %q", c.R)
+			}
+			value := c.V
+			switch c.T {
+			case "s":
+				if n, err := strconv.Atoi(c.V); err == nil && n >= 0 && n < len(shared.Items) {
+					value = shared.Items[n].String()
+				}
+			case "inlineStr":
+				if c.Inline != nil {
+					value = c.Inline.String()
+				}
+			case "b":
+				value = "FALSE"
+				if c.V == "1" {
+					value = "TRUE"
+				}
+			}
+			for len(table[r]) <= col {
+				table[r] = append(table[r], "")
+			}
+			table[r][col] = value
+		}
+	}
+	return table, nil
+}
+
+func readXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("no %s in the workbook", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(rc, 512<<20))
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(b, v)
+}
+
+// limits of a worksheet, larger positions are corrupted data
+const (
+	maxRows    = 1 << 20
+	maxColumns = 1 << 14
+)
+
+// columnIndex index (from 0) of the column of the cell reference, e.g. "B7" -> 1.
+// -1 if the reference has no column letters, maxColumns or more if the column is beyond the limit
+func columnIndex(ref string) int {
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' || n > maxColumns {
+			break
+		}
+		n = n*26 + int(c-'A') + 1
+	}
+	return n - 1
+}
+
+// ExcelDate converts a serial date number of XLSX (1900 date system) to time in UTC
+func ExcelDate(serial string) (time.Time, error) {
+	v, err := strconv.ParseFloat(serial, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// day 0 is 1899-12-30 since 1900 is wrongly treated as a leap year
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return epoch.Add(time.Duration(v * float64(24*time.Hour))).Round(time.Millisecond), nil
+}
@@ -0,0 +1,77 @@
+package control
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/target"
+)
+
+var (
+	ErrNoTargetsToKeep = errors.New("no tabs to keep are given")
+	ErrEmptyPattern    = errors.New("URL pattern is empty")
+)
+
+// ClosePageTargetsExcept closes all tabs except the given ones, at least one tab to keep is required
+func (b BrowserContext) ClosePageTargetsExcept(keep ...target.TargetID) error {
+	if len(keep) == 0 {
+		return ErrNoTargetsToKeep
+	}
+	return b.closePageTargets(func(t *target.TargetInfo) bool {
+		for _, id := range keep {
+			if id == t.TargetId {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ClosePageTargetsMatching closes all tabs with URL matching the pattern ('*' -> zero or more, '?' -> exactly one).
+// Unlike elsewhere an empty pattern is an error, pass "*" to close all tabs
+func (b BrowserContext) ClosePageTargetsMatching(pattern string) error {
+	if pattern == "" {
+		return ErrEmptyPattern
+	}
+	return b.closePageTargets(func(t *target.TargetInfo) bool {
+		return wildcard.Match(pattern, t.Url)
+	})
+}
+
+func (b BrowserContext) closePageTargets(condition func(*target.TargetInfo) bool) error {
+	pages, err := b.GetPageTargets()
+	if err != nil {
+		return err
+	}
+	for _, t := range pages {
+		if condition(t) {
+			if err = b.CloseTarget(t.TargetId); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WaitForPageTargetCount waits until the browser has exactly n tabs
+func (b BrowserContext) WaitForPageTargetCount(n int, timeout time.Duration) error {
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		pages, err := b.GetPageTargets()
+		if err != nil {
+			return err
+		}
+		if len(pages) == n {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return FutureTimeoutError{timeout: timeout}
+		}
+	}
+}
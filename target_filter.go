@@ -0,0 +1,88 @@
+package control
+
+import (
+	"encoding/json"
+
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/target"
+	"github.com/ecwid/control/transport"
+)
+
+// TargetFilter describes noise targets (devtools, extensions, blank popups) to be ignored
+type TargetFilter struct {
+	Types       []string // target types, e.g. "service_worker", "background_page", "other"
+	URLs        []string // URL patterns, e.g. "devtools://*", "chrome-extension://*"
+	BlankPopups bool     // popups (targets with an opener) which are still on about:blank
+	AutoClose   bool     // close matching page targets as soon as they appear, BlankPopups are never closed automatically
+}
+
+// Match reports whether the target is noise
+func (f TargetFilter) Match(t *target.TargetInfo) bool {
+	return f.matchTypeOrURL(t) || (f.BlankPopups && t.OpenerId != "" && (t.Url == "" || t.Url == Blank))
+}
+
+func (f TargetFilter) matchTypeOrURL(t *target.TargetInfo) bool {
+	for _, v := range f.Types {
+		if v == t.Type {
+			return true
+		}
+	}
+	for _, v := range f.URLs {
+		if wildcard.Match(v, t.Url) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTargetFilter sets filter for GetTargets and AutoClose policy, nil resets the filter
+func (b BrowserContext) SetTargetFilter(filter *TargetFilter) error {
+	// the reading goroutine takes state.mx while holding the observers lock (autoCloseTargets),
+	// so state.mx is never held while (un)registering observers or waiting for a response
+	b.state.filterMx.Lock()
+	defer b.state.filterMx.Unlock()
+	b.state.mx.Lock()
+	b.state.filter = filter
+	autoClose := b.state.autoClose
+	b.state.autoClose = nil
+	b.state.mx.Unlock()
+	if autoClose != nil {
+		autoClose()
+	}
+	if filter == nil || !filter.AutoClose {
+		return nil
+	}
+	if err := b.SetDiscoverTargets(true); err != nil {
+		return err
+	}
+	autoClose = b.Client.Register(transport.NewSimpleObserver("", b.autoCloseTargets))
+	b.state.mx.Lock()
+	b.state.autoClose = autoClose
+	b.state.mx.Unlock()
+	return nil
+}
+
+func (b BrowserContext) targetFilter() *TargetFilter {
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	return b.state.filter
+}
+
+// autoCloseTargets never returns an error, otherwise the transport would stop reading
+func (b BrowserContext) autoCloseTargets(e transport.Event) error {
+	switch e.Method {
+	case "Target.targetCreated", "Target.targetInfoChanged":
+	default:
+		return nil
+	}
+	var v = target.TargetInfoChanged{} // the same payload as Target.targetCreated
+	if err := json.Unmarshal(e.Params, &v); err != nil || v.TargetInfo == nil {
+		return nil
+	}
+	filter := b.targetFilter()
+	if filter != nil && v.TargetInfo.Type == "page" && filter.matchTypeOrURL(v.TargetInfo) {
+		// can't wait for the response in the transport's reading goroutine
+		go func() { _ = b.CloseTarget(v.TargetInfo.TargetId) }()
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package totp generates time-based one-time passwords (RFC 6238) of authenticator apps,
+// so accounts protected by 2FA can log in from tests
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSecret = errors.New("invalid TOTP secret")
+
+// Options of the generator, the zero value is the common authenticator setup: SHA1, 6 digits, 30 seconds
+type Options struct {
+	Digits    int           // zero - 6
+	Period    time.Duration // zero - 30s
+	Algorithm string        // "SHA1", "SHA256" or "SHA512", empty - SHA1
+}
+
+// Generate code of the base32 secret (as shown next to the QR code, spaces and case are ignored) at time t
+func Generate(secret string, t time.Time) (string, error) {
+	return GenerateWithOptions(secret, t, Options{})
+}
+
+// GenerateWithOptions is Generate with non-default options
+func GenerateWithOptions(secret string, t time.Time, options Options) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	options = options.withDefaults()
+	var h func() hash.Hash
+	switch strings.ToUpper(options.Algorithm) {
+	case "SHA1":
+		h = sha1.New
+	case "SHA256":
+		h = sha256.New
+	case "SHA512":
+		h = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported TOTP algorithm `%s`", options.Algorithm)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(options.Period/time.Second)))
+	mac := hmac.New(h, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for n := 0; n < options.Digits; n++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", options.Digits, code%mod), nil
+}
+
+// Remaining time until the code generated at t expires
+func Remaining(t time.Time, options Options) time.Duration {
+	period := options.withDefaults().Period
+	return period - time.Duration(t.UnixNano()%int64(period))
+}
+
+func (o Options) withDefaults() Options {
+	if o.Digits == 0 {
+		o.Digits = 6
+	}
+	if o.Period < time.Second {
+		o.Period = 30 * time.Second
+	}
+	if o.Algorithm == "" {
+		o.Algorithm = "SHA1"
+	}
+	return o
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(secret))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// ParseURI reads the secret and options of otpauth://totp/... URI encoded in the QR code
+func ParseURI(uri string) (secret string, options Options, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", options, err
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return "", options, fmt.Errorf("not a TOTP URI `%s`", u.Redacted())
+	}
+	q := u.Query()
+	secret = q.Get("secret")
+	if _, err = decodeSecret(secret); err != nil {
+		return "", options, err
+	}
+	options.Algorithm = q.Get("algorithm")
+	if d := q.Get("digits"); d != "" {
+		if options.Digits, err = strconv.Atoi(d); err != nil {
+			return "", options, err
+		}
+	}
+	if p := q.Get("period"); p != "" {
+		seconds, err := strconv.Atoi(p)
+		if err != nil {
+			return "", options, err
+		}
+		options.Period = time.Duration(seconds) * time.Second
+	}
+	return secret, options, nil
+}
@@ -0,0 +1,77 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// test vectors of RFC 6238 Appendix B
+func TestGenerateRFC6238(t *testing.T) {
+	seeds := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	for _, c := range []struct {
+		unix      int64
+		algorithm string
+		want      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1234567890, "SHA1", "89005924"},
+		{2000000000, "SHA256", "90698825"},
+		{20000000000, "SHA512", "47863826"},
+	} {
+		secret := base32.StdEncoding.EncodeToString([]byte(seeds[c.algorithm]))
+		got, err := GenerateWithOptions(secret, time.Unix(c.unix, 0), Options{Digits: 8, Algorithm: c.algorithm})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("%d %s: got %s, want %s", c.unix, c.algorithm, got, c.want)
+		}
+	}
+}
+
+func TestGenerateSecretFormat(t *testing.T) {
+	// spaces, lower case and missing padding are accepted as authenticator apps show them
+	a, err := Generate("GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ", time.Unix(59, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Generate("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", time.Unix(59, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != "287082" || a != b {
+		t.Errorf("got %s and %s, want 287082", a, b)
+	}
+	if _, err = Generate("not base32!", time.Now()); err != ErrInvalidSecret {
+		t.Errorf("got %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	secret, options, err := ParseURI("otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA256&digits=8&period=60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "JBSWY3DPEHPK3PXP" || options.Algorithm != "SHA256" || options.Digits != 8 || options.Period != time.Minute {
+		t.Errorf("got %s %+v", secret, options)
+	}
+	if _, _, err = ParseURI("otpauth://hotp/Example?secret=JBSWY3DPEHPK3PXP"); err == nil {
+		t.Error("HOTP URI is accepted")
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	if got := Remaining(time.Unix(61, 0), Options{}); got != 29*time.Second {
+		t.Errorf("got %v, want 29s", got)
+	}
+}
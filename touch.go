@@ -0,0 +1,42 @@
+package control
+
+import (
+	"time"
+)
+
+// Touchscreen single-finger touch gestures for mobile emulation (see EmulateDevice).
+// Coordinates are CSS pixels relative to the viewport
+type Touchscreen struct {
+	input Input
+}
+
+func (s Session) Touchscreen() Touchscreen {
+	return Touchscreen{input: s.Input}
+}
+
+// Tap touches (x, y) and lifts the finger
+func (t Touchscreen) Tap(x, y float64) error {
+	if err := t.input.TouchStart(x, y); err != nil {
+		return err
+	}
+	return t.input.TouchEnd()
+}
+
+// Swipe moves the finger from (fromX, fromY) to (toX, toY) by steps touchmove events spread over the duration
+func (t Touchscreen) Swipe(fromX, fromY, toX, toY float64, steps int, duration time.Duration) error {
+	if steps < 1 {
+		steps = 1
+	}
+	if err := t.input.TouchStart(fromX, fromY); err != nil {
+		return err
+	}
+	for n := 1; n <= steps; n++ {
+		time.Sleep(duration / time.Duration(steps))
+		p := float64(n) / float64(steps)
+		if err := t.input.TouchMove(fromX+(toX-fromX)*p, fromY+(toY-fromY)*p); err != nil {
+			_ = t.input.TouchEnd()
+			return err
+		}
+	}
+	return t.input.TouchEnd()
+}
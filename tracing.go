@@ -0,0 +1,84 @@
+package control
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ecwid/control/protocol/tracing"
+	"github.com/ecwid/control/transport"
+)
+
+// DefaultTracingCategories categories recorded by the Performance panel of DevTools, including JavaScript samples
+var DefaultTracingCategories = []string{
+	"-*",
+	"devtools.timeline",
+	"v8.execute",
+	"disabled-by-default-devtools.timeline",
+	"disabled-by-default-devtools.timeline.frame",
+	"disabled-by-default-devtools.timeline.stack",
+	"disabled-by-default-v8.cpu_profiler",
+	"toplevel",
+	"blink.console",
+	"blink.user_timing",
+	"latencyInfo",
+	"loading",
+}
+
+// TracingOptions of StartTracing
+type TracingOptions struct {
+	Categories  []string // included categories, "-*" excludes the rest. Empty - DefaultTracingCategories
+	Screenshots bool     // filmstrip of the page
+}
+
+// StartTracing records a trace of the page until StopTracing. Only one trace per browser can be recorded at once
+func (s Session) StartTracing(options TracingOptions) error {
+	categories := options.Categories
+	if len(categories) == 0 {
+		categories = DefaultTracingCategories
+	}
+	if options.Screenshots {
+		categories = append(append([]string{}, categories...), "disabled-by-default-devtools.screenshot")
+	}
+	config := &tracing.TraceConfig{RecordMode: "recordAsMuchAsPossible"}
+	for _, c := range categories {
+		if len(c) > 1 && c[0] == '-' {
+			if c != "-*" {
+				config.ExcludedCategories = append(config.ExcludedCategories, c[1:])
+			}
+			continue
+		}
+		config.IncludedCategories = append(config.IncludedCategories, c)
+	}
+	return tracing.Start(s, tracing.StartArgs{
+		TransferMode: "ReturnAsStream",
+		StreamFormat: "json",
+		TraceConfig:  config,
+	})
+}
+
+// StopTracing stops the trace and copies it to w in chrome://tracing (Trace Event) JSON format,
+// which is accepted by the Performance panel of DevTools, Perfetto UI and the flame package.
+// The trace is streamed, it's never kept in memory as a whole
+func (s Session) StopTracing(w io.Writer, timeout time.Duration) error {
+	future := s.Observe("Tracing.tracingComplete", func(e transport.Event, resolve func(interface{}), reject func(error)) {
+		var v = tracing.TracingComplete{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			reject(err)
+			return
+		}
+		resolve(v)
+	})
+	defer future.Cancel()
+	if err := tracing.End(s); err != nil {
+		return err
+	}
+	value, err := future.Get(timeout)
+	if err != nil {
+		return err
+	}
+	stream := NewStream(s, value.(tracing.TracingComplete).Stream)
+	defer stream.Close()
+	_, err = io.Copy(w, stream)
+	return err
+}
@@ -0,0 +1,203 @@
+package control
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/transport"
+)
+
+// TrafficCounter bytes of a resource type
+type TrafficCounter struct {
+	Requests int64
+	Sent     int64 // request line, headers and body, approximate since the browser doesn't report exact request size
+	Received int64 // encoded (compressed) bytes including response headers
+}
+
+// Traffic totals of a session
+type Traffic struct {
+	TrafficCounter
+	ByType map[network.ResourceType]TrafficCounter
+}
+
+type trafficMeter struct {
+	mx       sync.Mutex
+	total    Traffic
+	types    map[network.RequestId]network.ResourceType
+	received map[network.RequestId]int64 // dataReceived so far for requests failed in the middle
+	limit    int64
+	exceeded func(Traffic)
+	cancel   func()
+}
+
+// TrackTraffic starts accounting of bytes sent and received by the session, see Traffic
+func (s Session) TrackTraffic() error {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.traffic != nil {
+		return nil
+	}
+	m := &trafficMeter{}
+	m.reset()
+	unsubscribe := s.Subscribe("*", m.update)
+	release, err := s.EnableDomain("Network")
+	if err != nil {
+		unsubscribe()
+		return err
+	}
+	m.cancel = func() {
+		unsubscribe()
+		release()
+	}
+	s.config.traffic = m
+	return nil
+}
+
+// StopTrafficTracking stops accounting, totals are reset
+func (s Session) StopTrafficTracking() {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.traffic != nil {
+		s.config.traffic.cancel()
+		s.config.traffic = nil
+	}
+}
+
+func (s Session) trafficMeter() *trafficMeter {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	return s.config.traffic
+}
+
+// Traffic totals since TrackTraffic or ResetTraffic, zero if tracking is not started
+func (s Session) Traffic() Traffic {
+	m := s.trafficMeter()
+	if m == nil {
+		return Traffic{ByType: map[network.ResourceType]TrafficCounter{}}
+	}
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	return m.snapshot()
+}
+
+// ResetTraffic zeroes totals, e.g. between jobs sharing the session
+func (s Session) ResetTraffic() {
+	if m := s.trafficMeter(); m != nil {
+		m.mx.Lock()
+		m.reset()
+		m.mx.Unlock()
+	}
+}
+
+// SetTrafficLimit calls exceeded once when sent and received bytes go over the limit, e.g. to abort a scraping job.
+// Zero limit removes it
+func (s Session) SetTrafficLimit(limit int64, exceeded func(Traffic)) error {
+	if err := s.TrackTraffic(); err != nil {
+		return err
+	}
+	m := s.trafficMeter()
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.limit, m.exceeded = limit, exceeded
+	return nil
+}
+
+func (m *trafficMeter) reset() {
+	m.total = Traffic{ByType: map[network.ResourceType]TrafficCounter{}}
+	m.types = map[network.RequestId]network.ResourceType{}
+	m.received = map[network.RequestId]int64{}
+}
+
+func (m *trafficMeter) snapshot() Traffic {
+	t := Traffic{TrafficCounter: m.total.TrafficCounter, ByType: make(map[network.ResourceType]TrafficCounter, len(m.total.ByType))}
+	for k, v := range m.total.ByType {
+		t.ByType[k] = v
+	}
+	return t
+}
+
+func (m *trafficMeter) add(typ network.ResourceType, requests, sent, received int64) {
+	c := m.total.ByType[typ]
+	c.Requests += requests
+	c.Sent += sent
+	c.Received += received
+	m.total.ByType[typ] = c
+	m.total.Requests += requests
+	m.total.Sent += sent
+	m.total.Received += received
+	if m.limit > 0 && m.exceeded != nil && m.total.Sent+m.total.Received > m.limit {
+		exceeded, snapshot := m.exceeded, m.snapshot()
+		m.exceeded = nil
+		go exceeded(snapshot)
+	}
+}
+
+func (m *trafficMeter) update(e transport.Event) error {
+	switch e.Method {
+	case "Network.requestWillBeSent":
+		var v = network.RequestWillBeSent{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		sent := int64(len(v.Request.Method) + len(v.Request.Url) + len(" HTTP/1.1\r\n\r\n") + len(v.Request.PostData))
+		for name, value := range headersMap(v.Request.Headers) {
+			if str, ok := value.(string); ok {
+				sent += int64(len(name) + len(str) + 4)
+			}
+		}
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		m.types[v.RequestId] = v.Type
+		m.add(v.Type, 1, sent, 0)
+
+	case "Network.dataReceived":
+		var v = network.DataReceived{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		m.received[v.RequestId] += int64(v.EncodedDataLength)
+
+	case "Network.loadingFinished", "Network.loadingFailed":
+		var v struct {
+			RequestId         network.RequestId `json:"requestId"`
+			EncodedDataLength float64           `json:"encodedDataLength"`
+		}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		received := int64(v.EncodedDataLength)
+		if e.Method == "Network.loadingFailed" {
+			received = m.received[v.RequestId]
+		}
+		typ, ok := m.types[v.RequestId]
+		if !ok {
+			return nil // started before tracking or reset
+		}
+		m.add(typ, 0, 0, received)
+		delete(m.types, v.RequestId)
+		delete(m.received, v.RequestId)
+
+	case "Network.webSocketFrameSent", "Network.webSocketFrameReceived":
+		var v = network.WebSocketFrameSent{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		if v.Response == nil {
+			return nil
+		}
+		size := int64(len(v.Response.PayloadData))
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		if e.Method == "Network.webSocketFrameSent" {
+			m.add("WebSocket", 0, size, 0)
+		} else {
+			m.add("WebSocket", 0, 0, size)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// ObserverError error returned (or panic raised) by an observer's callback
+type ObserverError struct {
+	Name   string // observer's event name
+	Method string // event has been handled
+	Err    error
+	Panic  interface{} // recovered value if callback panicked
+	Stack  []byte
+}
+
+func (e ObserverError) Error() string {
+	if e.Panic != nil {
+		return fmt.Sprintf("observer `%s` panicked on %s: %v\n%s", e.Name, e.Method, e.Panic, e.Stack)
+	}
+	return fmt.Sprintf("observer `%s` failed on %s: %v", e.Name, e.Method, e.Err)
+}
+
+func (e ObserverError) Unwrap() error {
+	return e.Err
+}
+
+// ObserverOverflowError the observer doesn't keep up with incoming events. The observer is closed,
+// so it never misses an event silently: no event is delivered after the dropped one
+type ObserverOverflowError struct {
+	Name   string
+	Method string
+}
+
+func (e ObserverOverflowError) Error() string {
+	return fmt.Sprintf("observer `%s` queue is full, %s dropped and the observer is closed", e.Name, e.Method)
+}
+
+// AsyncObserver calls update in its own goroutine, so a slow or panicking callback doesn't stall other observers.
+// Errors and panics of the callback are reported to onError, as well as overflow of the queue which closes the observer
+type AsyncObserver struct {
+	name    string
+	update  func(Event) error
+	onError func(error)
+	queue   chan Event
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewAsyncObserver starts the observer, it stops on Close or when the context is done
+func NewAsyncObserver(ctx context.Context, name string, size int, update func(Event) error, onError func(error)) *AsyncObserver {
+	o := &AsyncObserver{
+		name:    name,
+		update:  update,
+		onError: onError,
+		queue:   make(chan Event, size),
+		done:    make(chan struct{}),
+	}
+	go o.run(ctx)
+	return o
+}
+
+func (o *AsyncObserver) Name() string {
+	return o.name
+}
+
+func (o *AsyncObserver) Update(val Event) error {
+	select {
+	case <-o.done:
+		return nil
+	case o.queue <- val:
+	default:
+		o.Close()
+		o.onError(ObserverOverflowError{Name: o.name, Method: val.Method})
+	}
+	return nil
+}
+
+// Close stops the observer, the callback is never called after Close returns unless it is running right now
+func (o *AsyncObserver) Close() {
+	o.once.Do(func() { close(o.done) })
+}
+
+func (o *AsyncObserver) run(ctx context.Context) {
+	defer o.Close()
+	for {
+		select {
+		case e := <-o.queue:
+			o.call(e)
+		case <-o.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *AsyncObserver) call(e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			o.onError(ObserverError{Name: o.name, Method: e.Method, Panic: r, Stack: debug.Stack()})
+		}
+	}()
+	select {
+	case <-o.done: // closed while the event was waiting in the queue
+		return
+	default:
+	}
+	if err := o.update(e); err != nil {
+		o.onError(ObserverError{Name: o.name, Method: e.Method, Err: err})
+	}
+}
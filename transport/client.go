@@ -6,32 +6,72 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ecwid/control/redact"
 	"github.com/gorilla/websocket"
 )
 
+var ErrShutdown = errors.New("client is shut down")
+
 type Client struct {
 	*Publisher
-	conn    *websocket.Conn
-	seq     uint64
-	queue   map[uint64]*Request
-	queueMu sync.Mutex
-	sendMu  sync.Mutex
-	context context.Context
-	Timeout time.Duration
-	err     error
-	cancel  func()
+	conn      *websocket.Conn
+	seq       uint64
+	queue     map[uint64]*Request
+	queueMu   sync.Mutex
+	sendMu    sync.Mutex
+	draining  bool
+	context   context.Context
+	Timeout   time.Duration
+	err       error
+	cancel    func()
+	redaction atomic.Value // *redact.Policy
+	limiter   *limiter
+	dump      atomic.Value // *dumper
+	url       string
+	options   DialOptions
+	closing   bool // Browser.close is sent, the connection is expected to drop
+	hooks     *reconnectHooks
+	// reconnected is closed when reconnection ends, nil if the client is not reconnecting
+	reconnected chan struct{}
+	generation  uint64
+}
+
+// DialOptions of DialWithOptions
+type DialOptions struct {
+	// Compression negotiates permessage-deflate, which saves a lot of bandwidth for remote browsers
+	// (protocol messages are JSON, screenshots are base64) at the cost of CPU on both sides.
+	// If the browser (or a proxy in between) declines the extension the connection is not compressed
+	Compression      bool
+	CompressionLevel int         // flate level 1 (fastest) .. 9 (best), zero - the default one
+	Header           http.Header // extra handshake headers, e.g. authorization of a browser farm
+	HandshakeTimeout time.Duration
+	// Reconnect re-dials the URL when the connection drops instead of shutting the client down,
+	// commands in flight fail with ErrReconnected and new ones wait for the connection
+	// (until their context is done or Client.Timeout, then ErrReconnecting is returned). See Client.OnReconnect
+	Reconnect         bool
+	ReconnectAttempts int           // zero - 5
+	ReconnectDelay    time.Duration // before every attempt, zero - 1s
 }
 
 func Dial(ctx context.Context, url string) (*Client, error) {
-	var dialer = websocket.Dialer{
-		ReadBufferSize:   8192,
-		WriteBufferSize:  8192,
-		HandshakeTimeout: 45 * time.Second,
-		Proxy:            http.ProxyFromEnvironment,
+	return DialWithOptions(ctx, url, DialOptions{})
+}
+
+// DialWithOptions connects to the DevTools websocket URL
+func DialWithOptions(ctx context.Context, url string, options DialOptions) (*Client, error) {
+	if options.HandshakeTimeout == 0 {
+		options.HandshakeTimeout = 45 * time.Second
 	}
-	conn, _, err := dialer.Dial(url, nil)
+	if options.ReconnectAttempts == 0 {
+		options.ReconnectAttempts = 5
+	}
+	if options.ReconnectDelay == 0 {
+		options.ReconnectDelay = time.Second
+	}
+	conn, err := dial(ctx, url, options)
 	if err != nil {
 		return nil, err
 	}
@@ -41,46 +81,167 @@ func Dial(ctx context.Context, url string) (*Client, error) {
 		seq:       1,
 		queue:     map[uint64]*Request{},
 		Timeout:   time.Second * 60,
+		limiter:   newLimiter(),
+		url:       url,
+		options:   options,
+		hooks:     newReconnectHooks(),
 	}
 	client.context, client.cancel = context.WithCancel(ctx)
 	go client.reading()
 	return client, nil
 }
 
+func dial(ctx context.Context, url string, options DialOptions) (*websocket.Conn, error) {
+	var dialer = websocket.Dialer{
+		ReadBufferSize:    8192,
+		WriteBufferSize:   8192,
+		HandshakeTimeout:  options.HandshakeTimeout,
+		Proxy:             http.ProxyFromEnvironment,
+		EnableCompression: options.Compression,
+	}
+	conn, _, err := dialer.DialContext(ctx, url, options.Header)
+	if err != nil {
+		return nil, err
+	}
+	if options.Compression && options.CompressionLevel != 0 {
+		if err = conn.SetCompressionLevel(options.CompressionLevel); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
 func (c *Client) Context() context.Context {
 	return c.context
 }
 
 func (c *Client) Close() error {
+	if c.conn == nil {
+		c.finalize(ErrOffline)
+		return nil
+	}
+	c.sendMu.Lock()
+	c.closing = true
+	reconnecting := c.reconnected != nil
+	c.sendMu.Unlock()
+	if reconnecting {
+		// there is no connection to send Browser.close to, dialing gives up when the context is cancelled
+		c.finalize(errors.New("connection is shut down"))
+		return nil
+	}
 	if err := c.Call("", "Browser.close", nil, nil); err != nil {
 		return err
 	}
-	_ = c.conn.Close()
+	_ = c.connection().Close()
 	c.finalize(errors.New("connection is shut down"))
 	return nil
 }
 
+// SetRedaction sets policy applied to protocol data in error messages
+func (c *Client) SetRedaction(policy *redact.Policy) {
+	c.redaction.Store(policy)
+}
+
+func (c *Client) Redaction() *redact.Policy {
+	policy, _ := c.redaction.Load().(*redact.Policy)
+	return policy
+}
+
 func (c *Client) Call(sessionID, method string, args, value interface{}) error {
+	return c.CallContext(context.Background(), sessionID, method, args, value)
+}
+
+// CallContext is Call which also returns ctx.Err() when ctx is done before the response is received.
+// The request is not cancelled in the browser, its response is discarded
+func (c *Client) CallContext(ctx context.Context, sessionID, method string, args, value interface{}) error {
+	request, release, err := c.start(ctx, sessionID, method, args)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return c.await(ctx, request, value)
+}
+
+// Command of Pipeline
+type Command struct {
+	SessionID string
+	Method    string
+	Args      interface{}
+	Value     interface{} // the result is unmarshalled into, optional
+	Err       error       // set by Pipeline
+}
+
+// Pipeline sends commands in order without waiting for responses and then waits for all of them,
+// so N commands cost one round trip instead of N. Err of every command is set, the first error is returned
+func (c *Client) Pipeline(ctx context.Context, commands ...*Command) error {
+	var wg sync.WaitGroup
+	for _, cmd := range commands {
+		request, release, err := c.start(ctx, cmd.SessionID, cmd.Method, cmd.Args)
+		if err != nil {
+			cmd.Err = err
+			continue
+		}
+		wg.Add(1)
+		go func(cmd *Command) {
+			defer wg.Done()
+			defer release() // frees the heavy command slot, so the next heavy command can be sent
+			cmd.Err = c.await(ctx, request, cmd.Value)
+		}(cmd)
+	}
+	wg.Wait()
+	for _, cmd := range commands {
+		if cmd.Err != nil {
+			return cmd.Err
+		}
+	}
+	return nil
+}
+
+// start sends the request, release frees the heavy command slot once the response is received
+func (c *Client) start(ctx context.Context, sessionID, method string, args interface{}) (*Request, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	release, err := c.limiter.acquire(ctx, c.context, sessionID, method)
+	if err != nil {
+		return nil, nil, err
+	}
 	var request = &Request{
 		SessionID: sessionID,
 		Method:    method,
 		Args:      args,
 		response:  make(chan Response, 1),
 	}
-	if err := c.send(request); err != nil {
-		return err
+	if err = c.send(ctx, request); err != nil {
+		release()
+		return nil, nil, err
 	}
-	var ctx, cancel = context.WithTimeout(c.context, c.Timeout)
+	return request, release, nil
+}
+
+func (c *Client) await(ctx context.Context, request *Request, value interface{}) error {
+	var timeout, cancel = context.WithTimeout(c.context, c.Timeout)
 	defer cancel()
 
 	var r Response
 	select {
 	case r = <-request.response:
+		if r.err != nil {
+			return r.err
+		}
 		if r.Error != nil {
 			return r.Error
 		}
 	case <-ctx.Done():
-		return DeadlineExceededError{Request: request, Timeout: c.Timeout}
+		c.forget(request)
+		return ctx.Err()
+	case <-timeout.Done():
+		if c.context.Err() != nil {
+			return c.finalizeErr()
+		}
+		c.forget(request)
+		return DeadlineExceededError{Request: request, Timeout: c.Timeout, redaction: c.Redaction()}
 	}
 	if value != nil {
 		return json.Unmarshal(r.Result, value)
@@ -88,8 +249,10 @@ func (c *Client) Call(sessionID, method string, args, value interface{}) error {
 	return nil
 }
 
-func (c *Client) send(request *Request) error {
-	c.sendMu.Lock()
+func (c *Client) send(ctx context.Context, request *Request) error {
+	if err := c.waitConnection(ctx); err != nil {
+		return err
+	}
 	defer c.sendMu.Unlock()
 
 	select {
@@ -97,6 +260,12 @@ func (c *Client) send(request *Request) error {
 		return c.err
 	default:
 	}
+	if c.draining {
+		return ErrShutdown
+	}
+	if c.conn == nil {
+		return ErrOffline
+	}
 
 	c.queueMu.Lock()
 	seq := c.seq
@@ -105,6 +274,7 @@ func (c *Client) send(request *Request) error {
 	c.queue[seq] = request
 	c.queueMu.Unlock()
 
+	c.dumpRequest(request) // before the write, so the response can't be dumped first
 	if err := c.conn.WriteJSON(request); err != nil {
 		c.queueMu.Lock()
 		delete(c.queue, seq)
@@ -114,23 +284,95 @@ func (c *Client) send(request *Request) error {
 	return nil
 }
 
+// forget removes the request nobody waits for anymore, its response is dropped if it ever arrives
+func (c *Client) forget(request *Request) {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	if c.queue[request.ID] == request {
+		delete(c.queue, request.ID)
+	}
+}
+
 func (c *Client) finalize(err error) {
 	c.sendMu.Lock()
 	c.queueMu.Lock()
 	defer c.queueMu.Unlock()
 	defer c.sendMu.Unlock()
-	c.err = err
+	if c.err == nil {
+		c.err = err
+	}
 	c.cancel()
-	for _, request := range c.queue {
-		_ = request.received(Response{Error: &Error{Message: err.Error()}})
+	for id, request := range c.queue {
+		_ = request.received(Response{err: c.err})
+		delete(c.queue, id)
 	}
 }
 
-func (c *Client) read() error {
+func (c *Client) finalizeErr() error {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	if c.err == nil {
+		return c.context.Err()
+	}
+	return c.err
+}
+
+// Pending returns number of commands waiting for the response
+func (c *Client) Pending() int {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+	return len(c.queue)
+}
+
+// Shutdown stops accepting new commands and waits for in-flight ones,
+// if the context is done first they are cancelled with ErrShutdown.
+// Then all observers are unregistered and the connection is closed, the browser keeps running
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.sendMu.Lock()
+	c.draining = true
+	c.sendMu.Unlock()
+
+	var err error
+	ticker := time.NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+wait:
+	for c.Pending() > 0 {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break wait
+		case <-ticker.C:
+		}
+	}
+	c.finalize(ErrShutdown)
+	c.Clear()
+	if conn := c.connection(); conn != nil {
+		_ = conn.Close()
+	}
+	return err
+}
+
+// connection the current connection, it's replaced on reconnection
+func (c *Client) connection() *websocket.Conn {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.conn
+}
+
+func (c *Client) read(conn *websocket.Conn) error {
 	response := Response{}
-	if err := c.conn.ReadJSON(&response); err != nil {
+	if err := conn.ReadJSON(&response); err != nil {
 		return err
 	}
+	c.dumpEntry(DumpEntry{
+		Direction: DumpReceive,
+		ID:        response.ID,
+		SessionID: response.SessionID,
+		Method:    response.Method,
+		Params:    response.Params,
+		Result:    response.Result,
+		Error:     response.Error,
+	})
 	if response.ID == 0 { // event, not message's response
 		var e = Event{Method: response.Method, Params: response.Params}
 		if response.SessionID != "" {
@@ -143,14 +385,23 @@ func (c *Client) read() error {
 	delete(c.queue, response.ID)
 	c.queueMu.Unlock()
 	if request == nil {
-		return errors.New("no request for response")
+		return nil // the caller has given up (see forget)
 	}
 	return request.received(response)
 }
 
 func (c *Client) reading() {
-	var err error
-	for ; err == nil; err = c.read() {
+	for {
+		var (
+			err  error
+			conn = c.connection()
+		)
+		for ; err == nil; err = c.read(conn) {
+		}
+		if !c.options.Reconnect || !c.redial() {
+			c.finalize(err)
+			return
+		}
+		go c.hooks.call()
 	}
-	c.finalize(err)
 }
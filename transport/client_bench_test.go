@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// countingListener counts bytes the server writes, i.e. responses on the wire
+type countingListener struct {
+	net.Listener
+	written *int64
+}
+
+func (l countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{Conn: c, written: l.written}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	written *int64
+}
+
+func (c countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(c.written, int64(n))
+	return n, err
+}
+
+// benchmarkServer answers every command with result, as the browser does with JSON and base64 payloads
+func benchmarkServer(b *testing.B, result interface{}) (string, *int64) {
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var request struct {
+				ID uint64 `json:"id"`
+			}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(map[string]interface{}{"id": request.ID, "result": result}); err != nil {
+				return
+			}
+		}
+	}))
+	written := new(int64)
+	server.Listener = countingListener{Listener: server.Listener, written: written}
+	server.Start()
+	b.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http"), written
+}
+
+func benchmarkCall(b *testing.B, options DialOptions, result interface{}) {
+	url, written := benchmarkServer(b, result)
+	client, err := DialWithOptions(context.Background(), url, options)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = client.Shutdown(context.Background()) }()
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := atomic.LoadInt64(written)
+	for i := 0; i < b.N; i++ {
+		if err = client.Call("", "Page.captureScreenshot", nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadInt64(written)-start)/float64(b.N), "wire-B/op")
+}
+
+// domResult DOM.getDocument-like JSON: repetitive and well compressible
+func domResult() interface{} {
+	nodes := make([]map[string]interface{}, 500)
+	for n := range nodes {
+		nodes[n] = map[string]interface{}{
+			"nodeId": n, "backendNodeId": n + 1000, "nodeType": 1, "nodeName": "DIV", "localName": "div",
+			"nodeValue": "", "attributes": []string{"class", "row item", "data-id", "item-" + string(rune('a'+n%26))},
+		}
+	}
+	return map[string]interface{}{"root": map[string]interface{}{"children": nodes}}
+}
+
+// screenshotResult base64 of incompressible image data
+func screenshotResult() interface{} {
+	data := make([]byte, 200<<10)
+	rand.New(rand.NewSource(1)).Read(data)
+	return map[string]string{"data": base64.StdEncoding.EncodeToString(data)}
+}
+
+func BenchmarkCallJSON(b *testing.B) {
+	b.Run("compression=off", func(b *testing.B) { benchmarkCall(b, DialOptions{}, domResult()) })
+	b.Run("compression=on", func(b *testing.B) { benchmarkCall(b, DialOptions{Compression: true}, domResult()) })
+}
+
+func BenchmarkCallScreenshot(b *testing.B) {
+	b.Run("compression=off", func(b *testing.B) { benchmarkCall(b, DialOptions{}, screenshotResult()) })
+	b.Run("compression=on", func(b *testing.B) { benchmarkCall(b, DialOptions{Compression: true}, screenshotResult()) })
+}
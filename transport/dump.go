@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrOffline the client has no connection, see NewOfflineClient
+var ErrOffline = errors.New("client has no connection to a browser")
+
+const (
+	DumpSend    = "send"
+	DumpReceive = "recv"
+)
+
+// DumpEntry protocol message written by SetDump, one JSON object per line
+type DumpEntry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"dir"` // DumpSend or DumpReceive
+	ID        uint64          `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+// IsEvent true if the entry is an event sent by the browser
+func (e DumpEntry) IsEvent() bool {
+	return e.Direction == DumpReceive && e.ID == 0 && e.Method != ""
+}
+
+type dumper struct {
+	mx  sync.Mutex
+	enc *json.Encoder
+}
+
+// SetDump writes every command, response and event to w as JSON lines, nil w stops dumping.
+// Params and results are masked by the redaction policy, see SetRedaction
+func (c *Client) SetDump(w io.Writer) {
+	var d *dumper
+	if w != nil {
+		d = &dumper{enc: json.NewEncoder(w)}
+	}
+	c.dump.Store(d)
+}
+
+func (c *Client) dumpEntry(e DumpEntry) {
+	d, _ := c.dump.Load().(*dumper)
+	if d == nil {
+		return
+	}
+	policy := c.Redaction()
+	if len(e.Params) > 0 {
+		e.Params = policy.JSON(e.Params)
+	}
+	if len(e.Result) > 0 {
+		e.Result = policy.JSON(e.Result)
+	}
+	e.Time = time.Now()
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	_ = d.enc.Encode(e)
+}
+
+func (c *Client) dumpRequest(r *Request) {
+	d, _ := c.dump.Load().(*dumper)
+	if d == nil {
+		return
+	}
+	var params json.RawMessage
+	if r.Args != nil {
+		params, _ = json.Marshal(r.Args)
+	}
+	c.dumpEntry(DumpEntry{Direction: DumpSend, ID: r.ID, SessionID: r.SessionID, Method: r.Method, Params: params})
+}
+
+// ReadDump reads entries written by SetDump
+func ReadDump(r io.Reader) ([]DumpEntry, error) {
+	var (
+		list    []DumpEntry
+		scanner = bufio.NewScanner(r)
+	)
+	scanner.Buffer(make([]byte, 64*1024), 256<<20) // screenshots and DOM snapshots make long lines
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e DumpEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, scanner.Err()
+}
+
+// NewOfflineClient client without a connection: every command fails with ErrOffline,
+// events are delivered to observers with Notify and Broadcast only, e.g. to replay a dump
+func NewOfflineClient(ctx context.Context) *Client {
+	client := &Client{
+		Publisher: NewPublisher(),
+		seq:       1,
+		queue:     map[uint64]*Request{},
+		Timeout:   time.Second * 60,
+		limiter:   newLimiter(),
+		err:       ErrOffline,
+		hooks:     newReconnectHooks(),
+	}
+	client.context, client.cancel = context.WithCancel(ctx)
+	return client
+}
@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HeavyMethods commands which are expensive for the renderer, SetHeavyLimit applies to them unless other methods are given
+var HeavyMethods = []string{
+	"Page.captureScreenshot",
+	"Page.captureSnapshot",
+	"Page.printToPDF",
+	"Tracing.start",
+	"Tracing.end",
+	"HeapProfiler.takeHeapSnapshot",
+}
+
+// LimiterStats of heavy commands since SetHeavyLimit
+type LimiterStats struct {
+	Limit     int
+	Running   int
+	Waiting   int
+	Calls     uint64        // commands started
+	Waited    uint64        // commands which had to wait for a slot
+	TotalWait time.Duration // sum of waits, TotalWait/Calls is the average wait
+	MaxWait   time.Duration
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// limiter semaphore of heavy commands, slots are handed out round-robin between sessions
+// so a session queueing many commands doesn't starve the others
+type limiter struct {
+	mx      sync.Mutex
+	methods map[string]bool
+	queues  map[string][]*waiter // session -> waiters in order of arrival
+	ring    []string             // sessions having waiters, in order of service
+	stats   LimiterStats
+}
+
+// SetHeavyLimit limits how many heavy commands run at once across all sessions of the client,
+// excess commands wait for a slot. Zero limit removes the limit. Methods default to HeavyMethods
+func (c *Client) SetHeavyLimit(limit int, methods ...string) {
+	if len(methods) == 0 {
+		methods = HeavyMethods
+	}
+	l := c.limiter
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.methods = make(map[string]bool, len(methods))
+	for _, m := range methods {
+		l.methods[m] = true
+	}
+	l.stats = LimiterStats{Limit: limit, Running: l.stats.Running, Waiting: l.stats.Waiting}
+	l.dispatch()
+}
+
+// HeavyStats returns usage of heavy command slots
+func (c *Client) HeavyStats() LimiterStats {
+	l := c.limiter
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.stats
+}
+
+func newLimiter() *limiter {
+	return &limiter{queues: map[string][]*waiter{}}
+}
+
+// acquire waits for a slot if the method is heavy, release must be called when the command is done
+func (l *limiter) acquire(ctx, client context.Context, sessionID, method string) (release func(), err error) {
+	l.mx.Lock()
+	if l.stats.Limit <= 0 || !l.methods[method] {
+		l.mx.Unlock()
+		return func() {}, nil
+	}
+	l.stats.Calls++
+	if l.stats.Running < l.stats.Limit && len(l.ring) == 0 {
+		l.stats.Running++
+		l.mx.Unlock()
+		return l.release, nil
+	}
+	w := &waiter{ready: make(chan struct{})}
+	if len(l.queues[sessionID]) == 0 {
+		l.ring = append(l.ring, sessionID)
+	}
+	l.queues[sessionID] = append(l.queues[sessionID], w)
+	l.stats.Waiting++
+	l.stats.Waited++
+	l.mx.Unlock()
+
+	start := time.Now()
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-client.Done():
+		err = ErrShutdown
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	wait := time.Since(start)
+	l.stats.TotalWait += wait
+	if wait > l.stats.MaxWait {
+		l.stats.MaxWait = wait
+	}
+	if err != nil {
+		if w.granted {
+			// the slot came along with the cancellation, pass it on
+			l.stats.Running--
+			l.dispatch()
+		} else {
+			l.remove(sessionID, w)
+		}
+		return nil, err
+	}
+	return l.release, nil
+}
+
+func (l *limiter) release() {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.stats.Running--
+	l.dispatch()
+}
+
+// dispatch hands free slots out to the sessions in turn, l.mx must be held
+func (l *limiter) dispatch() {
+	for len(l.ring) > 0 && (l.stats.Limit <= 0 || l.stats.Running < l.stats.Limit) {
+		session := l.ring[0]
+		queue := l.queues[session]
+		w := queue[0]
+		if len(queue) == 1 {
+			delete(l.queues, session)
+			l.ring = l.ring[1:]
+		} else {
+			l.queues[session] = queue[1:]
+			l.ring = append(l.ring[1:], session) // the session goes to the end of the line
+		}
+		w.granted = true
+		l.stats.Running++
+		l.stats.Waiting--
+		close(w.ready)
+	}
+}
+
+func (l *limiter) remove(session string, w *waiter) {
+	queue := l.queues[session]
+	for n, q := range queue {
+		if q == w {
+			queue = append(queue[:n:n], queue[n+1:]...)
+			break
+		}
+	}
+	l.stats.Waiting--
+	if len(queue) > 0 {
+		l.queues[session] = queue
+		return
+	}
+	delete(l.queues, session)
+	for n, s := range l.ring {
+		if s == session {
+			l.ring = append(l.ring[:n:n], l.ring[n+1:]...)
+			break
+		}
+	}
+}
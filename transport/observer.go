@@ -64,6 +64,20 @@ func (o *Publisher) Register(val Observer) func() {
 	}
 }
 
+// Len returns number of registered observers
+func (o *Publisher) Len() int {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	return len(o.observers)
+}
+
+// Clear unregisters all observers
+func (o *Publisher) Clear() {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	o.observers = map[uint64]Observer{}
+}
+
 func NewSimpleObserver(name string, update func(value Event) error) SimpleObserver {
 	return SimpleObserver{
 		name:   name,
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrReconnected the connection dropped while the command was in flight and the client has reconnected,
+// it's unknown whether the command was executed
+var ErrReconnected = errors.New("connection is lost, client has reconnected")
+
+// ErrReconnecting the connection is not restored within Client.Timeout
+var ErrReconnecting = errors.New("connection is lost, client is reconnecting")
+
+// ErrStaleGeneration the command is not sent, the client has reconnected since the generation the command is bound to (see WithGeneration)
+var ErrStaleGeneration = errors.New("command is bound to a lost connection")
+
+type generationKey struct{}
+
+// WithGeneration binds commands sent with ctx to the connection of generation gen (see Client.Generation),
+// e.g. commands of a session attached on the lost connection fail with ErrStaleGeneration instead of being sent
+func WithGeneration(ctx context.Context, gen uint64) context.Context {
+	return context.WithValue(ctx, generationKey{}, gen)
+}
+
+type reconnectHooks struct {
+	mx    sync.Mutex
+	guid  uint64
+	hooks map[uint64]func()
+}
+
+func newReconnectHooks() *reconnectHooks {
+	return &reconnectHooks{hooks: map[uint64]func(){}}
+}
+
+// OnReconnect calls fn after every reconnection (see DialOptions.Reconnect).
+// Sessions of the lost connection are gone in the browser, fn is the place to attach to targets again
+func (c *Client) OnReconnect(fn func()) (cancel func()) {
+	h := c.hooks
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	h.guid++
+	uid := h.guid
+	h.hooks[uid] = fn
+	return func() {
+		h.mx.Lock()
+		defer h.mx.Unlock()
+		delete(h.hooks, uid)
+	}
+}
+
+func (h *reconnectHooks) call() {
+	h.mx.Lock()
+	list := make([]func(), 0, len(h.hooks))
+	for _, fn := range h.hooks {
+		list = append(list, fn)
+	}
+	h.mx.Unlock()
+	for _, fn := range list {
+		fn()
+	}
+}
+
+// Generation number of reconnections so far, session IDs of an older generation are gone in the browser
+func (c *Client) Generation() uint64 {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.generation
+}
+
+// redial replaces the dropped connection, false - the client is closing or all attempts failed.
+// New commands wait for the connection (see send), the lock is not held while dialing, so Close doesn't wait for it
+func (c *Client) redial() bool {
+	c.sendMu.Lock()
+	if c.draining || c.closing || c.context.Err() != nil {
+		c.sendMu.Unlock()
+		return false
+	}
+	reconnected := make(chan struct{})
+	c.reconnected = reconnected
+	c.queueMu.Lock()
+	for id, request := range c.queue {
+		_ = request.received(Response{err: ErrReconnected})
+		delete(c.queue, id)
+	}
+	c.queueMu.Unlock()
+	_ = c.conn.Close()
+	c.sendMu.Unlock()
+
+	conn := c.dialAgain()
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.reconnected = nil
+	close(reconnected)
+	if conn == nil {
+		return false
+	}
+	if c.draining || c.closing || c.context.Err() != nil {
+		_ = conn.Close()
+		return false
+	}
+	c.conn = conn
+	c.generation++
+	return true
+}
+
+func (c *Client) dialAgain() *websocket.Conn {
+	for attempt := 0; attempt < c.options.ReconnectAttempts; attempt++ {
+		select {
+		case <-c.context.Done():
+			return nil
+		case <-time.After(c.options.ReconnectDelay):
+		}
+		if conn, err := dial(c.context, c.url, c.options); err == nil {
+			return conn
+		}
+	}
+	return nil
+}
+
+// waitConnection waits until the client has reconnected if it's reconnecting and checks the generation ctx is bound to,
+// sendMu is held when it returns nil
+func (c *Client) waitConnection(ctx context.Context) error {
+	var timer *time.Timer
+	for {
+		c.sendMu.Lock()
+		reconnected := c.reconnected
+		if reconnected == nil {
+			if timer != nil {
+				timer.Stop()
+			}
+			if gen, ok := ctx.Value(generationKey{}).(uint64); ok && gen != c.generation {
+				c.sendMu.Unlock()
+				return ErrStaleGeneration
+			}
+			return nil
+		}
+		c.sendMu.Unlock()
+		if timer == nil {
+			timer = time.NewTimer(c.Timeout)
+			defer timer.Stop()
+		}
+		select {
+		case <-reconnected:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.context.Done():
+			return c.finalizeErr()
+		case <-timer.C:
+			return ErrReconnecting
+		}
+	}
+}
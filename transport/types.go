@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/ecwid/control/redact"
 )
 
 type Error struct {
@@ -29,6 +31,7 @@ type Response struct {
 	Params    json.RawMessage `json:"params,omitempty"`
 	Result    json.RawMessage `json:"result,omitempty"`
 	Error     *Error          `json:"error,omitempty"`
+	err       error           // the request is cancelled by the client
 }
 
 type Request struct {
@@ -49,11 +52,18 @@ func (request *Request) received(r Response) error {
 }
 
 type DeadlineExceededError struct {
-	Request *Request
-	Timeout time.Duration
+	Request   *Request
+	Timeout   time.Duration
+	redaction *redact.Policy
 }
 
 func (r DeadlineExceededError) Error() string {
+	var args interface{} = r.Request.Args
+	if r.redaction != nil {
+		if b, err := json.Marshal(args); err == nil {
+			args = string(r.redaction.JSON(b))
+		}
+	}
 	return fmt.Sprintf("the reply to the request [sessionID: %s, Method: %s, Args: %v] not received in %s",
-		r.Request.SessionID, r.Request.Method, r.Request.Args, r.Timeout)
+		r.Request.SessionID, r.Request.Method, args, r.Timeout)
 }
@@ -0,0 +1,106 @@
+package control
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ecwid/control/protocol/common"
+	"github.com/ecwid/control/protocol/dom"
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+// UploadFile sets files of input[type=file] as if they were chosen by the user, input and change events are fired.
+// Paths are made absolute and must exist, more than one file requires the multiple attribute
+func (e Element) UploadFile(paths ...string) error {
+	if e.node.LocalName != "input" || !strings.EqualFold(nodeAttribute(e.node, "type"), "file") {
+		return ErrNotFileInput
+	}
+	if _, multiple := nodeAttributeOK(e.node, "multiple"); len(paths) > 1 && !multiple {
+		return ErrSingleFileInput
+	}
+	files, err := absFiles(paths)
+	if err != nil {
+		return err
+	}
+	return e.Upload(files...)
+}
+
+func absFiles(paths []string) ([]string, error) {
+	files := make([]string, len(paths))
+	for n, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, &os.PathError{Op: "upload", Path: abs, Err: os.ErrInvalid}
+		}
+		files[n] = abs
+	}
+	return files, nil
+}
+
+func nodeAttribute(node *dom.Node, name string) string {
+	value, _ := nodeAttributeOK(node, name)
+	return value
+}
+
+// nodeAttributeOK attribute of the node as described by DOM.describeNode (a flat list of names and values)
+func nodeAttributeOK(node *dom.Node, name string) (string, bool) {
+	for n := 0; n+1 < len(node.Attributes); n += 2 {
+		if strings.EqualFold(node.Attributes[n], name) {
+			return node.Attributes[n+1], true
+		}
+	}
+	return "", false
+}
+
+// FileChooser file chooser dialog opened by the page, e.g. by a click on a custom button which clicks a hidden input
+type FileChooser struct {
+	FrameID  common.FrameId
+	Multiple bool
+}
+
+// FileChooserHandler returns files to choose, no files cancel the dialog
+type FileChooserHandler func(FileChooser) []string
+
+// SetFileChooserHandler intercepts file chooser dialogs of the page, so uploads work whatever opens the dialog.
+// nil handler restores native dialogs
+func (s Session) SetFileChooserHandler(handler FileChooserHandler) error {
+	s.config.mx.Lock()
+	defer s.config.mx.Unlock()
+	if s.config.fileChooserCancel != nil {
+		s.config.fileChooserCancel()
+		s.config.fileChooserCancel = nil
+	}
+	if handler == nil {
+		return page.SetInterceptFileChooserDialog(s, page.SetInterceptFileChooserDialogArgs{Enabled: false})
+	}
+	cancel := s.Subscribe("Page.fileChooserOpened", func(e transport.Event) error {
+		var v = page.FileChooserOpened{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			return err
+		}
+		files, err := absFiles(handler(FileChooser{FrameID: v.FrameId, Multiple: v.Mode == "selectMultiple"}))
+		if err != nil {
+			return err
+		}
+		if files == nil {
+			files = []string{}
+		}
+		return dom.SetFileInputFiles(s, dom.SetFileInputFilesArgs{Files: files, BackendNodeId: v.BackendNodeId})
+	})
+	if err := page.SetInterceptFileChooserDialog(s, page.SetInterceptFileChooserDialogArgs{Enabled: true}); err != nil {
+		cancel()
+		return err
+	}
+	s.config.fileChooserCancel = cancel
+	return nil
+}
@@ -0,0 +1,50 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ecwid/control/protocol/target"
+)
+
+// LeakError describes resources still held by the client
+type LeakError struct {
+	Sessions      []string // IDs of sessions which are not closed or detached
+	Subscriptions int      // subscriptions which are not cancelled
+	PendingCalls  int      // commands waiting for the response
+}
+
+func (e LeakError) Error() string {
+	return fmt.Sprintf("leaked %d session(s) [%s], %d subscription(s), %d pending call(s)",
+		len(e.Sessions), strings.Join(e.Sessions, ", "), e.Subscriptions, e.PendingCalls)
+}
+
+// Verify checks that there are no open sessions, dangling subscriptions and pending commands.
+// It is designed to be called at the end of a test suite, e.g. from TestMain along with goleak
+func (b BrowserContext) Verify() error {
+	var leak = LeakError{PendingCalls: b.Client.Pending()}
+	b.state.mx.Lock()
+	for id, s := range b.state.sessions {
+		leak.Sessions = append(leak.Sessions, string(id))
+		leak.Subscriptions += s.publisher.Len()
+	}
+	internal := len(b.state.sessions)
+	if b.state.autoClose != nil {
+		internal++
+	}
+	b.state.mx.Unlock()
+	// the rest of client's observers are user's subscriptions
+	if n := b.Client.Len() - internal; n > 0 {
+		leak.Subscriptions += n
+	}
+	if len(leak.Sessions) == 0 && leak.Subscriptions == 0 && leak.PendingCalls == 0 {
+		return nil
+	}
+	return leak
+}
+
+func (b BrowserContext) forgetSession(id target.SessionID) {
+	b.state.mx.Lock()
+	defer b.state.mx.Unlock()
+	delete(b.state.sessions, id)
+}
@@ -0,0 +1,40 @@
+package control
+
+import "time"
+
+const functionWebVitals = `new Promise(r=>{const v={ttfb:0,fcp:0,lcp:0,cls:0},n=performance.getEntriesByType("navigation")[0],p=performance.getEntriesByName("first-contentful-paint")[0],os=[];
+n&&(v.ttfb=n.responseStart);p&&(v.fcp=p.startTime);
+const w=(t,f)=>{try{const o=new PerformanceObserver(l=>l.getEntries().forEach(f));o.observe({type:t,buffered:!0});os.push(o)}catch(e){}};
+w("largest-contentful-paint",e=>v.lcp=Math.max(v.lcp,e.startTime));w("layout-shift",e=>{e.hadRecentInput||(v.cls+=e.value)});
+setTimeout(()=>{os.forEach(o=>o.disconnect());r(v)},100)})`
+
+// WebVitals loading metrics of the current document, zero if the browser hasn't reported it (yet)
+type WebVitals struct {
+	TTFB time.Duration // time to first byte
+	FCP  time.Duration // first contentful paint
+	LCP  time.Duration // largest contentful paint so far
+	CLS  float64       // cumulative layout shift without shifts caused by user input
+}
+
+// WebVitals https://web.dev/vitals/
+func (f Frame) WebVitals() (*WebVitals, error) {
+	var v struct {
+		TTFB float64 `json:"ttfb"`
+		FCP  float64 `json:"fcp"`
+		LCP  float64 `json:"lcp"`
+		CLS  float64 `json:"cls"`
+	}
+	if err := f.evaluateValue(functionWebVitals, true, &v); err != nil {
+		return nil, err
+	}
+	return &WebVitals{
+		TTFB: milliseconds(v.TTFB),
+		FCP:  milliseconds(v.FCP),
+		LCP:  milliseconds(v.LCP),
+		CLS:  v.CLS,
+	}, nil
+}
+
+func milliseconds(ms float64) time.Duration {
+	return seconds(ms / 1000)
+}
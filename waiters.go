@@ -0,0 +1,186 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecwid/control/internal/wildcard"
+	"github.com/ecwid/control/protocol/network"
+	"github.com/ecwid/control/protocol/page"
+	"github.com/ecwid/control/transport"
+)
+
+// NavigationWaitOptions of WaitForNavigation
+type NavigationWaitOptions struct {
+	Event        LifecycleEventType // lifecycle event of the new document, empty - LifecycleLoad
+	URL          string             // pattern of the new URL ('*' -> zero or more, '?' -> exactly one), empty - any
+	SameDocument bool               // history.pushState, replaceState and anchor navigations count as well
+}
+
+// Waiters subscribe before the action which triggers the event, so the event can't be missed:
+//
+//	future := session.WaitForResponse("*/api/cart*")
+//	defer future.Cancel()
+//	if err := button.Click(); err != nil { ... }
+//	value, err := future.Get(timeout)
+
+// WaitForNavigation resolves with the new URL (string) of the main frame when it has navigated
+func (s Session) WaitForNavigation(options NavigationWaitOptions) Future {
+	if options.Event == "" {
+		options.Event = LifecycleLoad
+	}
+	var (
+		mainFrame = s.Page().ID()
+		loader    network.LoaderId
+		url       string
+	)
+	return s.Observe("*", func(e transport.Event, resolve func(interface{}), reject func(error)) {
+		switch e.Method {
+		case "Page.frameNavigated":
+			var v = page.FrameNavigated{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if v.Frame.Id == mainFrame && wildcard.Match(options.URL, v.Frame.Url) {
+				loader, url = v.Frame.LoaderId, v.Frame.Url
+			}
+		case "Page.navigatedWithinDocument":
+			var v = page.NavigatedWithinDocument{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if options.SameDocument && v.FrameId == mainFrame && wildcard.Match(options.URL, v.Url) {
+				resolve(v.Url)
+			}
+		case "Page.lifecycleEvent":
+			var v = page.LifecycleEvent{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if loader != "" && v.FrameId == mainFrame && v.LoaderId == loader && v.Name == string(options.Event) {
+				resolve(url)
+			}
+		}
+	})
+}
+
+// WaitForRequest resolves with the first request (*network.Request) whose URL matches the pattern
+func (s Session) WaitForRequest(pattern string) Future {
+	return s.observeNetwork(func(e transport.Event, resolve func(interface{}), reject func(error)) {
+		if e.Method != "Network.requestWillBeSent" {
+			return
+		}
+		var v = network.RequestWillBeSent{}
+		if err := json.Unmarshal(e.Params, &v); err != nil {
+			reject(err)
+			return
+		}
+		if wildcard.Match(pattern, v.Request.Url) {
+			resolve(v.Request)
+		}
+	})
+}
+
+// WaitForResponse resolves with the first response (network.ResponseReceived) whose URL matches the pattern.
+// It is rejected if such a request fails without a response
+func (s Session) WaitForResponse(pattern string) Future {
+	var requests = map[network.RequestId]bool{}
+	return s.observeNetwork(func(e transport.Event, resolve func(interface{}), reject func(error)) {
+		switch e.Method {
+		case "Network.requestWillBeSent":
+			var v = network.RequestWillBeSent{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if wildcard.Match(pattern, v.Request.Url) {
+				requests[v.RequestId] = true
+			}
+		case "Network.responseReceived":
+			var v = network.ResponseReceived{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if requests[v.RequestId] || wildcard.Match(pattern, v.Response.Url) {
+				resolve(v)
+			}
+		case "Network.loadingFailed":
+			var v = network.LoadingFailed{}
+			if err := json.Unmarshal(e.Params, &v); err != nil {
+				reject(err)
+				return
+			}
+			if requests[v.RequestId] {
+				reject(fmt.Errorf("request matching %s failed: %s", pattern, v.ErrorText))
+			}
+		}
+	})
+}
+
+// observeNetwork is Observe with Network domain enabled until the future is cancelled
+func (s Session) observeNetwork(condition func(transport.Event, func(interface{}), func(error))) Future {
+	release, err := s.EnableDomain("Network")
+	if err != nil {
+		return rejectedFuture(err)
+	}
+	future := s.Observe("*", condition)
+	unregister := future.promise.unregister
+	future.promise.unregister = func() {
+		unregister()
+		release()
+	}
+	return future
+}
+
+// the predicate is checked on every DOM mutation and animation frame, null means timeout as a value of the predicate is truthy.
+// An exception of the predicate rejects the promise
+const functionWaitFor = `function(predicate,timeout){return new Promise((resolve,reject)=>{
+let done=false,observer=null,timer=null;
+const finish=(f,v)=>{if(done)return;done=true;if(observer)observer.disconnect();clearTimeout(timer);f(v)},
+check=()=>{if(done)return;let v;try{v=predicate()}catch(e){return finish(reject,e)}if(v)return finish(resolve,v);requestAnimationFrame(check)};
+observer=new MutationObserver(check);observer.observe(document,{childList:true,subtree:true,attributes:true,characterData:true});
+timer=setTimeout(()=>finish(resolve,null),timeout);check()})}`
+
+// WaitFor waits until JavaScript expression is truthy in the main frame and returns its value (by value, so return JSON-able values).
+// The expression is checked on every DOM mutation and animation frame instead of polling with a fixed interval.
+// It's compiled into the evaluated function (no eval, so CSP without unsafe-eval doesn't matter), its exception is returned as the error
+func (s Session) WaitFor(predicate string, timeout time.Duration) (interface{}, error) {
+	value, err := s.waitFor(context.Background(), predicate, timeout)
+	if err == nil && value == nil {
+		return nil, FutureTimeoutError{timeout: timeout}
+	}
+	return value, err
+}
+
+// WaitForContext is WaitFor which waits until ctx is done, then ctx.Err() is returned.
+// Without a deadline of ctx the wait is limited by the client's timeout
+func (s Session) WaitForContext(ctx context.Context, predicate string) (interface{}, error) {
+	timeout := s.browser.Client.Timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	value, err := s.waitFor(ctx, predicate, timeout)
+	if err == nil && value == nil {
+		if err = ctx.Err(); err == nil {
+			err = FutureTimeoutError{timeout: timeout}
+		}
+	}
+	return value, err
+}
+
+func (s Session) waitFor(ctx context.Context, predicate string, timeout time.Duration) (interface{}, error) {
+	if timeout <= 0 {
+		timeout = time.Millisecond
+	}
+	value, err := s.Page().EvaluateContext(ctx, fmt.Sprintf("(%s)(()=>(\n%s\n),%d)", functionWaitFor, predicate, timeout.Milliseconds()), true, true)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
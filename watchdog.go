@@ -0,0 +1,123 @@
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ecwid/control/protocol/target"
+)
+
+// activity of the session's commands
+type activity struct {
+	mx    sync.Mutex
+	last  time.Time
+	seq   uint64
+	calls map[uint64]pendingCall
+}
+
+type pendingCall struct {
+	method string
+	start  time.Time
+}
+
+func newActivity() *activity {
+	return &activity{last: time.Now(), calls: map[uint64]pendingCall{}}
+}
+
+func (a *activity) begin(method string) (end func()) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	a.seq++
+	id, now := a.seq, time.Now()
+	a.last = now
+	a.calls[id] = pendingCall{method: method, start: now}
+	return func() {
+		a.mx.Lock()
+		defer a.mx.Unlock()
+		delete(a.calls, id)
+	}
+}
+
+// SessionHealth activity of the session
+type SessionHealth struct {
+	SessionID   string
+	TargetID    target.TargetID
+	Idle        time.Duration // since the last command
+	StuckMethod string        // the oldest command waiting for the response, empty if there is no one
+	StuckFor    time.Duration
+}
+
+func (s Session) health() SessionHealth {
+	s.activity.mx.Lock()
+	defer s.activity.mx.Unlock()
+	h := SessionHealth{SessionID: s.ID(), TargetID: s.tid, Idle: time.Since(s.activity.last)}
+	for _, c := range s.activity.calls {
+		if d := time.Since(c.start); d > h.StuckFor {
+			h.StuckMethod, h.StuckFor = c.method, d
+		}
+	}
+	return h
+}
+
+// Health returns activity of all live sessions
+func (b BrowserContext) Health() []SessionHealth {
+	b.state.mx.Lock()
+	sessions := make([]*Session, 0, len(b.state.sessions))
+	for _, s := range b.state.sessions {
+		sessions = append(sessions, s)
+	}
+	b.state.mx.Unlock()
+	var list = make([]SessionHealth, len(sessions))
+	for n, s := range sessions {
+		list[n] = s.health()
+	}
+	return list
+}
+
+type WatchdogOptions struct {
+	Interval    time.Duration // how often sessions are checked, default 10s
+	IdleLimit   time.Duration // session without commands longer than the limit is unhealthy, 0 - no limit
+	StuckLimit  time.Duration // command waiting for the response longer than the limit makes session unhealthy, 0 - no limit
+	Recycle     bool          // close tabs of unhealthy sessions
+	OnUnhealthy func(SessionHealth)
+}
+
+func (o WatchdogOptions) unhealthy(h SessionHealth) bool {
+	return (o.IdleLimit > 0 && h.Idle > o.IdleLimit) || (o.StuckLimit > 0 && h.StuckMethod != "" && h.StuckFor > o.StuckLimit)
+}
+
+// StartWatchdog periodically flags sessions which are idle or stuck mid-command and optionally recycles them
+func (b BrowserContext) StartWatchdog(options WatchdogOptions) (stop func()) {
+	if options.Interval <= 0 {
+		options.Interval = time.Second * 10
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			case <-b.Client.Context().Done():
+				return
+			}
+			for _, h := range b.Health() {
+				if !options.unhealthy(h) {
+					continue
+				}
+				if options.OnUnhealthy != nil {
+					options.OnUnhealthy(h)
+				}
+				if options.Recycle {
+					if err := b.CloseTarget(h.TargetID); err != nil {
+						b.internalError(err)
+					}
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,69 @@
+package control
+
+import "time"
+
+// scriptWebRTC wraps RTCPeerConnection to keep track of created connections and their ICE candidates
+const scriptWebRTC = `(()=>{if(!window.RTCPeerConnection||window.__control_rtc)return;const l=window.__control_rtc=[],O=window.RTCPeerConnection;let n=0;
+class P extends O{constructor(...a){super(...a);const r={id:"pc"+ ++n,pc:this,local:[],remote:[],prev:null};l.push(r);
+this.addEventListener("icecandidate",e=>{e.candidate&&r.local.push(e.candidate.candidate)})}
+addIceCandidate(c,...a){const r=l.find(r=>r.pc===this);r&&c&&c.candidate&&r.remote.push(c.candidate);return super.addIceCandidate(c,...a)}}
+window.RTCPeerConnection=P;window.webkitRTCPeerConnection&&(window.webkitRTCPeerConnection=P)})()`
+
+const functionWebRTCStats = `Promise.all((window.__control_rtc||[]).map(async r=>{const s={id:r.id,connectionState:r.pc.connectionState,iceConnectionState:r.pc.iceConnectionState,
+localCandidates:r.local,remoteCandidates:r.remote,bytesSent:0,bytesReceived:0,outgoingBitrate:0,incomingBitrate:0,timestamp:0};
+(await r.pc.getStats()).forEach(v=>{if(v.type==="outbound-rtp")s.bytesSent+=v.bytesSent||0;if(v.type==="inbound-rtp")s.bytesReceived+=v.bytesReceived||0;s.timestamp=Math.max(s.timestamp,v.timestamp||0)});
+const p=r.prev;if(p&&s.timestamp>p.timestamp){const d=(s.timestamp-p.timestamp)/1e3;s.outgoingBitrate=8*(s.bytesSent-p.bytesSent)/d;s.incomingBitrate=8*(s.bytesReceived-p.bytesReceived)/d}
+r.prev={timestamp:s.timestamp,bytesSent:s.bytesSent,bytesReceived:s.bytesReceived};return s}))`
+
+// PeerConnectionStats state of the page's RTCPeerConnection
+type PeerConnectionStats struct {
+	ID                 string   `json:"id"`
+	ConnectionState    string   `json:"connectionState"`    // new, connecting, connected, disconnected, failed, closed
+	IceConnectionState string   `json:"iceConnectionState"` // new, checking, connected, completed, disconnected, failed, closed
+	LocalCandidates    []string `json:"localCandidates"`
+	RemoteCandidates   []string `json:"remoteCandidates"`
+	BytesSent          float64  `json:"bytesSent"`
+	BytesReceived      float64  `json:"bytesReceived"`
+	OutgoingBitrate    float64  `json:"outgoingBitrate"` // bits per second since the previous WebRTCStats call
+	IncomingBitrate    float64  `json:"incomingBitrate"`
+	Timestamp          float64  `json:"timestamp"` // ms
+}
+
+// InstrumentWebRTC makes peer connections created by the page observable via WebRTCStats.
+// Instrumentation applies to documents loaded after the call, so call it before Navigate
+func (s Session) InstrumentWebRTC() (cancel func(), err error) {
+	identifier, err := s.AddScriptToEvaluateOnNewDocument(scriptWebRTC)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = s.RemoveScriptToEvaluateOnNewDocument(identifier) }, nil
+}
+
+// WebRTCStats reports state, ICE candidates and bitrate of all peer connections of the page
+func (s Session) WebRTCStats() ([]PeerConnectionStats, error) {
+	var stats []PeerConnectionStats
+	if err := s.Page().evaluateValue(functionWebRTCStats, true, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// WaitPeerConnectionState waits until any peer connection of the page reaches the state, e.g. "connected"
+func (s Session) WaitPeerConnectionState(state string, timeout time.Duration) (*PeerConnectionStats, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		stats, err := s.WebRTCStats()
+		if err != nil {
+			return nil, err
+		}
+		for n := range stats {
+			if stats[n].ConnectionState == state {
+				return &stats[n], nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, FutureTimeoutError{timeout: timeout}
+		}
+		time.Sleep(time.Millisecond * 250)
+	}
+}